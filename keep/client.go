@@ -3,28 +3,83 @@ package keep
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"mime/multipart"
+	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/spf13/cast"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/yaml.v2"
 )
 
-// KeepClient interface defines the methods that need to be implemented
+// KeepClient interface defines the methods of the Keep API used by resources and data sources,
+// so they can be tested with mocks
 type KeepClient interface {
-	GetAvailableProviders() ([]interface{}, *ErrorResponse, error)
-	GetInstalledProviders() ([]interface{}, *ErrorResponse, error)
-	InstallProvider(providerConfig map[string]interface{}) (map[string]interface{}, *ErrorResponse, error)
-	DeleteProvider(providerType, providerID string) (*ErrorResponse, error)
-	InstallProviderWebhook(providerType, providerID string) (*ErrorResponse, error)
+	GetAvailableProviders(ctx context.Context) ([]KeepProvider, *ErrorResponse, error)
+	GetInstalledProviders(ctx context.Context) ([]KeepProvider, *ErrorResponse, error)
+	GetInstalledProvider(ctx context.Context, providerID string) (*KeepProvider, *ErrorResponse, error)
+	InstallProvider(ctx context.Context, providerConfig map[string]interface{}) (*KeepProvider, *ErrorResponse, error)
+	UpdateProvider(ctx context.Context, providerID string, providerConfig map[string]interface{}) (*ErrorResponse, error)
+	DeleteProvider(ctx context.Context, providerType, providerID string) (*ErrorResponse, error)
+	InstallProviderWebhook(ctx context.Context, providerType, providerID string, events []string) (*ErrorResponse, error)
+	UninstallProviderWebhook(ctx context.Context, providerType, providerID string) (*ErrorResponse, error)
+	GetWebhookSettings(ctx context.Context) (*WebhookSettings, *ErrorResponse, error)
+	ValidateProviderScopes(ctx context.Context, providerID string) (map[string]interface{}, *ErrorResponse, error)
+	TestProvider(ctx context.Context, providerConfig map[string]interface{}) (*ErrorResponse, error)
+	ListWorkflows(ctx context.Context) ([]Workflow, *ErrorResponse, error)
+	GetWorkflow(ctx context.Context, id string) (*Workflow, *ErrorResponse, error)
+	CreateWorkflow(ctx context.Context, filePath string) (*WorkflowRevision, *ErrorResponse, error)
+	CreateWorkflowJSON(ctx context.Context, workflow map[string]interface{}) (*WorkflowRevision, *ErrorResponse, error)
+	UpdateWorkflow(ctx context.Context, id string, filePath string) (*WorkflowRevision, *ErrorResponse, error)
+	DeleteWorkflow(ctx context.Context, id string) (*ErrorResponse, error)
+	WriteWorkflowSecrets(ctx context.Context, id string, secrets map[string]string) (*ErrorResponse, error)
+	DeleteWorkflowSecret(ctx context.Context, id, name string) (*ErrorResponse, error)
+	GetMappings(ctx context.Context) ([]Mapping, *ErrorResponse, error)
+	GetMappingsByName(ctx context.Context, name string) ([]Mapping, *ErrorResponse, error)
+	GetMapping(ctx context.Context, id string) (*Mapping, *ErrorResponse, error)
+	CreateMapping(ctx context.Context, mapping Mapping) (*Mapping, *ErrorResponse, error)
+	DeleteMapping(ctx context.Context, id string) (*ErrorResponse, error)
+	GetExtractions(ctx context.Context) ([]Extraction, *ErrorResponse, error)
+	GetExtraction(ctx context.Context, id string) (*Extraction, *ErrorResponse, error)
+	CreateExtraction(ctx context.Context, extraction Extraction) (*Extraction, *ErrorResponse, error)
+	UpdateExtraction(ctx context.Context, id string, extraction Extraction) (*ErrorResponse, error)
+	DeleteExtraction(ctx context.Context, id string) (*ErrorResponse, error)
+	GetIncidents(ctx context.Context) ([]IncidentDto, *ErrorResponse, error)
+	GetIncident(ctx context.Context, id string) (*IncidentDto, *ErrorResponse, error)
+	CreateIncident(ctx context.Context, incident IncidentDtoIn) (*IncidentDto, *ErrorResponse, error)
+	UpdateIncident(ctx context.Context, id string, incident IncidentDtoIn) (*IncidentDto, *ErrorResponse, error)
+	DeleteIncident(ctx context.Context, id string) (*ErrorResponse, error)
+	CreateDeduplicationRule(ctx context.Context, rule DeduplicationRuleRequestDto) (string, *ErrorResponse, error)
+	UpdateDeduplicationRule(ctx context.Context, id string, rule DeduplicationRuleRequestDto) (*ErrorResponse, error)
+	DeleteDeduplicationRule(ctx context.Context, id string) (*ErrorResponse, error)
+	GetTopologyApplications(ctx context.Context) ([]TopologyApplicationDtoOut, *ErrorResponse, error)
+	CreateTopologyApplication(ctx context.Context, application TopologyApplicationDtoIn) (*TopologyApplicationDtoOut, *ErrorResponse, error)
+	UpdateTopologyApplication(ctx context.Context, id string, application TopologyApplicationDtoIn) (*TopologyApplicationDtoOut, *ErrorResponse, error)
+	DeleteTopologyApplication(ctx context.Context, id string) (*ErrorResponse, error)
+	GetAlertFields(ctx context.Context) ([]string, *ErrorResponse, error)
+	WhoAmI(ctx context.Context) (map[string]interface{}, *ErrorResponse, error)
+	CreateAPIKey(ctx context.Context, name, role string) (*APIKey, *ErrorResponse, error)
+	DeleteAPIKey(ctx context.Context, keyID string) (*ErrorResponse, error)
+	BackendVersion(ctx context.Context) string
+	WithTenant(tenantID string) KeepClient
+
+	// supportsEndpoint and rejectEndpoint track the endpoints served by the backend, see backendCapabilities
+	supportsEndpoint(ctx context.Context, method, path string) bool
+	rejectEndpoint(method, path string)
 }
 
 // Client struct with Api Key needed to authenticate against keep
@@ -32,6 +87,55 @@ type Client struct {
 	HostURL    string
 	HTTPClient *http.Client
 	ApiKey     string
+	// UserAgent is sent as User-Agent header if set
+	UserAgent string
+	// TenantID is sent as X-Tenant-Id header if set
+	TenantID string
+	// AuthType selects how the api key is sent, as X-API-Key header or as bearer token
+	AuthType string
+	// Credentials provide the api key or token instead of ApiKey if set, e.g. to rotate it
+	Credentials credentialSource
+	// RateLimiter limits the requests of the client and all its copies if set
+	RateLimiter *rateLimiter
+	// RequestSlots limits the number of concurrent requests of the client and all its copies to its capacity if set
+	RequestSlots chan struct{}
+	// Headers are added to every request, e.g. for identity-aware proxies in front of the backend
+	Headers map[string]string
+	// AuthHook adds headers of templates and auth commands to every attempt of a request if set
+	AuthHook *authHook
+	// ReadTimeout and WriteTimeout bound every attempt of GET and HEAD requests respectively all other requests
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	// MaxRetries is the number of retries of requests failing with a retryable status code
+	MaxRetries   int
+	RetryMinWait time.Duration
+	RetryMaxWait time.Duration
+	// ReadinessTimeout bounds the wait until created providers and mappings are returned by the backend
+	ReadinessTimeout time.Duration
+
+	availableProviders *availableProvidersCache
+	// Compression compresses large request bodies with gzip if set
+	Compression *requestCompression
+	// CircuitBreaker fails requests immediately while the backend is unreachable if set
+	CircuitBreaker *circuitBreaker
+	// Tracer records a span of every API call if set
+	Tracer trace.Tracer
+
+	// listCache reuses list responses for a short time if set
+	listCache    *listCache
+	capabilities *backendCapabilities
+}
+
+const (
+	authTypeAPIKey = "api_key"
+	authTypeBearer = "bearer"
+)
+
+// availableProvidersCache keeps the available providers for the lifetime of the client, so concurrent
+// operations on many providers share a single request
+type availableProvidersCache struct {
+	mu        sync.Mutex
+	providers []KeepProvider
 }
 
 // Ensure Client implements KeepClient interface
@@ -66,86 +170,783 @@ type ErrorResponse struct {
 	Details string `json:"details,omitempty"`
 }
 
-// NewClient func creates new client
+// NewClient func creates new client. The client is safe for concurrent use, its copies share the transport
+// and the caches of the client.
 func NewClient(hostUrl string, apiKey string, timeout time.Duration) *Client {
 	c := Client{
-		HTTPClient: &http.Client{Timeout: timeout},
-		HostURL:    hostUrl,
-		ApiKey:     apiKey,
+		HTTPClient:         &http.Client{Timeout: timeout, Transport: defaultTransport()},
+		HostURL:            hostUrl,
+		ApiKey:             apiKey,
+		MaxRetries:         3,
+		RetryMinWait:       time.Second,
+		RetryMaxWait:       30 * time.Second,
+		ReadinessTimeout:   defaultReadinessTimeout,
+		availableProviders: &availableProvidersCache{},
+		capabilities:       &backendCapabilities{},
 	}
 	return &c
 }
 
-// doReq func does the api requests
-func (c *Client) doReq(req *http.Request) ([]byte, *ErrorResponse, error) {
-	req.Header.Set("X-API-Key", c.ApiKey)
+// WithTenant returns a copy of the client which sends its requests to the given tenant,
+// an empty tenantID keeps the tenant of the client
+func (c *Client) WithTenant(tenantID string) KeepClient {
+	if tenantID == "" {
+		return c
+	}
+	client := *c
+	client.TenantID = tenantID
+	return &client
+}
+
+// endpoint returns the url of an API path, joined to HostURL with exactly one slash
+func (c *Client) endpoint(path string) string {
+	return strings.TrimRight(c.HostURL, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// doReq func does the api requests, retrying failures with backoff if they are retryable
+func (c *Client) doReq(req *http.Request) (respBody []byte, errResp *ErrorResponse, err error) {
+	// failed writes may still have changed objects, so any write invalidates the cached lists
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		defer c.listCache.invalidate()
+	}
+	for key, value := range c.Headers {
+		req.Header.Set(key, value)
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	if c.TenantID != "" {
+		req.Header.Set("X-Tenant-Id", c.TenantID)
+	}
+	// all attempts of a call share the request id, so retries show up together in the logs of the backend
+	if req.Header.Get(requestIDHeader) == "" {
+		req.Header.Set(requestIDHeader, newRequestID())
+	}
 
 	// Only set Content-Type if not already set
 	if req.Header.Get("Content-Type") == "" {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	if c.Tracer != nil {
+		var span trace.Span
+		req, span = c.startSpan(req)
+		defer func() { endSpan(span, err) }()
+	}
+
+	restoreBody, err := c.Compression.compress(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	credentialsRefreshed := false
+	for attempt := 0; ; attempt++ {
+		apiKey := c.ApiKey
+		if c.Credentials != nil {
+			var err error
+			if apiKey, err = c.Credentials.Token(req.Context()); err != nil {
+				return nil, &ErrorResponse{Error: "Failed to get credentials", Details: err.Error()}, fmt.Errorf("failed to get credentials: %v", err)
+			}
+		}
+		if c.AuthType == authTypeBearer {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		} else {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		if c.AuthHook != nil {
+			if err := c.AuthHook.Apply(req); err != nil {
+				return nil, &ErrorResponse{Error: "Failed to get credentials", Details: err.Error()}, fmt.Errorf("failed to get credentials: %v", err)
+			}
+		}
+
+		if err := c.CircuitBreaker.allow(); err != nil {
+			return nil, &ErrorResponse{Error: "Keep backend unreachable", Details: err.Error()}, err
+		}
+
+		if c.RateLimiter != nil {
+			if err := c.RateLimiter.Wait(req.Context()); err != nil {
+				return nil, nil, fmt.Errorf("rate limit wait failed: %v", err)
+			}
+		}
+
+		if c.RequestSlots != nil {
+			select {
+			case c.RequestSlots <- struct{}{}:
+			case <-req.Context().Done():
+				return nil, nil, fmt.Errorf("waiting for a free request slot failed: %v", req.Context().Err())
+			}
+		}
+
+		attemptReq, cancel := c.withRequestTimeout(req)
+		statusCode, body, errResp, err := c.doReqOnce(attemptReq)
+		cancel()
+		recordAttempt(req.Context(), attempt, statusCode)
+		if c.RequestSlots != nil {
+			<-c.RequestSlots
+		}
+		// requests canceled by terraform say nothing about the backend
+		if req.Context().Err() == nil {
+			c.CircuitBreaker.record(statusCode, err)
+		}
+
+		// rejected credentials may have been rotated or revoked before they expired, so they are refreshed once
+		if statusCode == http.StatusUnauthorized && (c.Credentials != nil || c.AuthHook != nil) && !credentialsRefreshed {
+			if c.Credentials != nil {
+				c.Credentials.Invalidate()
+			}
+			if c.AuthHook != nil {
+				c.AuthHook.Invalidate()
+			}
+			credentialsRefreshed = true
+			attempt--
+		} else if restoreBody != nil && statusCode == http.StatusUnsupportedMediaType {
+			// the backend doesn't accept compressed bodies, so the request is sent again uncompressed
+			tflog.Debug(req.Context(), "Keep backend rejected compressed request body, sending requests uncompressed", map[string]interface{}{
+				"http_method": req.Method,
+				"http_url":    req.URL.String(),
+			})
+			c.Compression.disable()
+			if err := restoreBody(); err != nil {
+				return nil, nil, fmt.Errorf("failed to rewind request body: %v", err)
+			}
+			restoreBody = nil
+			attempt--
+			continue
+		} else {
+			if err == nil || attempt >= c.MaxRetries || !isRetryable(req, statusCode, err) {
+				return body, errResp, err
+			}
+
+			wait := c.retryWait(attempt)
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+				wait = min(apiErr.RetryAfter, maxRetryAfter)
+			}
+			tflog.Debug(req.Context(), "Retrying Keep API request", map[string]interface{}{
+				"http_method": req.Method,
+				"http_url":    req.URL.String(),
+				"http_status": statusCode,
+				"attempt":     attempt + 1,
+				"wait_ms":     wait.Milliseconds(),
+			})
+
+			select {
+			case <-req.Context().Done():
+				return body, errResp, err
+			case <-time.After(wait):
+			}
+		}
+
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, nil, fmt.Errorf("failed to rewind request body: %v", err)
+			}
+		}
+	}
+}
+
+// withRequestTimeout bounds a request by ReadTimeout or WriteTimeout depending on its method. A deadline of the
+// request, e.g. the timeout of the resource operation, still applies if it is earlier.
+func (c *Client) withRequestTimeout(req *http.Request) (*http.Request, context.CancelFunc) {
+	timeout := c.WriteTimeout
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		timeout = c.ReadTimeout
+	}
+
+	if timeout <= 0 {
+		return req, func() {}
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	return req.WithContext(ctx), cancel
+}
+
+// isRetryable reports whether an attempt of the request which failed with the status code, 0 if no response
+// was received, can be retried. Rate limited requests were not processed and are always retried. Timeouts,
+// server errors and transport errors only for idempotent methods, because e.g. a provider installation may
+// have succeeded before the gateway timed out. Canceled requests and permanent transport errors are never retried.
+func isRetryable(req *http.Request, statusCode int, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if statusCode == http.StatusTooManyRequests {
+		return true
+	}
 
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	if statusCode == 0 {
+		return !isPermanentTransportError(err)
+	}
+	return statusCode == http.StatusRequestTimeout || statusCode >= 500
+}
+
+// isPermanentTransportError reports whether a request failed for a reason retrying doesn't fix,
+// e.g. an untrusted certificate, a rejected client certificate or an unknown host
+func isPermanentTransportError(err error) bool {
+	var certificateErr *tls.CertificateVerificationError
+	var hostnameErr x509.HostnameError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	switch {
+	case errors.As(err, &certificateErr), errors.As(err, &hostnameErr), errors.As(err, &unknownAuthorityErr):
+		return true
+	case errors.As(err, &dnsErr):
+		return dnsErr.IsNotFound
+	case errors.As(err, &opErr):
+		// TLS alerts of the backend, e.g. rejecting the client certificate
+		return opErr.Op == "remote error"
+	default:
+		return false
+	}
+}
+
+// maxRetryAfter caps the wait requested by Retry-After headers, so a misconfigured backend can't stall an apply
+const maxRetryAfter = 5 * time.Minute
+
+// retryWait returns the exponential backoff before the next attempt, bounded by RetryMinWait and RetryMaxWait.
+// Up to half of the backoff is random, so clients failing at the same time spread their retries.
+func (c *Client) retryWait(attempt int) time.Duration {
+	wait := c.RetryMinWait
+	for i := 0; i < attempt && wait < c.RetryMaxWait; i++ {
+		wait *= 2
+	}
+	if wait > c.RetryMaxWait {
+		wait = c.RetryMaxWait
+	}
+	if jitter := int64(wait / 2); jitter > 0 {
+		wait -= time.Duration(rand.Int63n(jitter + 1))
+	}
+	return wait
+}
+
+// doReqOnce sends the request once and returns the status code of the response, 0 if no response was received
+func (c *Client) doReqOnce(req *http.Request) (int, []byte, *ErrorResponse, error) {
+	start := time.Now()
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, nil, fmt.Errorf("HTTP request failed: %v", err)
+		c.logRequest(req, nil, nil, time.Since(start), err)
+		return 0, nil, nil, fmt.Errorf("HTTP request failed (request id %s): %w", req.Header.Get(requestIDHeader), &transportError{err: err})
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
+	c.logRequest(req, resp, body, time.Since(start), err)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read response body: %v", err)
+		return resp.StatusCode, nil, nil, fmt.Errorf("failed to read response body: %v", err)
 	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		if isScopeError, scopeDetails := isScopesError(body); isScopeError {
-			return nil, &ErrorResponse{
-				Error:   "Insufficient permissions",
-				Details: scopeDetails,
-			}, fmt.Errorf("API request failed: insufficient permissions")
+		apiErr := newAPIError(req, resp, body)
+		errResp := apiErr.Response
+		errResp.Details = strings.TrimSpace(fmt.Sprintf("%s (request id %s)", errResp.Details, apiErr.RequestID))
+		return resp.StatusCode, nil, &errResp, apiErr
+	}
+
+	return resp.StatusCode, body, nil, nil
+}
+
+// Provider-specific API methods
+
+// GetAvailableProviders returns the available provider types. The response is cached, failed requests are not.
+func (c *Client) GetAvailableProviders(ctx context.Context) ([]KeepProvider, *ErrorResponse, error) {
+	if c.availableProviders == nil {
+		return c.getAvailableProviders(ctx)
+	}
+
+	c.availableProviders.mu.Lock()
+	defer c.availableProviders.mu.Unlock()
+
+	if c.availableProviders.providers == nil {
+		providers, errResp, err := c.getAvailableProviders(ctx)
+		if err != nil {
+			return nil, errResp, err
+		}
+		c.availableProviders.providers = providers
+	}
+
+	return append([]KeepProvider{}, c.availableProviders.providers...), nil, nil
+}
+
+func (c *Client) getAvailableProviders(ctx context.Context) ([]KeepProvider, *ErrorResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint("providers"), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	body, errResp, err := c.doReq(req)
+	if err != nil {
+		return nil, errResp, fmt.Errorf("failed to get available providers: %w", err)
+	}
+
+	var response struct {
+		Providers []KeepProvider `json:"providers"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse response: %v. Response body: %s", err, string(body))
+	}
+
+	if response.Providers == nil {
+		return nil, nil, fmt.Errorf("invalid response format: 'providers' field is missing. Response body: %s", string(body))
+	}
+
+	return response.Providers, nil, nil
+}
+
+func (c *Client) GetInstalledProviders(ctx context.Context) ([]KeepProvider, *ErrorResponse, error) {
+	var providers []KeepProvider
+	if errResp, err := c.getAllPages(ctx, "providers/export", &providers); err != nil {
+		return nil, errResp, err
+	}
+
+	return providers, nil, nil
+}
+
+// GetInstalledProvider returns an installed provider, nil if it doesn't exist. Backends listing an endpoint
+// for single providers are asked for the provider, others for all providers. Unlike for other objects, a
+// 404 of a backend without the endpoint can't be told apart from a deleted provider, so it isn't guessed.
+func (c *Client) GetInstalledProvider(ctx context.Context, providerID string) (*KeepProvider, *ErrorResponse, error) {
+	if !c.advertisesEndpoint(ctx, "GET", "/providers/{provider_id}") {
+		providers, errResp, err := c.GetInstalledProviders(ctx)
+		if err != nil {
+			return nil, errResp, err
 		}
+		for i := range providers {
+			if string(providers[i].ID) == providerID {
+				return &providers[i], nil, nil
+			}
+		}
+		return nil, nil, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint(fmt.Sprintf("providers/%s", providerID)), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, errResp, err := c.doReq(req)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil, nil
+		}
+		return nil, errResp, err
+	}
+
+	var provider KeepProvider
+	if err := json.Unmarshal(body, &provider); err != nil {
+		return nil, nil, err
+	}
+
+	return &provider, nil, nil
+}
+
+func (c *Client) InstallProvider(ctx context.Context, providerConfig map[string]interface{}) (*KeepProvider, *ErrorResponse, error) {
+	payload, err := json.Marshal(providerConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal provider config: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint("providers/install"),
+		strings.NewReader(string(payload)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	body, errResp, err := c.doReq(req)
+	if err != nil {
+		return nil, errResp, fmt.Errorf("failed to install provider: %w", err)
+	}
 
-		var errResp ErrorResponse
-		if err := json.Unmarshal(body, &errResp); err == nil && (errResp.Error != "" || errResp.Details != "") {
-			return nil, &errResp, fmt.Errorf("API request failed with status %d", resp.StatusCode)
+	if body == nil {
+		return nil, nil, fmt.Errorf("received empty response body")
+	}
+
+	var provider KeepProvider
+	if err := json.Unmarshal(body, &provider); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse response: %v. Response body: %s", err, string(body))
+	}
+
+	return &provider, nil, nil
+}
+
+func (c *Client) UpdateProvider(ctx context.Context, providerID string, providerConfig map[string]interface{}) (*ErrorResponse, error) {
+	payload, err := json.Marshal(providerConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal provider config: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", c.endpoint(fmt.Sprintf("providers/%s", providerID)),
+		strings.NewReader(string(payload)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	_, errResp, err := c.doReq(req)
+	if err != nil {
+		return errResp, fmt.Errorf("failed to update provider: %w", err)
+	}
+
+	return nil, nil
+}
+
+// InstallProviderWebhook installs the webhook of a provider. If events is empty,
+// all webhook integrations of the provider are installed.
+func (c *Client) InstallProviderWebhook(ctx context.Context, providerType, providerID string, events []string) (*ErrorResponse, error) {
+	var body io.Reader
+	if len(events) > 0 {
+		payload, err := json.Marshal(map[string]interface{}{"events": events})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal webhook events: %v", err)
 		}
-		return nil, &ErrorResponse{
-			Error:   fmt.Sprintf("request failed with status %d", resp.StatusCode),
-			Details: string(body),
-		}, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST",
+		c.endpoint(fmt.Sprintf("providers/install/webhook/%s/%s", providerType, providerID)),
+		body)
+	if err != nil {
+		return nil, err
+	}
+
+	_, errResp, err := c.doReq(req)
+	if err != nil {
+		return errResp, err
+	}
+
+	return nil, nil
+}
+
+// errWebhookUninstallUnsupported is returned for backends which don't list an endpoint for uninstalling webhooks
+var errWebhookUninstallUnsupported = errors.New("the backend does not support uninstalling webhooks")
+
+// UninstallProviderWebhook removes the webhook of a provider from the source system. The OpenAPI document of Keep
+// doesn't list an endpoint for it, so it is only requested from backends listing one.
+func (c *Client) UninstallProviderWebhook(ctx context.Context, providerType, providerID string) (*ErrorResponse, error) {
+	if !c.advertisesEndpoint(ctx, "DELETE", "/providers/install/webhook/{provider_type}/{provider_id}") {
+		return nil, errWebhookUninstallUnsupported
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE",
+		c.endpoint(fmt.Sprintf("providers/install/webhook/%s/%s", providerType, providerID)),
+		nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, errResp, err := c.doReq(req)
+	if err != nil {
+		return errResp, err
+	}
+
+	return nil, nil
+}
+
+func (c *Client) GetWebhookSettings(ctx context.Context) (*WebhookSettings, *ErrorResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint("settings/webhook"), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, errResp, err := c.doReq(req)
+	if err != nil {
+		return nil, errResp, err
+	}
+
+	var settings WebhookSettings
+	if err := json.Unmarshal(body, &settings); err != nil {
+		return nil, nil, err
+	}
+
+	return &settings, nil, nil
+}
+
+func (c *Client) ValidateProviderScopes(ctx context.Context, providerID string) (map[string]interface{}, *ErrorResponse, error) {
+	if errResp, err := c.requireEndpoint(ctx, "POST", "/providers/{provider_id}/scopes", "Validating provider scopes"); err != nil {
+		return nil, errResp, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint(fmt.Sprintf("providers/%s/scopes", providerID)), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, errResp, err := c.doReq(req)
+	if err != nil {
+		return nil, errResp, err
+	}
+
+	var scopes map[string]interface{}
+	if err := json.Unmarshal(body, &scopes); err != nil {
+		return nil, nil, err
+	}
+
+	return scopes, nil, nil
+}
+
+func (c *Client) DeleteProvider(ctx context.Context, providerType, providerID string) (*ErrorResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "DELETE",
+		c.endpoint(fmt.Sprintf("providers/%s/%s", providerType, providerID)),
+		nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, errResp, err := c.doReq(req)
+	if err != nil {
+		return errResp, err
+	}
+
+	return nil, nil
+}
+
+// TestProvider tests the connection of a provider by fetching alerts with the given provider config
+func (c *Client) TestProvider(ctx context.Context, providerConfig map[string]interface{}) (*ErrorResponse, error) {
+	if errResp, err := c.requireEndpoint(ctx, "POST", "/providers/test", "validate_connection"); err != nil {
+		return errResp, err
+	}
+
+	payload, err := json.Marshal(providerConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal provider config: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint("providers/test"),
+		strings.NewReader(string(payload)))
+	if err != nil {
+		return nil, err
+	}
+
+	_, errResp, err := c.doReq(req)
+	if err != nil {
+		return errResp, err
+	}
+
+	return nil, nil
+}
+
+// Workflow API methods
+func (c *Client) ListWorkflows(ctx context.Context) ([]Workflow, *ErrorResponse, error) {
+	var workflows []Workflow
+	if errResp, err := c.getAllPages(ctx, "workflows", &workflows); err != nil {
+		return nil, errResp, err
+	}
+
+	return workflows, nil, nil
+}
+
+// WhoAmI returns the tenant of the credentials the client authenticates with
+func (c *Client) WhoAmI(ctx context.Context) (map[string]interface{}, *ErrorResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint("whoami"), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, errResp, err := c.doReq(req)
+	if err != nil {
+		return nil, errResp, err
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse response: %v. Response body: %s", err, string(body))
+	}
+
+	return response, nil, nil
+}
+
+// CreateAPIKey creates an api key with the role, its name has to be unique within the tenant
+func (c *Client) CreateAPIKey(ctx context.Context, name, role string) (*APIKey, *ErrorResponse, error) {
+	payload, err := json.Marshal(map[string]string{"name": name, "role": role})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint("settings/apikey"), strings.NewReader(string(payload)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, errResp, err := c.doReq(req)
+	if err != nil {
+		return nil, errResp, err
+	}
+
+	var apiKey APIKey
+	if err := json.Unmarshal(body, &apiKey); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	return &apiKey, nil, nil
+}
+
+// DeleteAPIKey deletes the api key with the reference id returned by CreateAPIKey, requests authenticated with it
+// fail afterwards
+func (c *Client) DeleteAPIKey(ctx context.Context, keyID string) (*ErrorResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", c.endpoint(fmt.Sprintf("settings/apikey/%s", url.PathEscape(keyID))), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, errResp, err := c.doReq(req)
+	if err != nil {
+		return errResp, err
+	}
+
+	return nil, nil
+}
+
+func (c *Client) GetWorkflow(ctx context.Context, id string) (*Workflow, *ErrorResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint(fmt.Sprintf("workflows/%s", id)), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, errResp, err := c.doReq(req)
+	if err != nil {
+		return nil, errResp, err
+	}
+
+	var workflow Workflow
+	if err := json.Unmarshal(body, &workflow); err != nil {
+		return nil, nil, err
+	}
+
+	return &workflow, nil, nil
+}
+
+// CreateWorkflow uploads the workflow file, its content is streamed instead of read into memory
+func (c *Client) CreateWorkflow(ctx context.Context, filePath string) (*WorkflowRevision, *ErrorResponse, error) {
+	req, err := c.newFileUploadRequest(ctx, "POST", "workflows", filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	respBody, errResp, err := c.doReq(req)
+	if err != nil {
+		return nil, errResp, err
+	}
+
+	var revision WorkflowRevision
+	if err := json.Unmarshal(respBody, &revision); err != nil {
+		return nil, nil, err
+	}
+
+	return &revision, nil, nil
+}
+
+// UpdateWorkflow uploads a new revision of the workflow file, its content is streamed instead of read into memory
+func (c *Client) UpdateWorkflow(ctx context.Context, id string, filePath string) (*WorkflowRevision, *ErrorResponse, error) {
+	req, err := c.newFileUploadRequest(ctx, "PUT", fmt.Sprintf("workflows/%s", id), filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	respBody, errResp, err := c.doReq(req)
+	if err != nil {
+		return nil, errResp, err
+	}
+
+	var revision WorkflowRevision
+	if err := json.Unmarshal(respBody, &revision); err != nil {
+		return nil, nil, err
+	}
+
+	return &revision, nil, nil
+}
+
+func (c *Client) DeleteWorkflow(ctx context.Context, id string) (*ErrorResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", c.endpoint(fmt.Sprintf("workflows/%s", id)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, errResp, err := c.doReq(req)
+	if err != nil {
+		return errResp, err
+	}
+
+	return nil, nil
+}
+
+// WriteWorkflowSecrets creates or updates the secrets of a workflow, other secrets of the workflow are kept
+func (c *Client) WriteWorkflowSecrets(ctx context.Context, id string, secrets map[string]string) (*ErrorResponse, error) {
+	payload, err := json.Marshal(secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint(fmt.Sprintf("workflows/%s/secrets", id)), strings.NewReader(string(payload)))
+	if err != nil {
+		return nil, err
+	}
+
+	_, errResp, err := c.doReq(req)
+	if err != nil {
+		return errResp, err
 	}
 
-	return body, nil, nil
-
+	return nil, nil
 }
 
-// Provider-specific API methods
-
-func (c *Client) GetAvailableProviders() ([]interface{}, *ErrorResponse, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/providers", c.HostURL), nil)
+func (c *Client) DeleteWorkflowSecret(ctx context.Context, id, name string) (*ErrorResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", c.endpoint(fmt.Sprintf("workflows/%s/secrets/%s", id, url.PathEscape(name))), nil)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create request: %v", err)
+		return nil, err
 	}
 
-	body, errResp, err := c.doReq(req)
+	_, errResp, err := c.doReq(req)
 	if err != nil {
-		return nil, errResp, fmt.Errorf("failed to get available providers: %v", err)
+		return errResp, err
 	}
 
-	var response map[string]interface{}
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, nil, fmt.Errorf("failed to parse response: %v. Response body: %s", err, string(body))
+	return nil, nil
+}
+
+// Mapping API methods
+func (c *Client) GetMappings(ctx context.Context) ([]Mapping, *ErrorResponse, error) {
+	var mappings []Mapping
+	if errResp, err := c.getAllPages(ctx, "mapping", &mappings); err != nil {
+		return nil, errResp, err
 	}
 
-	providers, ok := response["providers"].([]interface{})
-	if !ok {
-		return nil, nil, fmt.Errorf("invalid response format: 'providers' field is missing or has wrong type. Response: %v", response)
+	return mappings, nil, nil
+}
+
+// GetMappingsByName returns the mappings with the name. Backends documenting the name filter only return these
+// mappings instead of all mappings with their rows, otherwise the shared list of all mappings is filtered here.
+func (c *Client) GetMappingsByName(ctx context.Context, name string) ([]Mapping, *ErrorResponse, error) {
+	if !c.advertisesQueryParameter(ctx, "GET", "/mapping", "name") {
+		mappings, errResp, err := c.GetMappings(ctx)
+		if err != nil {
+			return nil, errResp, err
+		}
+		return filterMappingsByName(mappings, name), nil, nil
 	}
 
-	return providers, nil, nil
+	var mappings []Mapping
+	if errResp, err := c.getAllFilteredPages(ctx, "mapping", url.Values{"name": {name}}, &mappings); err != nil {
+		return nil, errResp, err
+	}
+	// the filter may be documented, but ignored by the backend
+	return filterMappingsByName(mappings, name), nil, nil
+}
+
+// filterMappingsByName returns the mappings with the name
+func filterMappingsByName(mappings []Mapping, name string) []Mapping {
+	named := make([]Mapping, 0, len(mappings))
+	for _, mapping := range mappings {
+		if mapping.Name == name {
+			named = append(named, mapping)
+		}
+	}
+	return named
 }
 
-func (c *Client) GetInstalledProviders() ([]interface{}, *ErrorResponse, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/providers/export", c.HostURL), nil)
+func (c *Client) GetMapping(ctx context.Context, id string) (*Mapping, *ErrorResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint(fmt.Sprintf("mapping/%s", id)), nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -155,47 +956,41 @@ func (c *Client) GetInstalledProviders() ([]interface{}, *ErrorResponse, error)
 		return nil, errResp, err
 	}
 
-	var providers []interface{}
-	if err := json.Unmarshal(body, &providers); err != nil {
+	var mapping Mapping
+	if err := json.Unmarshal(body, &mapping); err != nil {
 		return nil, nil, err
 	}
 
-	return providers, nil, nil
+	return &mapping, nil, nil
 }
 
-func (c *Client) InstallProvider(providerConfig map[string]interface{}) (map[string]interface{}, *ErrorResponse, error) {
-	payload, err := json.Marshal(providerConfig)
+func (c *Client) CreateMapping(ctx context.Context, mapping Mapping) (*Mapping, *ErrorResponse, error) {
+	payload, err := json.Marshal(mapping)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to marshal provider config: %v", err)
+		return nil, nil, err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/providers/install", c.HostURL),
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint("mapping"),
 		strings.NewReader(string(payload)))
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create request: %v", err)
+		return nil, nil, err
 	}
 
 	body, errResp, err := c.doReq(req)
 	if err != nil {
-		return nil, errResp, fmt.Errorf("failed to install provider: %v", err)
-	}
-
-	if body == nil {
-		return nil, nil, fmt.Errorf("received empty response body")
+		return nil, errResp, err
 	}
 
-	var response map[string]interface{}
+	var response Mapping
 	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, nil, fmt.Errorf("failed to parse response: %v. Response body: %s", err, string(body))
+		return nil, nil, err
 	}
 
-	return response, nil, nil
+	return &response, nil, nil
 }
 
-func (c *Client) InstallProviderWebhook(providerType, providerID string) (*ErrorResponse, error) {
-	req, err := http.NewRequest("POST",
-		fmt.Sprintf("%s/providers/install/webhook/%s/%s", c.HostURL, providerType, providerID),
-		nil)
+func (c *Client) DeleteMapping(ctx context.Context, id string) (*ErrorResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", c.endpoint(fmt.Sprintf("mapping/%s", id)), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -208,41 +1003,43 @@ func (c *Client) InstallProviderWebhook(providerType, providerID string) (*Error
 	return nil, nil
 }
 
-func (c *Client) DeleteProvider(providerType, providerID string) (*ErrorResponse, error) {
-	req, err := http.NewRequest("DELETE",
-		fmt.Sprintf("%s/providers/%s/%s", c.HostURL, providerType, providerID),
-		nil)
-	if err != nil {
-		return nil, err
-	}
-
-	_, errResp, err := c.doReq(req)
-	if err != nil {
-		return errResp, err
+// Extraction API methods
+func (c *Client) GetExtractions(ctx context.Context) ([]Extraction, *ErrorResponse, error) {
+	var extractions []Extraction
+	if errResp, err := c.getAllPages(ctx, "extraction", &extractions); err != nil {
+		return nil, errResp, err
 	}
 
-	return nil, nil
+	return extractions, nil, nil
 }
 
-func (c *Client) TestProvider(providerType, providerID string) (*ErrorResponse, error) {
-	req, err := http.NewRequest("POST",
-		fmt.Sprintf("%s/providers/%s/%s/test", c.HostURL, providerType, providerID),
-		nil)
+func (c *Client) GetExtraction(ctx context.Context, id string) (*Extraction, *ErrorResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint(fmt.Sprintf("extraction/%s", id)), nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	_, errResp, err := c.doReq(req)
+	body, errResp, err := c.doReq(req)
 	if err != nil {
-		return errResp, err
+		return nil, errResp, err
 	}
 
-	return nil, nil
+	var extraction Extraction
+	if err := json.Unmarshal(body, &extraction); err != nil {
+		return nil, nil, err
+	}
+
+	return &extraction, nil, nil
 }
 
-// Workflow API methods
-func (c *Client) ListWorkflows() ([]interface{}, *ErrorResponse, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/workflows", c.HostURL), nil)
+func (c *Client) CreateExtraction(ctx context.Context, extraction Extraction) (*Extraction, *ErrorResponse, error) {
+	payload, err := json.Marshal(extraction)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint("extraction"),
+		strings.NewReader(string(payload)))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -252,119 +1049,129 @@ func (c *Client) ListWorkflows() ([]interface{}, *ErrorResponse, error) {
 		return nil, errResp, err
 	}
 
-	var workflows []interface{}
-	if err := json.Unmarshal(body, &workflows); err != nil {
+	var response Extraction
+	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, nil, err
 	}
 
-	return workflows, nil, nil
+	return &response, nil, nil
 }
 
-func (c *Client) GetWorkflow(id string) (map[string]interface{}, *ErrorResponse, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/workflows/%s", c.HostURL, id), nil)
+func (c *Client) UpdateExtraction(ctx context.Context, id string, extraction Extraction) (*ErrorResponse, error) {
+	payload, err := json.Marshal(extraction)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	body, errResp, err := c.doReq(req)
+	req, err := http.NewRequestWithContext(ctx, "PUT", c.endpoint(fmt.Sprintf("extraction/%s", id)),
+		strings.NewReader(string(payload)))
 	if err != nil {
-		return nil, errResp, err
+		return nil, err
 	}
 
-	var response map[string]interface{}
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, nil, err
+	_, errResp, err := c.doReq(req)
+	if err != nil {
+		return errResp, err
 	}
 
-	return response, nil, nil
+	return nil, nil
 }
 
-func (c *Client) CreateWorkflow(filePath string) (map[string]interface{}, *ErrorResponse, error) {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	file, err := writer.CreateFormFile("file", filePath)
+func (c *Client) DeleteExtraction(ctx context.Context, id string) (*ErrorResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", c.endpoint(fmt.Sprintf("extraction/%s", id)), nil)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	content, err := os.ReadFile(filePath)
+	_, errResp, err := c.doReq(req)
 	if err != nil {
-		return nil, nil, err
+		return errResp, err
 	}
 
-	if _, err := file.Write(content); err != nil {
-		return nil, nil, err
-	}
+	return nil, nil
+}
 
-	if err := writer.Close(); err != nil {
-		return nil, nil, err
+// Incident API methods, using the models generated from the OpenAPI document
+func (c *Client) GetIncidents(ctx context.Context) ([]IncidentDto, *ErrorResponse, error) {
+	var incidents []IncidentDto
+	if errResp, err := c.getAllPages(ctx, "incidents", &incidents); err != nil {
+		return nil, errResp, err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/workflows", c.HostURL), body)
+	return incidents, nil, nil
+}
+
+func (c *Client) GetIncident(ctx context.Context, id string) (*IncidentDto, *ErrorResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint(fmt.Sprintf("incidents/%s", id)), nil)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-
-	respBody, errResp, err := c.doReq(req)
+	body, errResp, err := c.doReq(req)
 	if err != nil {
 		return nil, errResp, err
 	}
 
-	var response map[string]interface{}
-	if err := json.Unmarshal(respBody, &response); err != nil {
+	var incident IncidentDto
+	if err := json.Unmarshal(body, &incident); err != nil {
 		return nil, nil, err
 	}
 
-	return response, nil, nil
+	return &incident, nil, nil
 }
 
-func (c *Client) UpdateWorkflow(id string, filePath string) (map[string]interface{}, *ErrorResponse, error) {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	file, err := writer.CreateFormFile("file", filePath)
+func (c *Client) CreateIncident(ctx context.Context, incident IncidentDtoIn) (*IncidentDto, *ErrorResponse, error) {
+	payload, err := json.Marshal(incident)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	content, err := os.ReadFile(filePath)
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint("incidents"),
+		strings.NewReader(string(payload)))
 	if err != nil {
 		return nil, nil, err
 	}
 
-	if _, err := file.Write(content); err != nil {
-		return nil, nil, err
+	body, errResp, err := c.doReq(req)
+	if err != nil {
+		return nil, errResp, err
 	}
 
-	if err := writer.Close(); err != nil {
+	var response IncidentDto
+	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, nil, err
 	}
 
-	req, err := http.NewRequest("PUT", fmt.Sprintf("%s/workflows/%s", c.HostURL, id), body)
+	return &response, nil, nil
+}
+
+func (c *Client) UpdateIncident(ctx context.Context, id string, incident IncidentDtoIn) (*IncidentDto, *ErrorResponse, error) {
+	payload, err := json.Marshal(incident)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req, err := http.NewRequestWithContext(ctx, "PUT", c.endpoint(fmt.Sprintf("incidents/%s", id)),
+		strings.NewReader(string(payload)))
+	if err != nil {
+		return nil, nil, err
+	}
 
-	respBody, errResp, err := c.doReq(req)
+	body, errResp, err := c.doReq(req)
 	if err != nil {
 		return nil, errResp, err
 	}
 
-	var response map[string]interface{}
-	if err := json.Unmarshal(respBody, &response); err != nil {
+	var response IncidentDto
+	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, nil, err
 	}
 
-	return response, nil, nil
+	return &response, nil, nil
 }
 
-func (c *Client) DeleteWorkflow(id string) (*ErrorResponse, error) {
-	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/workflows/%s", c.HostURL, id), nil)
+func (c *Client) DeleteIncident(ctx context.Context, id string) (*ErrorResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", c.endpoint(fmt.Sprintf("incidents/%s", id)), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -377,53 +1184,59 @@ func (c *Client) DeleteWorkflow(id string) (*ErrorResponse, error) {
 	return nil, nil
 }
 
-// Mapping API methods
-func (c *Client) GetMappings() ([]interface{}, *ErrorResponse, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/mapping", c.HostURL), nil)
+// Deduplication rule API methods, using the models generated from the OpenAPI document
+
+// CreateDeduplicationRule creates a deduplication rule and returns its id, the OpenAPI document doesn't
+// describe the response, which contains the created rule
+func (c *Client) CreateDeduplicationRule(ctx context.Context, rule DeduplicationRuleRequestDto) (string, *ErrorResponse, error) {
+	payload, err := json.Marshal(rule)
 	if err != nil {
-		return nil, nil, err
+		return "", nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint("deduplications"),
+		strings.NewReader(string(payload)))
+	if err != nil {
+		return "", nil, err
 	}
 
 	body, errResp, err := c.doReq(req)
 	if err != nil {
-		return nil, errResp, err
+		return "", errResp, err
 	}
 
-	var mappings []interface{}
-	if err := json.Unmarshal(body, &mappings); err != nil {
-		return nil, nil, err
+	var response struct {
+		ID apiID `json:"id"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", nil, err
 	}
 
-	return mappings, nil, nil
+	return string(response.ID), nil, nil
 }
 
-func (c *Client) CreateMapping(mapping map[string]interface{}) (map[string]interface{}, *ErrorResponse, error) {
-	payload, err := json.Marshal(mapping)
+func (c *Client) UpdateDeduplicationRule(ctx context.Context, id string, rule DeduplicationRuleRequestDto) (*ErrorResponse, error) {
+	payload, err := json.Marshal(rule)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/mapping", c.HostURL),
+	req, err := http.NewRequestWithContext(ctx, "PUT", c.endpoint(fmt.Sprintf("deduplications/%s", id)),
 		strings.NewReader(string(payload)))
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	body, errResp, err := c.doReq(req)
+	_, errResp, err := c.doReq(req)
 	if err != nil {
-		return nil, errResp, err
-	}
-
-	var response map[string]interface{}
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, nil, err
+		return errResp, err
 	}
 
-	return response, nil, nil
+	return nil, nil
 }
 
-func (c *Client) DeleteMapping(id string) (*ErrorResponse, error) {
-	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/mapping/%s", c.HostURL, id), nil)
+func (c *Client) DeleteDeduplicationRule(ctx context.Context, id string) (*ErrorResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", c.endpoint(fmt.Sprintf("deduplications/%s", id)), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -436,9 +1249,9 @@ func (c *Client) DeleteMapping(id string) (*ErrorResponse, error) {
 	return nil, nil
 }
 
-// Extraction API methods
-func (c *Client) GetExtractions() ([]interface{}, *ErrorResponse, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/extraction", c.HostURL), nil)
+// Topology API methods, using the models generated from the OpenAPI document
+func (c *Client) GetTopologyApplications(ctx context.Context) ([]TopologyApplicationDtoOut, *ErrorResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint("topology/applications"), nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -448,21 +1261,21 @@ func (c *Client) GetExtractions() ([]interface{}, *ErrorResponse, error) {
 		return nil, errResp, err
 	}
 
-	var extractions []interface{}
-	if err := json.Unmarshal(body, &extractions); err != nil {
+	var applications []TopologyApplicationDtoOut
+	if err := json.Unmarshal(body, &applications); err != nil {
 		return nil, nil, err
 	}
 
-	return extractions, nil, nil
+	return applications, nil, nil
 }
 
-func (c *Client) CreateExtraction(extraction map[string]interface{}) (map[string]interface{}, *ErrorResponse, error) {
-	payload, err := json.Marshal(extraction)
+func (c *Client) CreateTopologyApplication(ctx context.Context, application TopologyApplicationDtoIn) (*TopologyApplicationDtoOut, *ErrorResponse, error) {
+	payload, err := json.Marshal(application)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/extraction", c.HostURL),
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint("topology/applications"),
 		strings.NewReader(string(payload)))
 	if err != nil {
 		return nil, nil, err
@@ -473,36 +1286,41 @@ func (c *Client) CreateExtraction(extraction map[string]interface{}) (map[string
 		return nil, errResp, err
 	}
 
-	var response map[string]interface{}
+	var response TopologyApplicationDtoOut
 	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, nil, err
 	}
 
-	return response, nil, nil
+	return &response, nil, nil
 }
 
-func (c *Client) UpdateExtraction(id string, extraction map[string]interface{}) (*ErrorResponse, error) {
-	payload, err := json.Marshal(extraction)
+func (c *Client) UpdateTopologyApplication(ctx context.Context, id string, application TopologyApplicationDtoIn) (*TopologyApplicationDtoOut, *ErrorResponse, error) {
+	payload, err := json.Marshal(application)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	req, err := http.NewRequest("PUT", fmt.Sprintf("%s/extraction/%s", c.HostURL, id),
+	req, err := http.NewRequestWithContext(ctx, "PUT", c.endpoint(fmt.Sprintf("topology/applications/%s", id)),
 		strings.NewReader(string(payload)))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	_, errResp, err := c.doReq(req)
+	body, errResp, err := c.doReq(req)
 	if err != nil {
-		return errResp, err
+		return nil, errResp, err
 	}
 
-	return nil, nil
+	var response TopologyApplicationDtoOut
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, nil, err
+	}
+
+	return &response, nil, nil
 }
 
-func (c *Client) DeleteExtraction(id string) (*ErrorResponse, error) {
-	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/extraction/%s", c.HostURL, id), nil)
+func (c *Client) DeleteTopologyApplication(ctx context.Context, id string) (*ErrorResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", c.endpoint(fmt.Sprintf("topology/applications/%s", id)), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -515,6 +1333,33 @@ func (c *Client) DeleteExtraction(id string) (*ErrorResponse, error) {
 	return nil, nil
 }
 
+// Alert API methods
+func (c *Client) GetAlertFields(ctx context.Context) ([]string, *ErrorResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint("alerts/facets/fields"), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, errResp, err := c.doReq(req)
+	if err != nil {
+		return nil, errResp, err
+	}
+
+	var fields []interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, nil, err
+	}
+
+	result := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if name, ok := field.(string); ok {
+			result = append(result, name)
+		}
+	}
+
+	return result, nil, nil
+}
+
 // Helper function to convert YAML to JSON-compatible map
 func yamlToJSONMap(content []byte) (map[string]interface{}, error) {
 	var yamlData map[interface{}]interface{}
@@ -569,13 +1414,17 @@ func convertToStringSlice(s []interface{}) []interface{} {
 	return result
 }
 
-func (c *Client) CreateWorkflowJSON(workflow map[string]interface{}) (map[string]interface{}, *ErrorResponse, error) {
+func (c *Client) CreateWorkflowJSON(ctx context.Context, workflow map[string]interface{}) (*WorkflowRevision, *ErrorResponse, error) {
+	if errResp, err := c.requireEndpoint(ctx, "POST", "/workflows/json", "Creating workflows"); err != nil {
+		return nil, errResp, err
+	}
+
 	payload, err := json.Marshal(workflow)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/workflows/json", c.HostURL), strings.NewReader(string(payload)))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint("workflows/json"), strings.NewReader(string(payload)))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -587,24 +1436,237 @@ func (c *Client) CreateWorkflowJSON(workflow map[string]interface{}) (map[string
 		return nil, errResp, err
 	}
 
-	var response map[string]interface{}
-	if err := json.Unmarshal(respBody, &response); err != nil {
+	var revision WorkflowRevision
+	if err := json.Unmarshal(respBody, &revision); err != nil {
 		return nil, nil, err
 	}
 
-	return response, nil, nil
+	return &revision, nil, nil
 }
 
-func ClientConfigurer(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-	host, err := url.Parse(d.Get("backend_url").(string))
+func ClientConfigurer(ctx context.Context, d *schema.ResourceData, userAgent string) (interface{}, diag.Diagnostics) {
+	profile, err := loadConfigProfile(d.Get("config_file").(string), d.Get("profile").(string))
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	// settings of the provider block and the environment take precedence over the config file
+	backendURL := d.Get("backend_url").(string)
+	if backendURL == "" {
+		backendURL = profile.APIURL
+	}
+	apiKey := d.Get("api_key").(string)
+	if apiKey == "" {
+		apiKey = profile.APIKey
+	}
+	tenantID := d.Get("tenant_id").(string)
+	if tenantID == "" {
+		tenantID = profile.TenantID
+	}
+
+	if backendURL == "" {
+		return nil, diag.Errorf("backend_url is required, set it in the provider block, the KEEP_BACKEND_URL environment variable or the config file")
+	}
+	var oauth2Config map[string]interface{}
+	if blocks := d.Get("oauth2").([]interface{}); len(blocks) > 0 && blocks[0] != nil {
+		oauth2Config = blocks[0].(map[string]interface{})
+	}
+	var authExecConfig map[string]interface{}
+	if blocks := d.Get("auth_exec").([]interface{}); len(blocks) > 0 && blocks[0] != nil {
+		authExecConfig = blocks[0].(map[string]interface{})
+	}
+	apiKeyFile := d.Get("api_key_file").(string)
+	// the token of an auth command authenticates with the backend if no other credentials are set
+	authExecCredentials := apiKey == "" && apiKeyFile == "" && oauth2Config == nil && authExecConfig != nil && !authExecConfig["per_request"].(bool)
+	if apiKey == "" && apiKeyFile == "" && oauth2Config == nil && !authExecCredentials {
+		return nil, diag.Errorf("api_key, api_key_file, oauth2 or auth_exec is required, set it in the provider block, the KEEP_API_KEY or KEEP_API_KEY_FILE environment variable or the config file")
+	}
+
+	host, err := url.Parse(backendURL)
 	if err != nil {
-		return nil, diag.Errorf("backend_url was not a valid url: %s", err.Error())
+		return nil, attributeErrorf(cty.GetAttrPath("backend_url"), "backend_url was not a valid url: %s", err.Error())
+	}
+	if basePath := d.Get("base_path").(string); basePath != "" {
+		host = host.JoinPath(basePath)
 	}
 
 	timeout, err := time.ParseDuration(d.Get("timeout").(string))
 	if err != nil {
-		return nil, diag.Errorf("timeout was not a valid duration: %s", err.Error())
+		return nil, attributeErrorf(cty.GetAttrPath("timeout"), "timeout was not a valid duration: %s", err.Error())
+	}
+
+	readTimeout, writeTimeout := timeout, timeout
+	if v := d.Get("read_timeout").(string); v != "" {
+		if readTimeout, err = time.ParseDuration(v); err != nil {
+			return nil, attributeErrorf(cty.GetAttrPath("read_timeout"), "read_timeout was not a valid duration: %s", err.Error())
+		}
+	}
+	if v := d.Get("write_timeout").(string); v != "" {
+		if writeTimeout, err = time.ParseDuration(v); err != nil {
+			return nil, attributeErrorf(cty.GetAttrPath("write_timeout"), "write_timeout was not a valid duration: %s", err.Error())
+		}
+	}
+
+	retryMinWait, err := time.ParseDuration(d.Get("retry_min_wait").(string))
+	if err != nil {
+		return nil, attributeErrorf(cty.GetAttrPath("retry_min_wait"), "retry_min_wait was not a valid duration: %s", err.Error())
+	}
+
+	retryMaxWait, err := time.ParseDuration(d.Get("retry_max_wait").(string))
+	if err != nil {
+		return nil, attributeErrorf(cty.GetAttrPath("retry_max_wait"), "retry_max_wait was not a valid duration: %s", err.Error())
+	}
+	if retryMaxWait < retryMinWait {
+		return nil, attributeErrorf(cty.GetAttrPath("retry_max_wait"), "retry_max_wait must not be less than retry_min_wait")
+	}
+
+	readinessTimeout, err := time.ParseDuration(d.Get("readiness_timeout").(string))
+	if err != nil {
+		return nil, attributeErrorf(cty.GetAttrPath("readiness_timeout"), "readiness_timeout was not a valid duration: %s", err.Error())
+	}
+
+	tlsConfig, err := buildTLSConfig(d)
+	if err != nil {
+		return nil, diag.Errorf("invalid TLS configuration: %s", err.Error())
+	}
+
+	var proxyURL *url.URL
+	if proxy := d.Get("proxy_url").(string); proxy != "" {
+		if proxyURL, err = url.Parse(proxy); err != nil {
+			return nil, attributeErrorf(cty.GetAttrPath("proxy_url"), "proxy_url was not a valid url: %s", err.Error())
+		}
+	}
+
+	idleConnTimeout, err := time.ParseDuration(d.Get("idle_conn_timeout").(string))
+	if err != nil {
+		return nil, attributeErrorf(cty.GetAttrPath("idle_conn_timeout"), "idle_conn_timeout was not a valid duration: %s", err.Error())
+	}
+
+	tlsHandshakeTimeout, err := time.ParseDuration(d.Get("tls_handshake_timeout").(string))
+	if err != nil {
+		return nil, attributeErrorf(cty.GetAttrPath("tls_handshake_timeout"), "tls_handshake_timeout was not a valid duration: %s", err.Error())
+	}
+
+	client := NewClient(host.String(), apiKey, timeout)
+	client.HTTPClient.Transport = newTransport(transportOptions{
+		TLSConfig:           tlsConfig,
+		ProxyURL:            proxyURL,
+		MaxIdleConnsPerHost: d.Get("max_idle_conns").(int),
+		IdleConnTimeout:     idleConnTimeout,
+		TLSHandshakeTimeout: tlsHandshakeTimeout,
+		DisableHTTP2:        d.Get("disable_http2").(bool),
+	})
+	client.UserAgent = userAgent
+	client.TenantID = tenantID
+	client.listCache = newListCache(defaultListCacheTTL)
+	// the http client timeout would bound both reads and writes, so per request deadlines replace it
+	client.HTTPClient.Timeout = 0
+	client.ReadTimeout = readTimeout
+	client.WriteTimeout = writeTimeout
+	client.AuthType = d.Get("auth_type").(string)
+	if oauth2Config != nil {
+		client.AuthType = authTypeBearer
+		client.Credentials = &oauth2TokenSource{
+			HTTPClient:   &http.Client{Timeout: timeout, Transport: client.HTTPClient.Transport},
+			TokenURL:     oauth2Config["token_url"].(string),
+			ClientID:     oauth2Config["client_id"].(string),
+			ClientSecret: oauth2Config["client_secret"].(string),
+			Scopes:       cast.ToStringSlice(oauth2Config["scopes"]),
+		}
+	} else if apiKeyFile != "" {
+		credentials := &fileCredentialSource{Path: apiKeyFile}
+		if _, err := credentials.Token(ctx); err != nil {
+			return nil, attributeErrorf(cty.GetAttrPath("api_key_file"), "cannot read api_key_file: %s", err.Error())
+		}
+		client.Credentials = credentials
+	}
+	client.Headers = cast.ToStringMapString(d.Get("headers"))
+	if headerTemplates := cast.ToStringMapString(d.Get("header_templates")); authExecConfig != nil || len(headerTemplates) > 0 {
+		var source *execAuthSource
+		if authExecConfig != nil {
+			source = &execAuthSource{
+				Command:    authExecConfig["command"].(string),
+				Args:       cast.ToStringSlice(authExecConfig["args"]),
+				Env:        cast.ToStringMapString(authExecConfig["env"]),
+				PerRequest: authExecConfig["per_request"].(bool),
+			}
+			if authExecCredentials {
+				client.Credentials = source
+			}
+		}
+		if client.AuthHook, err = newAuthHook(source, headerTemplates); err != nil {
+			return nil, attributeErrorf(cty.GetAttrPath("header_templates"), "invalid header_templates: %s", err.Error())
+		}
+	}
+	if maxConcurrentRequests := d.Get("max_concurrent_requests").(int); maxConcurrentRequests > 0 {
+		client.RequestSlots = make(chan struct{}, maxConcurrentRequests)
+	}
+	if requestsPerSecond := d.Get("requests_per_second").(float64); requestsPerSecond > 0 {
+		client.RateLimiter = newRateLimiter(requestsPerSecond, d.Get("burst").(int))
+	}
+	client.MaxRetries = d.Get("max_retries").(int)
+	client.RetryMinWait = retryMinWait
+	client.RetryMaxWait = retryMaxWait
+	client.ReadinessTimeout = readinessTimeout
+	if threshold := d.Get("circuit_breaker_threshold").(int); threshold > 0 {
+		client.CircuitBreaker = newCircuitBreaker(threshold, defaultCircuitBreakerCooldown)
 	}
+	if d.Get("request_compression").(string) == compressionGzip {
+		client.Compression = newRequestCompression(defaultCompressionMinSize)
+	}
+	if d.Get("opentelemetry_tracing").(bool) {
+		if client.Tracer, err = newTracer(ctx); err != nil {
+			return nil, attributeErrorf(cty.GetAttrPath("opentelemetry_tracing"), "failed to set up OpenTelemetry tracing: %s", err.Error())
+		}
+	}
+
+	if d.Get("validate_credentials").(bool) {
+		if diags := validateCredentials(ctx, client); diags.HasError() {
+			return nil, diags
+		}
+	}
+
+	return client, nil
+}
 
-	return NewClient(host.String(), d.Get("api_key").(string), timeout), nil
+// validateCredentials sends an authenticated request to the backend, so a wrong backend_url, rejected
+// credentials or TLS failures are reported once with a precise error instead of failing every resource
+func validateCredentials(ctx context.Context, client *Client) diag.Diagnostics {
+	_, errResp, err := client.WhoAmI(ctx)
+	if err == nil {
+		return nil
+	}
+
+	var certificateErr *tls.CertificateVerificationError
+	var hostnameErr x509.HostnameError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+
+	summary, detail := "Cannot connect to the Keep backend", err.Error()
+	if errResp != nil {
+		detail = fmt.Sprintf("%s. Details: %s", errResp.Error, errResp.Details)
+	}
+
+	switch {
+	case errors.As(err, &certificateErr), errors.As(err, &hostnameErr), errors.As(err, &unknownAuthorityErr):
+		summary = "TLS certificate of the Keep backend cannot be verified"
+		detail = fmt.Sprintf("%s. Set ca_cert_pem or ca_cert_file to trust its certificate authority.", err)
+	case errors.As(err, &dnsErr):
+		summary = "Host of backend_url cannot be resolved"
+	case errors.As(err, &opErr):
+		summary = "Keep backend is not reachable at backend_url"
+	case errors.Is(err, ErrUnauthorized):
+		summary = "Credentials were rejected by the Keep backend"
+		detail = "Check api_key, api_key_file or oauth2, and auth_type."
+	case isNotFound(err):
+		summary = "backend_url does not point to the Keep API"
+		detail = fmt.Sprintf("%s was not found, check the path of backend_url and base_path.", client.endpoint("whoami"))
+	}
+
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  summary,
+		Detail:   detail,
+	}}
 }
@@ -0,0 +1,1333 @@
+package keep
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestClientRequestDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", 10*time.Millisecond)
+	client.MaxRetries = 0
+
+	// the deadline of the resource operation doesn't lift the timeout of the client
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Minute)
+	defer cancel()
+	if _, _, err := client.GetInstalledProviders(ctx); err == nil {
+		t.Error("expected the request to time out")
+	}
+}
+
+func TestClientAvailableProvidersCache(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		w.Write([]byte(`{"providers": [{"type": "grafana"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", 30*time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			providers, _, err := client.GetAvailableProviders(context.Background())
+			if err != nil || len(providers) != 1 {
+				t.Errorf("unexpected result: %v, %v", providers, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if requests != 1 {
+		t.Errorf("expected a single request, got %d", requests)
+	}
+}
+
+func TestClientWithTenant(t *testing.T) {
+	var tenants []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenants = append(tenants, r.Header.Get("X-Tenant-Id"))
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", 30*time.Second)
+	client.GetInstalledProviders(context.Background())
+	client.WithTenant("tenant-a").GetInstalledProviders(context.Background())
+	client.WithTenant("").GetInstalledProviders(context.Background())
+
+	if strings.Join(tenants, ",") != ",tenant-a," {
+		t.Errorf("unexpected tenant headers: %q", tenants)
+	}
+}
+
+func TestClientHeaders(t *testing.T) {
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", 30*time.Second)
+	client.Headers = map[string]string{
+		"CF-Access-Client-Id": "client-id",
+		"X-API-Key":           "other",
+	}
+	client.GetInstalledProviders(context.Background())
+
+	if headers.Get("CF-Access-Client-Id") != "client-id" {
+		t.Errorf("expected the custom header to be sent, got %q", headers.Get("CF-Access-Client-Id"))
+	}
+	if headers.Get("X-API-Key") != "key" {
+		t.Errorf("expected the api key not to be overridden, got %q", headers.Get("X-API-Key"))
+	}
+}
+
+func TestClientAuthType(t *testing.T) {
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", 30*time.Second)
+	client.GetInstalledProviders(context.Background())
+	if headers.Get("X-API-Key") != "key" || headers.Get("Authorization") != "" {
+		t.Errorf("expected the api key header, got %v", headers)
+	}
+
+	client.AuthType = authTypeBearer
+	client.GetInstalledProviders(context.Background())
+	if headers.Get("X-API-Key") != "" || headers.Get("Authorization") != "Bearer key" {
+		t.Errorf("expected the bearer token, got %v", headers)
+	}
+}
+
+func TestClientOAuth2(t *testing.T) {
+	tokens := 0
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		clientID, clientSecret, _ := r.BasicAuth()
+		if r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("scope") != "keep:read keep:write" || clientID != "id" || clientSecret != "secret" {
+			t.Errorf("unexpected token request: %v", r.Form)
+		}
+		tokens++
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":3600}`, tokens)
+	}))
+	defer tokenServer.Close()
+
+	var authorizations []string
+	revoked := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		if r.Header.Get("X-API-Key") != "" {
+			t.Errorf("expected no api key, got %q", r.Header.Get("X-API-Key"))
+		}
+		if r.Header.Get("Authorization") == revoked {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", 30*time.Second)
+	client.AuthType = authTypeBearer
+	client.Credentials = &oauth2TokenSource{
+		HTTPClient:   http.DefaultClient,
+		TokenURL:     tokenServer.URL,
+		ClientID:     "id",
+		ClientSecret: "secret",
+		Scopes:       []string{"keep:read", "keep:write"},
+	}
+
+	if _, _, err := client.GetInstalledProviders(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, _, err := client.GetInstalledProviders(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	revoked = "Bearer token-1"
+	if _, _, err := client.GetInstalledProviders(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := "Bearer token-1,Bearer token-1,Bearer token-1,Bearer token-2"
+	if strings.Join(authorizations, ",") != expected {
+		t.Errorf("expected authorizations %s, got %q", expected, authorizations)
+	}
+	if tokens != 2 {
+		t.Errorf("expected 2 token requests, got %d", tokens)
+	}
+}
+
+func TestClientApiKeyFile(t *testing.T) {
+	apiKeyFile := filepath.Join(t.TempDir(), "api-key")
+	os.WriteFile(apiKeyFile, []byte("key-1\n"), 0o600)
+
+	var apiKeys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKeys = append(apiKeys, r.Header.Get("X-API-Key"))
+		if r.Header.Get("X-API-Key") != "key-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", 30*time.Second)
+	client.Credentials = &fileCredentialSource{Path: apiKeyFile}
+
+	if _, _, err := client.GetInstalledProviders(context.Background()); err == nil {
+		t.Fatal("expected an error for the rejected api key")
+	}
+
+	os.WriteFile(apiKeyFile, []byte("key-2\n"), 0o600)
+	if _, _, err := client.GetInstalledProviders(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := "key-1,key-1,key-1,key-2"
+	if strings.Join(apiKeys, ",") != expected {
+		t.Errorf("expected api keys %s, got %q", expected, apiKeys)
+	}
+}
+
+func TestClientRateLimiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", 30*time.Second)
+	client.RateLimiter = newRateLimiter(20, 2)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.GetInstalledProviders(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	// 2 requests are allowed by the burst, the other 4 are spread 50ms apart
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Errorf("expected the requests to be rate limited, took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client.RateLimiter = newRateLimiter(1, 1)
+	client.GetInstalledProviders(context.Background())
+	if _, _, err := client.GetInstalledProviders(ctx); err == nil {
+		t.Error("expected an error when the context is canceled while waiting")
+	}
+}
+
+func TestClientRequestSlots(t *testing.T) {
+	var mu sync.Mutex
+	active, maxActive := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", 30*time.Second)
+	client.RequestSlots = make(chan struct{}, 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.GetInstalledProviders(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	if maxActive != 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", maxActive)
+	}
+}
+
+func TestClientContextCanceled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(server.URL, "key", 30*time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	if _, _, err := client.GetInstalledProviders(ctx); err == nil {
+		t.Error("expected the canceled request to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the request to be canceled with its context, took %s", elapsed)
+	}
+}
+
+func TestClientRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		if r.Method == "GET" {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", 0)
+	client.MaxRetries = 0
+	client.ReadTimeout = 10 * time.Millisecond
+	client.WriteTimeout = time.Second
+
+	if _, _, err := client.GetInstalledProviders(context.Background()); err == nil {
+		t.Error("expected the read to time out")
+	}
+	if _, err := client.TestProvider(context.Background(), map[string]interface{}{}); err != nil {
+		t.Errorf("expected the write not to time out, got %s", err)
+	}
+
+	// the earlier of the read timeout and the deadline of the context applies
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Minute)
+	defer cancel()
+	if _, _, err := client.GetInstalledProviders(ctx); err == nil {
+		t.Error("expected the read timeout to apply within the deadline of the context")
+	}
+
+	shortCtx, shortCancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer shortCancel()
+	if _, err := client.TestProvider(shortCtx, map[string]interface{}{}); err == nil {
+		t.Error("expected the deadline of the context to apply within the write timeout")
+	}
+}
+
+func TestClientRetry(t *testing.T) {
+	cases := []struct {
+		name             string
+		method           string
+		statusCodes      []int
+		expectedRequests int
+		expectError      bool
+	}{
+		{name: "server errors of GET", method: "GET", statusCodes: []int{502, 504, 200}, expectedRequests: 3},
+		{name: "rate limited POST", method: "POST", statusCodes: []int{429, 200}, expectedRequests: 2},
+		{name: "server error of POST", method: "POST", statusCodes: []int{502, 200}, expectedRequests: 1, expectError: true},
+		{name: "client error", method: "GET", statusCodes: []int{404, 200}, expectedRequests: 1, expectError: true},
+		{name: "retries exhausted", method: "PUT", statusCodes: []int{503, 503, 503, 503, 503}, expectedRequests: 4, expectError: true},
+		{name: "request timeout of DELETE", method: "DELETE", statusCodes: []int{408, 200}, expectedRequests: 2},
+		{name: "request timeout of POST", method: "POST", statusCodes: []int{408, 200}, expectedRequests: 1, expectError: true},
+		{name: "transport error of GET", method: "GET", statusCodes: []int{0, 200}, expectedRequests: 2},
+		{name: "transport error of POST", method: "POST", statusCodes: []int{0, 200}, expectedRequests: 1, expectError: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body := make([]byte, r.ContentLength)
+				r.Body.Read(body)
+				if r.ContentLength > 0 && string(body) != `{"name":"test"}` {
+					t.Errorf("unexpected request body on attempt %d: %q", requests+1, body)
+				}
+
+				statusCode := tc.statusCodes[requests]
+				requests++
+				if statusCode == 0 {
+					// drop the connection without a response
+					conn, _, _ := w.(http.Hijacker).Hijack()
+					conn.Close()
+					return
+				}
+				w.WriteHeader(statusCode)
+				w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, "key", 30*time.Second)
+			client.RetryMinWait = time.Millisecond
+			client.RetryMaxWait = 5 * time.Millisecond
+
+			req, _ := http.NewRequest(tc.method, server.URL, strings.NewReader(`{"name":"test"}`))
+			_, _, err := client.doReq(req)
+			if (err != nil) != tc.expectError {
+				t.Errorf("expected error %t, got %v", tc.expectError, err)
+			}
+			if requests != tc.expectedRequests {
+				t.Errorf("expected %d requests, got %d", tc.expectedRequests, requests)
+			}
+		})
+	}
+}
+
+func TestClientRetryWait(t *testing.T) {
+	client := NewClient("http://localhost", "key", 30*time.Second)
+	client.RetryMinWait = time.Second
+	client.RetryMaxWait = 5 * time.Second
+
+	for attempt, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		waits := make(map[time.Duration]bool)
+		for i := 0; i < 20; i++ {
+			wait := client.retryWait(attempt)
+			if wait < expected/2 || wait > expected {
+				t.Errorf("attempt %d: expected between %s and %s, got %s", attempt, expected/2, expected, wait)
+			}
+			waits[wait] = true
+		}
+		if len(waits) == 1 {
+			t.Errorf("attempt %d: expected jitter, got the same wait every time", attempt)
+		}
+	}
+}
+
+func TestClientLogRedaction(t *testing.T) {
+	client := NewClient("http://localhost", "key", 30*time.Second)
+	client.Headers = map[string]string{"CF-Access-Client-Secret": "secret"}
+
+	header := http.Header{}
+	header.Set("X-API-Key", "key")
+	header.Set("CF-Access-Client-Secret", "secret")
+	header.Set("Content-Type", "application/json")
+
+	headers := client.redactHeaders(header)
+	if headers["X-Api-Key"] != redactedValue || headers["Cf-Access-Client-Secret"] != redactedValue || headers["Content-Type"] != "application/json" {
+		t.Errorf("unexpected redacted headers: %v", headers)
+	}
+
+	body := redactBody("/providers/install", []byte(`{"provider_name":"grafana","host":"https://grafana.example.com","token":"secret","pulling_enabled":true,"details":{"authentication":{"token":"secret"}}}`))
+	expected := `{"details":{"authentication":{"token":"***"}},"host":"***","provider_name":"grafana","pulling_enabled":true,"token":"***"}`
+	if body != expected {
+		t.Errorf("expected redacted body %s, got %s", expected, body)
+	}
+
+	if body := redactBody("/workflows", []byte(`{"name":"workflow"}`)); body != `{"name":"workflow"}` {
+		t.Errorf("expected the body of other requests to be kept, got %s", body)
+	}
+}
+
+func TestClientRequireEndpoint(t *testing.T) {
+	cases := map[string]struct {
+		openAPI          string
+		expectedVersion  string
+		expectError      bool
+		expectedRequests []string
+	}{
+		"old backend": {
+			openAPI:          `{"info":{"version":"0.10.0"},"paths":{"/workflows":{"post":{}}}}`,
+			expectedVersion:  "0.10.0",
+			expectError:      true,
+			expectedRequests: []string{"GET /openapi.json"},
+		},
+		"new backend": {
+			openAPI:          `{"info":{"version":"0.42.5"},"paths":{"/workflows/json":{"post":{}}}}`,
+			expectedVersion:  "0.42.5",
+			expectedRequests: []string{"GET /openapi.json", "POST /workflows/json", "POST /workflows/json"},
+		},
+		"unknown backend": {
+			expectedRequests: []string{"GET /openapi.json", "POST /workflows/json", "POST /workflows/json"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var requests []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				switch {
+				case r.URL.Path == "/openapi.json" && tc.openAPI != "":
+					w.Write([]byte(tc.openAPI))
+				case r.URL.Path == "/openapi.json":
+					w.WriteHeader(http.StatusNotFound)
+				default:
+					w.Write([]byte(`{"workflow_id":"test"}`))
+				}
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, "key", 30*time.Second)
+			for i := 0; i < 2; i++ {
+				_, errResp, err := client.CreateWorkflowJSON(context.Background(), map[string]interface{}{"name": "test"})
+				if (err != nil) != tc.expectError {
+					t.Fatalf("expected error %t, got %v", tc.expectError, err)
+				}
+				if tc.expectError && (!strings.Contains(errResp.Details, "POST /workflows/json") || !strings.Contains(errResp.Details, "requires Keep >= "+minimumKeepVersion)) {
+					t.Errorf("expected the details to name the endpoint and the minimum version, got %q", errResp.Details)
+				}
+			}
+
+			if client.BackendVersion(context.Background()) != tc.expectedVersion {
+				t.Errorf("expected version %q, got %q", tc.expectedVersion, client.BackendVersion(context.Background()))
+			}
+			if strings.Join(requests, ",") != strings.Join(tc.expectedRequests, ",") {
+				t.Errorf("expected requests %v, got %v", tc.expectedRequests, requests)
+			}
+		})
+	}
+}
+
+func TestMinimumKeepVersion(t *testing.T) {
+	content, err := os.ReadFile("../openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var document struct {
+		Info struct {
+			Version string `json:"version"`
+		} `json:"info"`
+	}
+	if err := json.Unmarshal(content, &document); err != nil {
+		t.Fatal(err)
+	}
+	if document.Info.Version != minimumKeepVersion {
+		t.Errorf("expected minimumKeepVersion to be the version of openapi.json %s, got %s", document.Info.Version, minimumKeepVersion)
+	}
+}
+
+func TestClientAuthHook(t *testing.T) {
+	command := filepath.Join(t.TempDir(), "token.sh")
+	script := `#!/bin/sh
+echo "{\"token\":\"token-$TOKEN_SUFFIX\",\"headers\":{\"X-Signature\":\"$KEEP_REQUEST_METHOD $KEEP_REQUEST_URL\"}}"
+`
+	if err := os.WriteFile(command, []byte(script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	hook, err := newAuthHook(
+		&execAuthSource{Command: command, Env: map[string]string{"TOKEN_SUFFIX": "1"}, PerRequest: true},
+		map[string]string{"Proxy-Authorization": "Bearer {{ .Token }}"},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	client := NewClient(server.URL, "key", 30*time.Second)
+	client.AuthHook = hook
+	if _, _, err := client.GetInstalledProviders(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if headers.Get("X-API-Key") != "key" {
+		t.Errorf("expected the api key not to be replaced, got %q", headers.Get("X-API-Key"))
+	}
+	if headers.Get("Proxy-Authorization") != "Bearer token-1" {
+		t.Errorf("expected the rendered header template, got %q", headers.Get("Proxy-Authorization"))
+	}
+	if expected := "GET " + server.URL + "/providers/export?limit=100&offset=0"; headers.Get("X-Signature") != expected {
+		t.Errorf("expected header %s of the auth command, got %q", expected, headers.Get("X-Signature"))
+	}
+
+	redacted := client.redactHeaders(headers)
+	if redacted["Proxy-Authorization"] != redactedValue || redacted["X-Signature"] != redactedValue {
+		t.Errorf("expected the headers of the auth hook to be redacted, got %v", redacted)
+	}
+
+	if _, err := newAuthHook(nil, map[string]string{"Proxy-Authorization": "{{ .Token"}); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+}
+
+func TestClientTypedModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/mapping":
+			w.Write([]byte(`[{"id": 7, "name": "teams", "matchers": [["service", "env"]]}, {"id": 8, "name": "legacy", "matchers": ["host && env"]}]`))
+		case "/extraction":
+			w.Write([]byte(`[{"id": 3, "name": "region", "priority": 2, "created_by": "alice"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", 30*time.Second)
+
+	mappings, _, err := client.GetMappings(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(mappings) != 2 || mappings[0].ID != "7" || mappings[0].Matchers[0].String() != "service && env" ||
+		mappings[1].Matchers[0].String() != "host && env" {
+		t.Errorf("unexpected mappings: %+v", mappings)
+	}
+
+	extractions, _, err := client.GetExtractions(context.Background())
+	if err != nil || len(extractions) != 1 {
+		t.Fatalf("unexpected result: %v, %v", extractions, err)
+	}
+	extraction := extractions[0]
+	if extraction.ID != "3" || extraction.Priority != 2 {
+		t.Errorf("unexpected extraction: %+v", extraction)
+	}
+	if payload := extraction.Payload(); payload.ID != "" || payload.CreatedBy != "" || payload.Name != "region" {
+		t.Errorf("expected the payload to only contain writable attributes, got %+v", payload)
+	}
+}
+
+func TestClientGeneratedModels(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		switch r.Method + " " + r.URL.Path {
+		case "GET /incidents":
+			w.Write([]byte(`{"items": [{"id": "inc-1", "user_generated_name": "Checkout down", "severity": "critical", "status": "firing", "alerts_count": 3}], "count": 1, "limit": 100, "offset": 0}`))
+		case "POST /incidents":
+			w.Write([]byte(`{"id": "inc-2", "user_generated_name": "Latency", "severity": "warning"}`))
+		case "POST /deduplications":
+			w.Write([]byte(`{"id": "rule-1", "name": "grafana"}`))
+		case "GET /topology/applications":
+			w.Write([]byte(`[{"id": "app-1", "name": "checkout", "services": [{"id": "1", "name": "api", "service": "checkout-api"}]}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", 30*time.Second)
+	ctx := context.Background()
+
+	incidents, _, err := client.GetIncidents(ctx)
+	if err != nil || len(incidents) != 1 {
+		t.Fatalf("unexpected result: %+v, %v", incidents, err)
+	}
+	if incidents[0].ID != "inc-1" || incidents[0].Severity != IncidentSeverityCritical || incidents[0].Status != IncidentStatusFiring || incidents[0].AlertsCount != 3 {
+		t.Errorf("unexpected incident: %+v", incidents[0])
+	}
+
+	incident, _, err := client.CreateIncident(ctx, IncidentDtoIn{UserGeneratedName: "Latency", Severity: IncidentSeverityWarning})
+	if err != nil || incident.ID != "inc-2" {
+		t.Fatalf("unexpected result: %+v, %v", incident, err)
+	}
+
+	ruleID, _, err := client.CreateDeduplicationRule(ctx, DeduplicationRuleRequestDto{Name: "grafana", ProviderType: "grafana", FingerprintFields: []string{"fingerprint"}})
+	if err != nil || ruleID != "rule-1" {
+		t.Fatalf("unexpected result: %q, %v", ruleID, err)
+	}
+
+	applications, _, err := client.GetTopologyApplications(ctx)
+	if err != nil || len(applications) != 1 || applications[0].Services[0].Service != "checkout-api" {
+		t.Fatalf("unexpected result: %+v, %v", applications, err)
+	}
+
+	expected := []string{
+		"GET /incidents ",
+		`POST /incidents {"severity":"warning","user_generated_name":"Latency"}`,
+		`POST /deduplications {"fingerprint_fields":["fingerprint"],"name":"grafana","provider_type":"grafana"}`,
+		"GET /topology/applications ",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected requests %q, got %q", expected, requests)
+	}
+}
+
+func TestClientPagination(t *testing.T) {
+	listPageSize = 2
+	defer func() { listPageSize = 100 }()
+
+	cases := map[string]func(w http.ResponseWriter, r *http.Request){
+		"plain list": func(w http.ResponseWriter, r *http.Request) {
+			pages := map[string]string{"0": `[{"id": 1}, {"id": 2}]`, "2": `[{"id": 3}]`}
+			w.Write([]byte(pages[r.URL.Query().Get("offset")]))
+		},
+		"plain list ignoring offset": func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`[{"id": 1}, {"id": 2}, {"id": 3}]`))
+		},
+		"total count": func(w http.ResponseWriter, r *http.Request) {
+			pages := map[string]string{"0": `{"items": [{"id": 1}, {"id": 2}], "total": 3}`, "2": `{"items": [{"id": 3}], "total": 3}`}
+			w.Write([]byte(pages[r.URL.Query().Get("offset")]))
+		},
+		"cursor": func(w http.ResponseWriter, r *http.Request) {
+			pages := map[string]string{"": `{"results": [{"id": 1}, {"id": 2}], "next_cursor": "c2"}`, "c2": `{"results": [{"id": 3}], "next_cursor": ""}`}
+			w.Write([]byte(pages[r.URL.Query().Get("cursor")]))
+		},
+		"next link": func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("page") == "2" {
+				w.Write([]byte(`{"items": [{"id": 3}], "next": null}`))
+				return
+			}
+			w.Write([]byte(`{"items": [{"id": 1}, {"id": 2}], "next": "/mapping?page=2"}`))
+		},
+	}
+
+	for name, handler := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(handler))
+			defer server.Close()
+
+			client := NewClient(server.URL, "key", 30*time.Second)
+			mappings, _, err := client.GetMappings(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			ids := make([]string, len(mappings))
+			for i, mapping := range mappings {
+				ids[i] = string(mapping.ID)
+			}
+			if strings.Join(ids, ",") != "1,2,3" {
+				t.Errorf("expected the mappings of all pages, got %v", ids)
+			}
+		})
+	}
+}
+
+func TestClientListCache(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.Method+" "+r.Header.Get("X-Tenant-Id")]++
+		mu.Unlock()
+		if r.Method == "GET" {
+			w.Write([]byte(`[{"id": 1}]`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", 30*time.Second)
+	client.listCache = newListCache(time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if mappings, _, err := client.GetMappings(context.Background()); err != nil || len(mappings) != 1 {
+				t.Errorf("unexpected result: %v, %v", mappings, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if requests["GET "] != 1 {
+		t.Errorf("expected a single list request, got %d", requests["GET "])
+	}
+
+	client.WithTenant("tenant-a").(*Client).GetMappings(context.Background())
+	if requests["GET tenant-a"] != 1 {
+		t.Errorf("expected a list request of the other tenant, got %d", requests["GET tenant-a"])
+	}
+
+	client.DeleteMapping(context.Background(), "1")
+	client.GetMappings(context.Background())
+	if requests["GET "] != 2 {
+		t.Errorf("expected the write to invalidate the cached list, got %d list requests", requests["GET "])
+	}
+
+	client.listCache.TTL = 0
+	client.GetMappings(context.Background())
+	if requests["GET "] != 3 {
+		t.Errorf("expected the expired list to be requested again, got %d list requests", requests["GET "])
+	}
+}
+
+func TestClientGetByID(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/openapi.json":
+			w.Write([]byte(`{"info":{"version":"0.42.5"},"paths":{"/providers/{provider_id}":{"get":{}}}}`))
+		case "/providers/p1":
+			w.Write([]byte(`{"id":"p1","type":"slack"}`))
+		case "/providers/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/mapping/1":
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case "/mapping":
+			w.Write([]byte(`[{"id":1,"name":"test"}]`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", 30*time.Second)
+
+	provider, _, err := client.GetInstalledProvider(context.Background(), "p1")
+	if err != nil || provider == nil || provider.Type != "slack" {
+		t.Fatalf("unexpected provider: %v, %v", provider, err)
+	}
+	if provider, _, err := client.GetInstalledProvider(context.Background(), "missing"); err != nil || provider != nil {
+		t.Errorf("expected a missing provider, got %v, %v", provider, err)
+	}
+
+	// the openapi document doesn't list the mapping endpoint, so mappings are always read from the list
+	for i := 0; i < 2; i++ {
+		mapping, _, err := getMapping(context.Background(), client, "1")
+		if err != nil || mapping == nil || mapping.Name != "test" {
+			t.Fatalf("unexpected mapping: %v, %v", mapping, err)
+		}
+	}
+
+	expected := []string{"GET /openapi.json", "GET /providers/p1", "GET /providers/missing", "GET /mapping", "GET /mapping"}
+	if strings.Join(requests, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected requests %v, got %v", expected, requests)
+	}
+}
+
+func TestClientGetByIDRejected(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/openapi.json":
+			w.WriteHeader(http.StatusNotFound)
+		case "/mapping/1":
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case "/mapping":
+			w.Write([]byte(`[{"id":1,"name":"test"}]`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", 30*time.Second)
+	for i := 0; i < 2; i++ {
+		mapping, _, err := getMapping(context.Background(), client, "1")
+		if err != nil || mapping == nil || mapping.Name != "test" {
+			t.Fatalf("unexpected mapping: %v, %v", mapping, err)
+		}
+	}
+
+	// the rejected endpoint isn't requested again
+	expected := []string{"GET /openapi.json", "GET /mapping/1", "GET /mapping", "GET /mapping"}
+	if strings.Join(requests, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected requests %v, got %v", expected, requests)
+	}
+}
+
+func TestClientAPIError(t *testing.T) {
+	cases := map[string]struct {
+		statusCode      int
+		body            string
+		expectedError   string
+		expectedDetails string
+		expectedKind    error
+	}{
+		"error response": {
+			statusCode:      http.StatusConflict,
+			body:            `{"error":"conflict","details":"mapping exists"}`,
+			expectedError:   "conflict",
+			expectedDetails: "mapping exists",
+			expectedKind:    ErrConflict,
+		},
+		"fastapi detail": {
+			statusCode:      http.StatusNotFound,
+			body:            `{"detail":"Mapping not found"}`,
+			expectedError:   "request failed with status 404",
+			expectedDetails: "Mapping not found",
+			expectedKind:    ErrNotFound,
+		},
+		"plain body": {
+			statusCode:      http.StatusBadGateway,
+			body:            `bad gateway`,
+			expectedError:   "request failed with status 502",
+			expectedDetails: "bad gateway",
+			expectedKind:    ErrTransient,
+		},
+		"missing scopes": {
+			statusCode:      http.StatusForbidden,
+			body:            `{"detail":{"write:mappings":"Missing scope"}}`,
+			expectedError:   "Insufficient permissions",
+			expectedDetails: "Missing required scopes: [write:mappings]",
+			expectedKind:    ErrUnauthorized,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Request-Id", "req-1")
+				w.WriteHeader(tc.statusCode)
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, "key", 30*time.Second)
+			client.MaxRetries = 0
+			errResp, err := client.DeleteMapping(context.Background(), "1")
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected an API error, got %v", err)
+			}
+			if apiErr.StatusCode != tc.statusCode || apiErr.Method != "DELETE" || apiErr.Endpoint != "/mapping/1" || apiErr.RequestID != "req-1" {
+				t.Errorf("unexpected request context: %+v", apiErr)
+			}
+			if apiErr.Response.Error != tc.expectedError || apiErr.Response.Details != tc.expectedDetails {
+				t.Errorf("unexpected parsed error: %+v", apiErr.Response)
+			}
+			if errResp == nil || errResp.Error != tc.expectedError || errResp.Details != tc.expectedDetails+" (request id req-1)" {
+				t.Errorf("unexpected error response: %+v", errResp)
+			}
+			if !hasStatus(err, tc.statusCode) || isNotFound(err) != (tc.statusCode == http.StatusNotFound) || isServerError(err) != (tc.statusCode >= 500) {
+				t.Errorf("unexpected classification of %v", err)
+			}
+			for _, kind := range []error{ErrNotFound, ErrConflict, ErrUnauthorized, ErrTransient} {
+				if errors.Is(err, kind) != (kind == tc.expectedKind) {
+					t.Errorf("expected errors.Is(%v) to be %t", kind, kind == tc.expectedKind)
+				}
+			}
+		})
+	}
+}
+
+func TestClientTransportErrorKind(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	client := NewClient(server.URL, "key", 30*time.Second)
+	client.MaxRetries = 0
+	_, _, err := client.GetWorkflow(context.Background(), "1")
+	if !errors.Is(err, ErrTransient) || errors.Is(err, ErrNotFound) {
+		t.Errorf("expected an unreachable backend to be transient, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := client.GetWorkflow(ctx, "1"); err == nil || errors.Is(err, ErrTransient) {
+		t.Errorf("expected a canceled request not to be transient, got %v", err)
+	}
+}
+
+func TestClientRequestID(t *testing.T) {
+	var requestIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestIDs = append(requestIDs, r.Header.Get("X-Request-Id"))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", 30*time.Second)
+	client.MaxRetries = 1
+	client.RetryMinWait = time.Millisecond
+	client.RetryMaxWait = time.Millisecond
+
+	errResp, err := client.DeleteMapping(context.Background(), "1")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(requestIDs) != 2 || requestIDs[0] == "" || requestIDs[0] != requestIDs[1] {
+		t.Fatalf("expected the retry to reuse the request id, got %v", requestIDs)
+	}
+	if !strings.Contains(errResp.Details, requestIDs[0]) {
+		t.Errorf("expected the details to contain the request id %s, got %q", requestIDs[0], errResp.Details)
+	}
+
+	client.DeleteMapping(context.Background(), "1")
+	if requestIDs[2] == requestIDs[0] {
+		t.Errorf("expected a new request id for a new call, got %v", requestIDs)
+	}
+}
+
+func TestClientSharedTransport(t *testing.T) {
+	var mu sync.Mutex
+	connections := 0
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": 1}]`))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			connections++
+			mu.Unlock()
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", 30*time.Second)
+	if other := NewClient(server.URL, "key", 30*time.Second); other.HTTPClient.Transport != client.HTTPClient.Transport {
+		t.Error("expected clients to share the transport")
+	}
+	if newTransport(transportOptions{}) != client.HTTPClient.Transport {
+		t.Error("expected the provider to use the shared transport without TLS configuration or proxy")
+	}
+	if newTransport(transportOptions{MaxIdleConnsPerHost: transportMaxIdleConnsPerHost, IdleConnTimeout: transportIdleConnTimeout}) != client.HTTPClient.Transport {
+		t.Error("expected the provider to use the shared transport with the default connection settings")
+	}
+	if newTransport(transportOptions{TLSConfig: &tls.Config{}}) == client.HTTPClient.Transport {
+		t.Error("expected a transport of its own with TLS configuration")
+	}
+
+	tuned := newTransport(transportOptions{MaxIdleConnsPerHost: 200, IdleConnTimeout: time.Minute, TLSHandshakeTimeout: time.Minute, DisableHTTP2: true})
+	if tuned == client.HTTPClient.Transport {
+		t.Error("expected a transport of its own with connection settings")
+	}
+	if tuned.MaxIdleConnsPerHost != 200 || tuned.MaxIdleConns != 200 || tuned.IdleConnTimeout != time.Minute || tuned.TLSHandshakeTimeout != time.Minute {
+		t.Errorf("unexpected connection settings of transport: %d, %d, %s, %s", tuned.MaxIdleConnsPerHost, tuned.MaxIdleConns, tuned.IdleConnTimeout, tuned.TLSHandshakeTimeout)
+	}
+	if tuned.ForceAttemptHTTP2 || tuned.TLSNextProto == nil {
+		t.Error("expected HTTP/2 to be disabled")
+	}
+
+	// concurrent operations of several tenants use the caches and the transport of the client
+	client.listCache = newListCache(time.Minute)
+	const workers = 8
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tenantClient := client.WithTenant(fmt.Sprintf("tenant-%d", i%2)).(*Client)
+			for j := 0; j < 10; j++ {
+				if _, _, err := tenantClient.GetMappings(context.Background()); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				if _, err := tenantClient.DeleteMapping(context.Background(), "1"); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if connections > 2*workers {
+		t.Errorf("expected keep-alive connections to be reused, got %d connections", connections)
+	}
+}
+
+func TestClientCompression(t *testing.T) {
+	var encodings []string
+	acceptGzip := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := r.Header.Get("Content-Encoding")
+		encodings = append(encodings, encoding)
+		if encoding == "gzip" && !acceptGzip {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+
+		body := io.Reader(r.Body)
+		if encoding == "gzip" {
+			reader, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("invalid gzip body: %v", err)
+				return
+			}
+			body = reader
+		}
+		content, _ := io.ReadAll(body)
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", 30*time.Second)
+	client.Compression = newRequestCompression(1024)
+
+	send := func(payload string) {
+		t.Helper()
+		req, _ := http.NewRequest("POST", server.URL, strings.NewReader(payload))
+		body, _, err := client.doReq(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(body) != payload {
+			t.Errorf("expected the backend to receive the payload, got %d of %d bytes", len(body), len(payload))
+		}
+	}
+	large := `{"rows": [` + strings.Repeat(`{"service": "checkout", "team": "payments"},`, 100) + `{}]}`
+
+	send(`{"name": "small"}`)
+	send(large)
+	if want := []string{"", "gzip"}; !reflect.DeepEqual(encodings, want) {
+		t.Errorf("expected encodings %q, got %q", want, encodings)
+	}
+
+	encodings = nil
+	acceptGzip = false
+	send(large)
+	send(large)
+	if want := []string{"gzip", "", ""}; !reflect.DeepEqual(encodings, want) {
+		t.Errorf("expected the rejected body to be sent uncompressed from then on, got encodings %q", encodings)
+	}
+}
+
+func TestClientCircuitBreaker(t *testing.T) {
+	requests := 0
+	down := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if down {
+			// drop the connection without a response
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", 30*time.Second)
+	client.MaxRetries = 0
+	client.CircuitBreaker = newCircuitBreaker(2, time.Hour)
+
+	for i := 0; i < 5; i++ {
+		if _, _, err := client.GetMappings(context.Background()); err == nil {
+			t.Fatal("expected an error")
+		}
+	}
+	if requests != 2 {
+		t.Errorf("expected requests to fail fast after 2 failures, got %d requests", requests)
+	}
+	_, errResp, err := client.WithTenant("tenant-a").(*Client).GetMappings(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "unreachable") || errResp == nil {
+		t.Errorf("expected copies of the client to fail fast with a clear error, got %v", err)
+	}
+
+	// after the cooldown a request probes the backend and closes the circuit
+	down = false
+	client.CircuitBreaker.Cooldown = 0
+	if _, _, err := client.GetMappings(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	client.CircuitBreaker.Cooldown = time.Hour
+	if _, _, err := client.GetMappings(context.Background()); err != nil {
+		t.Errorf("expected the circuit to be closed, got %v", err)
+	}
+	if requests != 4 {
+		t.Errorf("expected 4 requests, got %d", requests)
+	}
+}
+
+func TestClientRetryAfter(t *testing.T) {
+	var times []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		if len(times) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", 30*time.Second)
+	client.RetryMinWait = time.Millisecond
+	client.RetryMaxWait = time.Millisecond
+
+	req, _ := http.NewRequest("POST", server.URL, strings.NewReader(`{}`))
+	if _, _, err := client.doReq(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(times) != 2 || times[1].Sub(times[0]) < time.Second {
+		t.Errorf("expected the retry to wait for the Retry-After header, got %d requests", len(times))
+	}
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for value, expected := range map[string]time.Duration{
+		"":                              0,
+		"30":                            30 * time.Second,
+		"-1":                            0,
+		"soon":                          0,
+		"Mon, 01 Jan 2024 12:00:10 GMT": 10 * time.Second,
+		"Mon, 01 Jan 2024 11:59:00 GMT": 0,
+	} {
+		if wait := parseRetryAfter(value, now); wait != expected {
+			t.Errorf("Retry-After %q: expected %s, got %s", value, expected, wait)
+		}
+	}
+}
+
+func TestClientStreamedWorkflowUpload(t *testing.T) {
+	content := strings.Repeat("workflow:\n  id: test\n", 10000)
+	filePath := filepath.Join(t.TempDir(), "workflow.yml")
+	if err := os.WriteFile(filePath, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.ContentLength <= int64(len(content)) {
+			t.Errorf("expected content length of the multipart form on attempt %d, got %d", requests, r.ContentLength)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("expected multipart file on attempt %d: %v", requests, err)
+			return
+		}
+		defer file.Close()
+		received, _ := io.ReadAll(file)
+		if string(received) != content || header.Filename != "workflow.yml" {
+			t.Errorf("unexpected file %q of %d bytes on attempt %d", header.Filename, len(received), requests)
+		}
+
+		if requests == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"workflow_id":"test","revision":2}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key", 30*time.Second)
+	client.RetryMinWait = time.Millisecond
+	client.RetryMaxWait = 5 * time.Millisecond
+
+	if _, _, err := client.UpdateWorkflow(context.Background(), "test", filePath); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected the upload to be retried, got %d requests", requests)
+	}
+
+	if _, _, err := client.CreateWorkflow(context.Background(), filepath.Join(t.TempDir(), "missing.yml")); err == nil {
+		t.Error("expected error for missing workflow file")
+	}
+}
+
+func TestClientTracing(t *testing.T) {
+	var traceparent string
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("Traceparent")
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	client := NewClient(server.URL, "key", 30*time.Second)
+	client.RetryMinWait = time.Millisecond
+	client.RetryMaxWait = 5 * time.Millisecond
+	client.Tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	if _, _, err := client.GetWorkflow(context.Background(), "1"); !isNotFound(err) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected one span of the call including its retry, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /workflows/1" || span.Status().Code != codes.Error {
+		t.Errorf("unexpected span %q with status %v", span.Name(), span.Status())
+	}
+	attributes := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+	if attributes["http.response.status_code"].AsInt64() != http.StatusNotFound || attributes["http.request.resend_count"].AsInt64() != 1 {
+		t.Errorf("unexpected span attributes: %v", span.Attributes())
+	}
+	if !strings.Contains(traceparent, span.SpanContext().TraceID().String()) {
+		t.Errorf("expected the trace context to be sent to the backend, got %q", traceparent)
+	}
+}
+
+func TestClientGetMappingsByName(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+	openAPI := `{"paths": {"/mapping": {"get": {}}}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/openapi.json" {
+			w.Write([]byte(openAPI))
+			return
+		}
+		mu.Lock()
+		queries = append(queries, r.URL.RawQuery)
+		mu.Unlock()
+		if r.URL.Query().Get("name") == "teams" {
+			w.Write([]byte(`[{"id": 7, "name": "teams"}]`))
+			return
+		}
+		w.Write([]byte(`[{"id": 7, "name": "teams"}, {"id": 8, "name": "legacy"}, {"id": 9, "name": "teams"}]`))
+	}))
+	defer server.Close()
+
+	// backends not documenting the name filter share the cached list of all mappings
+	client := NewClient(server.URL, "key", 30*time.Second)
+	client.listCache = newListCache(defaultListCacheTTL)
+	if _, _, err := client.GetMappings(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	mappings, _, err := client.GetMappingsByName(context.Background(), "teams")
+	if err != nil || len(mappings) != 2 || mappings[0].ID != "7" || mappings[1].ID != "9" {
+		t.Errorf("expected the mappings to be filtered by name, got %+v, %v", mappings, err)
+	}
+	if len(queries) != 1 || strings.Contains(queries[0], "name=") {
+		t.Errorf("expected the cached list without name filter, got %v", queries)
+	}
+
+	openAPI = `{"paths": {"/mapping": {"get": {"parameters": [{"name": "name", "in": "query"}]}}}}`
+	queries = nil
+	client = NewClient(server.URL, "key", 30*time.Second)
+	mappings, _, err = client.GetMappingsByName(context.Background(), "teams")
+	if err != nil || len(mappings) != 1 || mappings[0].ID != "7" {
+		t.Fatalf("unexpected result: %+v, %v", mappings, err)
+	}
+	if len(queries) != 1 || !strings.Contains(queries[0], "name=teams") {
+		t.Errorf("expected the name to be sent as filter, got %v", queries)
+	}
+}
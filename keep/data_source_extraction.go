@@ -0,0 +1,110 @@
+package keep
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceExtraction() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceReadExtraction,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeInt,
+				Required:    true,
+				Description: "ID of the extraction",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Name of the extraction",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Description of the extraction",
+			},
+			"priority": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Priority of the extraction",
+			},
+			"attribute": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Attribute of the extraction",
+			},
+			"condition": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "CEL condition of the extraction",
+			},
+			"disabled": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the extraction is disabled",
+			},
+			"regex": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Regex of the extraction",
+			},
+			"enriched_attributes": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "Attributes the extraction adds to alerts, the named capture groups of the regex",
+			},
+			"pre": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Pre of the extraction",
+			},
+			"created_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Creation time of the extraction",
+			},
+			"created_by": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Creator of the extraction",
+			},
+			"updated_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Time of the last update of the extraction",
+			},
+			"updated_by": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "User who last updated the extraction",
+			},
+		},
+	}
+}
+
+func dataSourceReadExtraction(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(KeepClient)
+	id := strconv.Itoa(d.Get("id").(int))
+
+	extraction, errResp, err := getExtraction(ctx, client, id)
+	if err != nil {
+		if errResp != nil {
+			return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
+		}
+		return diag.Errorf("error reading extraction: %s", err)
+	}
+
+	if extraction == nil {
+		return attributeErrorf(cty.GetAttrPath("id"), "extraction with ID %s not found", id)
+	}
+
+	d.SetId(id)
+	return setExtractionState(d, extraction)
+}
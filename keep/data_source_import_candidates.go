@@ -0,0 +1,221 @@
+package keep
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// importCandidateTypes are the resource types whose objects can be listed as import candidates
+var importCandidateTypes = []string{"keep_extraction", "keep_mapping", "keep_provider", "keep_workflow"}
+
+// invalidAddressCharacters matches the characters which are not allowed in the names of resources
+var invalidAddressCharacters = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// importCandidate is an object of the backend which is not managed by terraform
+type importCandidate struct {
+	ResourceType string
+	ID           string
+	Name         string
+	Address      string
+}
+
+func dataSourceImportCandidates() *schema.Resource {
+	return &schema.Resource{
+		Description: "Lists the workflows, mappings, extractions and providers of the backend which are not managed by terraform " +
+			"with suggested resource addresses, to generate import blocks for adopting them.",
+		ReadContext: dataSourceReadImportCandidates,
+		Schema: map[string]*schema.Schema{
+			"managed_ids": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+				Description: "IDs of the objects which are already managed, e.g. `keep_workflow.example.id`. " +
+					"Ids of mappings and extractions are numbers which can be equal, prefix them with the resource type, " +
+					"e.g. `keep_mapping/3`, to only match objects of that type.",
+			},
+			"types": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(importCandidateTypes, false),
+				},
+				Set:         schema.HashString,
+				Description: "Resource types to list objects of, any of " + strings.Join(importCandidateTypes, ", ") + ". Lists all if not set.",
+			},
+			"candidates": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Unmanaged objects sorted by resource type and address",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"resource_type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Resource type to import the object as",
+						},
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID to import the object by",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the object",
+						},
+						"address": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Suggested address derived from the name, unique within the candidates",
+						},
+					},
+				},
+			},
+			"import_blocks": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Import blocks of all candidates, e.g. to write them to a file and run terraform plan -generate-config-out",
+			},
+		},
+	}
+}
+
+func dataSourceReadImportCandidates(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(KeepClient)
+
+	types := d.Get("types").(*schema.Set)
+	if types.Len() == 0 {
+		for _, resourceType := range importCandidateTypes {
+			types.Add(resourceType)
+		}
+	}
+
+	var candidates []importCandidate
+	for _, resourceType := range importCandidateTypes {
+		if !types.Contains(resourceType) {
+			continue
+		}
+		objects, err := listImportCandidates(ctx, client, resourceType)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		candidates = append(candidates, objects...)
+	}
+
+	managed := d.Get("managed_ids").(*schema.Set)
+	unmanaged := make([]importCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if !managed.Contains(candidate.ID) && !managed.Contains(candidate.ResourceType+"/"+candidate.ID) {
+			unmanaged = append(unmanaged, candidate)
+		}
+	}
+	unmanaged = addressImportCandidates(unmanaged)
+
+	values := make([]interface{}, len(unmanaged))
+	var blocks strings.Builder
+	for i, candidate := range unmanaged {
+		values[i] = map[string]interface{}{
+			"resource_type": candidate.ResourceType,
+			"id":            candidate.ID,
+			"name":          candidate.Name,
+			"address":       candidate.Address,
+		}
+		if i > 0 {
+			blocks.WriteString("\n")
+		}
+		fmt.Fprintf(&blocks, "import {\n  to = %s\n  id = %q\n}\n", candidate.Address, candidate.ID)
+	}
+
+	d.SetId("import_candidates")
+	return diag.FromErr(setAttributes(d, map[string]interface{}{
+		"candidates":    values,
+		"import_blocks": blocks.String(),
+	}))
+}
+
+// listImportCandidates returns the objects of the backend of the resource type
+func listImportCandidates(ctx context.Context, client KeepClient, resourceType string) ([]importCandidate, error) {
+	var candidates []importCandidate
+	var errResp *ErrorResponse
+	var err error
+
+	switch resourceType {
+	case "keep_extraction":
+		var extractions []Extraction
+		if extractions, errResp, err = client.GetExtractions(ctx); err == nil {
+			for _, extraction := range extractions {
+				candidates = append(candidates, importCandidate{ResourceType: resourceType, ID: string(extraction.ID), Name: extraction.Name})
+			}
+		}
+	case "keep_mapping":
+		var mappings []Mapping
+		if mappings, errResp, err = client.GetMappings(ctx); err == nil {
+			for _, mapping := range mappings {
+				candidates = append(candidates, importCandidate{ResourceType: resourceType, ID: string(mapping.ID), Name: mapping.Name})
+			}
+		}
+	case "keep_provider":
+		var providers []KeepProvider
+		if providers, errResp, err = client.GetInstalledProviders(ctx); err == nil {
+			for _, provider := range providers {
+				candidates = append(candidates, importCandidate{ResourceType: resourceType, ID: string(provider.ID), Name: provider.Details.Name})
+			}
+		}
+	case "keep_workflow":
+		var workflows []Workflow
+		if workflows, errResp, err = client.ListWorkflows(ctx); err == nil {
+			for _, workflow := range workflows {
+				candidates = append(candidates, importCandidate{ResourceType: resourceType, ID: string(workflow.ID), Name: workflow.Name})
+			}
+		}
+	}
+
+	if err != nil {
+		if errResp != nil {
+			return nil, fmt.Errorf("API Error listing %s: %s. Details: %s", resourceType, errResp.Error, errResp.Details)
+		}
+		return nil, fmt.Errorf("error listing %s: %s", resourceType, err)
+	}
+	return candidates, nil
+}
+
+// addressImportCandidates sets the addresses of the candidates to their names in snake case, candidates with
+// the same name get a numbered suffix. The candidates are sorted by resource type and address.
+func addressImportCandidates(candidates []importCandidate) []importCandidate {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].ResourceType != candidates[j].ResourceType {
+			return candidates[i].ResourceType < candidates[j].ResourceType
+		}
+		if candidates[i].Name != candidates[j].Name {
+			return candidates[i].Name < candidates[j].Name
+		}
+		return candidates[i].ID < candidates[j].ID
+	})
+
+	used := make(map[string]bool, len(candidates))
+	for i, candidate := range candidates {
+		name := strings.Trim(invalidAddressCharacters.ReplaceAllString(strings.ToLower(candidate.Name), "_"), "_")
+		// names of resources have to start with a letter or underscore
+		if name == "" || (name[0] >= '0' && name[0] <= '9') {
+			name = strings.TrimPrefix(candidate.ResourceType, "keep_") + "_" + name
+		}
+		name = strings.TrimSuffix(name, "_")
+
+		address := candidate.ResourceType + "." + name
+		for suffix := 2; used[address]; suffix++ {
+			address = fmt.Sprintf("%s.%s_%d", candidate.ResourceType, name, suffix)
+		}
+		used[address] = true
+		candidates[i].Address = address
+	}
+	return candidates
+}
@@ -0,0 +1,48 @@
+package keep
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceImportCandidates(t *testing.T) {
+	backend := newMockBackend(t)
+	backend.workflows["wf-1"] = Workflow{ID: "wf-1", Name: "Alert Router"}
+	backend.workflows["wf-2"] = Workflow{ID: "wf-2", Name: "alert-router"}
+	backend.workflows["wf-3"] = Workflow{ID: "wf-3", Name: "managed"}
+	backend.mappings["3"] = Mapping{ID: "3", Name: "2024 teams"}
+	backend.extractions["3"] = Extraction{ID: "3", Name: "service"}
+	backend.providers["provider-id"] = KeepProvider{ID: "provider-id", Type: "test", Details: ProviderDetails{Name: "grafana"}}
+
+	d := schema.TestResourceDataRaw(t, dataSourceImportCandidates().Schema, map[string]interface{}{
+		"managed_ids": []interface{}{"wf-3", "keep_extraction/3"},
+	})
+	if diags := dataSourceReadImportCandidates(context.Background(), d, backend.client()); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	var addresses []string
+	for _, candidate := range d.Get("candidates").([]interface{}) {
+		addresses = append(addresses, candidate.(map[string]interface{})["address"].(string))
+	}
+	expected := "keep_mapping.mapping_2024_teams,keep_provider.grafana,keep_workflow.alert_router,keep_workflow.alert_router_2"
+	if strings.Join(addresses, ",") != expected {
+		t.Errorf("expected the addresses %s, got %v", expected, addresses)
+	}
+	if blocks := d.Get("import_blocks").(string); !strings.Contains(blocks, "import {\n  to = keep_workflow.alert_router_2\n  id = \"wf-2\"\n}\n") {
+		t.Errorf("unexpected import blocks:\n%s", blocks)
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourceImportCandidates().Schema, map[string]interface{}{
+		"types": []interface{}{"keep_extraction"},
+	})
+	if diags := dataSourceReadImportCandidates(context.Background(), d, backend.client()); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if d.Get("candidates.#") != 1 || d.Get("candidates.0.address") != "keep_extraction.service" {
+		t.Errorf("expected only the extraction, got %v", d.Get("candidates"))
+	}
+}
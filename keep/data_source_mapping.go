@@ -2,22 +2,12 @@ package keep
 
 import (
 	"context"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"strconv"
 )
 
-type Mapping struct {
-	ID          int      `json:"id"`
-	Name        string   `json:"name"`
-	Description string   `json:"description"`
-	FileName    string   `json:"file_name"`
-	Matchers    []string `json:"matchers"`
-	Attributes  []string `json:"attributes"`
-	CreatedAt   string   `json:"created_at"`
-	CreatedBy   string   `json:"created_by"`
-}
-
 func dataSourceMapping() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceReadMapping,
@@ -69,10 +59,10 @@ func dataSourceMapping() *schema.Resource {
 }
 
 func dataSourceReadMapping(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Client)
+	client := m.(KeepClient)
 	id := d.Get("id").(int)
 
-	mappings, errResp, err := client.GetMappings()
+	mapping, errResp, err := getMapping(ctx, client, strconv.Itoa(id))
 	if err != nil {
 		if errResp != nil {
 			return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
@@ -80,21 +70,23 @@ func dataSourceReadMapping(ctx context.Context, d *schema.ResourceData, m interf
 		return diag.Errorf("error reading mappings: %s", err)
 	}
 
-	for _, m := range mappings {
-		mapping := m.(map[string]interface{})
-		if int(mapping["id"].(float64)) == id {
-			d.SetId(strconv.Itoa(id))
-			d.Set("id", strconv.Itoa(id))
-			d.Set("name", mapping["name"])
-			d.Set("description", mapping["description"])
-			d.Set("file_name", mapping["file_name"])
-			d.Set("matchers", mapping["matchers"])
-			d.Set("attributes", mapping["attributes"])
-			d.Set("created_at", mapping["created_at"])
-			d.Set("created_by", mapping["created_by"])
-			return nil
-		}
+	if mapping == nil {
+		return attributeErrorf(cty.GetAttrPath("id"), "mapping with ID %d not found", id)
+	}
+
+	matchers := make([]string, len(mapping.Matchers))
+	for i, matcher := range mapping.Matchers {
+		matchers[i] = matcher.String()
 	}
 
-	return diag.Errorf("mapping with ID %d not found", id)
+	d.SetId(strconv.Itoa(id))
+	return diag.FromErr(setAttributes(d, map[string]interface{}{
+		"name":        mapping.Name,
+		"description": mapping.Description,
+		"file_name":   mapping.FileName,
+		"matchers":    matchers,
+		"attributes":  mapping.Attributes,
+		"created_at":  mapping.CreatedAt,
+		"created_by":  mapping.CreatedBy,
+	}))
 }
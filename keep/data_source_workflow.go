@@ -2,6 +2,8 @@ package keep
 
 import (
 	"context"
+
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -92,11 +94,14 @@ func dataSourceWorkflows() *schema.Resource {
 }
 
 func dataSourceReadWorkflow(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Client)
+	client := m.(KeepClient)
 	id := d.Get("id").(string)
 
-	response, errResp, err := client.GetWorkflow(id)
+	response, errResp, err := client.GetWorkflow(ctx, id)
 	if err != nil {
+		if isNotFound(err) {
+			return attributeErrorf(cty.GetAttrPath("id"), "workflow with ID %s not found", id)
+		}
 		if errResp != nil {
 			return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
 		}
@@ -104,20 +109,20 @@ func dataSourceReadWorkflow(ctx context.Context, d *schema.ResourceData, m inter
 	}
 
 	d.SetId(id)
-	d.Set("name", response["name"])
-	d.Set("description", response["description"])
-	d.Set("created_by", response["created_by"])
-	d.Set("creation_time", response["creation_time"])
-	d.Set("triggers", response["triggers"])
-	d.Set("interval", response["interval"])
-	d.Set("last_execution_time", response["last_execution_time"])
-	d.Set("last_execution_status", response["last_execution_status"])
-	d.Set("keep_providers", response["providers"])
-	d.Set("workflow_raw_id", response["workflow_raw_id"])
-	d.Set("workflow_raw", response["workflow_raw"])
-	d.Set("revision", response["revision"])
-	d.Set("last_updated", response["last_updated"])
-	d.Set("invalid", response["invalid"])
-
-	return nil
+	return diag.FromErr(setAttributes(d, map[string]interface{}{
+		"name":                  response.Name,
+		"description":           response.Description,
+		"created_by":            response.CreatedBy,
+		"creation_time":         response.CreationTime,
+		"triggers":              string(response.Triggers),
+		"interval":              response.Interval,
+		"last_execution_time":   response.LastExecutionTime,
+		"last_execution_status": response.LastExecutionStatus,
+		"keep_providers":        string(response.Providers),
+		"workflow_raw_id":       response.WorkflowRawID,
+		"workflow_raw":          response.WorkflowRaw,
+		"revision":              response.Revision,
+		"last_updated":          response.LastUpdated,
+		"invalid":               response.Invalid,
+	}))
 }
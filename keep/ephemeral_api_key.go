@@ -0,0 +1,194 @@
+package keep
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	defaultAPIKeyNamePrefix = "terraform"
+	defaultAPIKeyRole       = "webhook"
+	// apiKeyPrivateKey is the key of the private data of an opened keep_api_key
+	apiKeyPrivateKey = "api_key"
+)
+
+// apiKeyRoles are the roles predefined by the backend
+var apiKeyRoles = []string{"admin", "noc", "webhook", "workflowrunner"}
+
+// apiKeyNamePattern matches names the backend keeps unchanged, it removes spaces from names
+var apiKeyNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// apiKeyPrivate is the private data of an opened keep_api_key, which Close needs to delete the key
+type apiKeyPrivate struct {
+	// KeyID is the reference id the backend returned for the key, keys are deleted by it
+	KeyID string `json:"key_id"`
+	Name  string `json:"name"`
+}
+
+// apiKeyModel is the configuration and the result of keep_api_key
+type apiKeyModel struct {
+	Name   types.String `tfsdk:"name"`
+	Role   types.String `tfsdk:"role"`
+	Secret types.String `tfsdk:"secret"`
+}
+
+// apiKeyEphemeralResource creates an api key when opened and deletes it when closed
+type apiKeyEphemeralResource struct {
+	client KeepClient
+}
+
+var (
+	_ ephemeral.EphemeralResourceWithConfigure      = &apiKeyEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithClose          = &apiKeyEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithValidateConfig = &apiKeyEphemeralResource{}
+)
+
+func newAPIKeyEphemeralResource() ephemeral.EphemeralResource {
+	return &apiKeyEphemeralResource{}
+}
+
+func (r *apiKeyEphemeralResource) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_api_key"
+}
+
+func (r *apiKeyEphemeralResource) Schema(_ context.Context, _ ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Creates an api key, which is available during the run, e.g. to configure webhook senders, and deleted at its end. " +
+			"The secret is never stored in the plan or state. An api key of a run which was interrupted before its end has to be deleted in the settings of Keep.",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Name of the api key, which has to be unique within the tenant. Defaults to terraform-<random suffix>.",
+			},
+			"role": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Role of the api key, one of " + strings.Join(apiKeyRoles, ", ") + ". Default is webhook.",
+			},
+			"secret": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Secret of the api key, sent as X-API-Key header",
+			},
+		},
+	}
+}
+
+func (r *apiKeyEphemeralResource) Configure(_ context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	// the provider data is nil until the provider is configured, e.g. during validation
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(KeepClient)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider data", fmt.Sprintf("Expected the client of the provider, got %T", req.ProviderData))
+		return
+	}
+	r.client = client
+}
+
+func (r *apiKeyEphemeralResource) ValidateConfig(ctx context.Context, req ephemeral.ValidateConfigRequest, resp *ephemeral.ValidateConfigResponse) {
+	var config apiKeyModel
+	if resp.Diagnostics.Append(req.Config.Get(ctx, &config)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	if name := config.Name; !name.IsNull() && !name.IsUnknown() && !apiKeyNamePattern.MatchString(name.ValueString()) {
+		resp.Diagnostics.AddAttributeError(path.Root("name"), "Invalid api key name",
+			fmt.Sprintf("Name %q of the api key may only contain letters, digits, '_', '.' and '-'", name.ValueString()))
+	}
+	if role := config.Role; !role.IsNull() && !role.IsUnknown() && !isAPIKeyRole(role.ValueString()) {
+		resp.Diagnostics.AddAttributeError(path.Root("role"), "Invalid api key role",
+			fmt.Sprintf("Role %q of the api key must be one of %s", role.ValueString(), strings.Join(apiKeyRoles, ", ")))
+	}
+}
+
+func (r *apiKeyEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data apiKeyModel
+	if resp.Diagnostics.Append(req.Config.Get(ctx, &data)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.Name.IsNull() {
+		suffix := make([]byte, 4)
+		if _, err := rand.Read(suffix); err != nil {
+			resp.Diagnostics.AddError("Cannot generate the name of the api key", err.Error())
+			return
+		}
+		data.Name = types.StringValue(defaultAPIKeyNamePrefix + "-" + hex.EncodeToString(suffix))
+	}
+	if data.Role.IsNull() {
+		data.Role = types.StringValue(defaultAPIKeyRole)
+	}
+
+	apiKey, errResp, err := r.client.CreateAPIKey(ctx, data.Name.ValueString(), data.Role.ValueString())
+	if err != nil {
+		if errResp != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("API Error: %s", errResp.Error), errResp.Details)
+			return
+		}
+		resp.Diagnostics.AddError("error creating api key", err.Error())
+		return
+	}
+	if apiKey.ReferenceID == "" {
+		resp.Diagnostics.AddError("no id found in response for api key",
+			fmt.Sprintf("The api key %s cannot be deleted at the end of the run, delete it in the settings of Keep", data.Name.ValueString()))
+		return
+	}
+
+	private, err := json.Marshal(apiKeyPrivate{KeyID: apiKey.ReferenceID, Name: data.Name.ValueString()})
+	if err != nil {
+		resp.Diagnostics.AddError("Cannot encode the private data of the api key", err.Error())
+		return
+	}
+	if resp.Diagnostics.Append(resp.Private.SetKey(ctx, apiKeyPrivateKey, private)...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Secret = types.StringValue(apiKey.Secret)
+	resp.Diagnostics.Append(resp.Result.Set(ctx, data)...)
+}
+
+// Close deletes the api key, a key which is already gone is fine
+func (r *apiKeyEphemeralResource) Close(ctx context.Context, req ephemeral.CloseRequest, resp *ephemeral.CloseResponse) {
+	private, diags := req.Private.GetKey(ctx, apiKeyPrivateKey)
+	if resp.Diagnostics.Append(diags...); resp.Diagnostics.HasError() {
+		return
+	}
+
+	var data apiKeyPrivate
+	if err := json.Unmarshal(private, &data); err != nil {
+		resp.Diagnostics.AddError("Cannot decode the private data of the api key", err.Error())
+		return
+	}
+
+	errResp, err := r.client.DeleteAPIKey(ctx, data.KeyID)
+	if err != nil && !isNotFound(err) {
+		if errResp != nil {
+			resp.Diagnostics.AddError(fmt.Sprintf("API Error deleting api key %s: %s", data.Name, errResp.Error), errResp.Details)
+			return
+		}
+		resp.Diagnostics.AddError(fmt.Sprintf("error deleting api key %s", data.Name), err.Error())
+	}
+}
+
+func isAPIKeyRole(role string) bool {
+	for _, r := range apiKeyRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
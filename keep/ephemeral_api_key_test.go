@@ -0,0 +1,162 @@
+package keep
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// apiKeyConfig returns the configuration of keep_api_key with the attributes, all others are null
+func apiKeyConfig(t *testing.T, schemaResp *tfprotov6.GetProviderSchemaResponse, attributes map[string]tftypes.Value) *tfprotov6.DynamicValue {
+	t.Helper()
+
+	objectType := schemaResp.EphemeralResourceSchemas["keep_api_key"].ValueType()
+	values := map[string]tftypes.Value{
+		"name":   tftypes.NewValue(tftypes.String, nil),
+		"role":   tftypes.NewValue(tftypes.String, nil),
+		"secret": tftypes.NewValue(tftypes.String, nil),
+	}
+	for name, value := range attributes {
+		values[name] = value
+	}
+
+	config, err := tfprotov6.NewDynamicValue(objectType, tftypes.NewValue(objectType, values))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &config
+}
+
+func TestEphemeralAPIKey(t *testing.T) {
+	b := newMockBackend(t)
+	server, schemaResp := newConfiguredProviderServer(t, b.URL)
+	ctx := context.Background()
+
+	if schemaResp.EphemeralResourceSchemas["keep_api_key"] == nil {
+		t.Fatal("expected the schema of keep_api_key")
+	}
+
+	openResp, err := server.OpenEphemeralResource(ctx, &tfprotov6.OpenEphemeralResourceRequest{
+		TypeName: "keep_api_key",
+		Config:   apiKeyConfig(t, schemaResp, map[string]tftypes.Value{"name": tftypes.NewValue(tftypes.String, "alertmanager")}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(openResp.Diagnostics) > 0 {
+		t.Fatalf("unexpected diagnostics: %s: %s", openResp.Diagnostics[0].Summary, openResp.Diagnostics[0].Detail)
+	}
+
+	result, err := openResp.Result.Unmarshal(schemaResp.EphemeralResourceSchemas["keep_api_key"].ValueType())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var attributes map[string]tftypes.Value
+	if err := result.As(&attributes); err != nil {
+		t.Fatal(err)
+	}
+	keyID := b.apiKeyIDs["alertmanager"]
+	for name, expected := range map[string]string{"name": "alertmanager", "role": "webhook", "secret": b.apiKeys[keyID].Secret} {
+		var value string
+		if err := attributes[name].As(&value); err != nil || value != expected {
+			t.Errorf("expected %s %q, got %q", name, expected, value)
+		}
+	}
+
+	closeResp, err := server.CloseEphemeralResource(ctx, &tfprotov6.CloseEphemeralResourceRequest{
+		TypeName: "keep_api_key",
+		Private:  openResp.Private,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(closeResp.Diagnostics) > 0 {
+		t.Fatalf("unexpected diagnostics: %s: %s", closeResp.Diagnostics[0].Summary, closeResp.Diagnostics[0].Detail)
+	}
+	if count := b.requestCount("DELETE", "/settings/apikey/"+keyID); count != 1 {
+		t.Errorf("expected the api key to be deleted by its id %s, got %d requests", keyID, count)
+	}
+	if len(b.apiKeys) != 0 {
+		t.Errorf("expected the api key to be deleted when closed, got %v", b.apiKeys)
+	}
+
+	// a key which was deleted in the meantime is fine, other failures are errors
+	closeResp, err = server.CloseEphemeralResource(ctx, &tfprotov6.CloseEphemeralResourceRequest{
+		TypeName: "keep_api_key",
+		Private:  openResp.Private,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(closeResp.Diagnostics) > 0 {
+		t.Errorf("expected a deleted api key to be ignored, got %s: %s", closeResp.Diagnostics[0].Summary, closeResp.Diagnostics[0].Detail)
+	}
+}
+
+func TestEphemeralAPIKey_CloseFails(t *testing.T) {
+	b := newMockBackend(t, "DELETE /settings/apikey/{keyId}")
+	server, schemaResp := newConfiguredProviderServer(t, b.URL)
+	ctx := context.Background()
+
+	openResp, err := server.OpenEphemeralResource(ctx, &tfprotov6.OpenEphemeralResourceRequest{
+		TypeName: "keep_api_key",
+		Config:   apiKeyConfig(t, schemaResp, nil),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(openResp.Diagnostics) > 0 {
+		t.Fatalf("unexpected diagnostics: %s: %s", openResp.Diagnostics[0].Summary, openResp.Diagnostics[0].Detail)
+	}
+
+	closeResp, err := server.CloseEphemeralResource(ctx, &tfprotov6.CloseEphemeralResourceRequest{
+		TypeName: "keep_api_key",
+		Private:  openResp.Private,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(closeResp.Diagnostics) != 1 || closeResp.Diagnostics[0].Severity != tfprotov6.DiagnosticSeverityError {
+		t.Errorf("expected an error when the api key cannot be deleted, got %v", closeResp.Diagnostics)
+	}
+}
+
+func TestEphemeralAPIKey_Validate(t *testing.T) {
+	factory, err := NewProviderServer(context.Background(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := factory()
+	schemaResp, err := server.GetProviderSchema(context.Background(), &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name       string
+		attributes map[string]tftypes.Value
+		errors     int
+	}{
+		{name: "defaults", attributes: nil},
+		{name: "unknown", attributes: map[string]tftypes.Value{"name": tftypes.NewValue(tftypes.String, tftypes.UnknownValue)}},
+		{name: "invalid name", attributes: map[string]tftypes.Value{"name": tftypes.NewValue(tftypes.String, "alert manager")}, errors: 1},
+		{name: "invalid role", attributes: map[string]tftypes.Value{"role": tftypes.NewValue(tftypes.String, "owner")}, errors: 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := server.ValidateEphemeralResourceConfig(context.Background(), &tfprotov6.ValidateEphemeralResourceConfigRequest{
+				TypeName: "keep_api_key",
+				Config:   apiKeyConfig(t, schemaResp, tc.attributes),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Diagnostics) != tc.errors {
+				t.Errorf("expected %d errors, got %d", tc.errors, len(resp.Diagnostics))
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package keep
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// csvToRowsFunction converts CSV content to a list of rows, called as provider::keep::csv_to_rows(content, delimiter)
+type csvToRowsFunction struct{}
+
+var _ function.Function = &csvToRowsFunction{}
+
+func newCSVToRowsFunction() function.Function {
+	return &csvToRowsFunction{}
+}
+
+func (f *csvToRowsFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "csv_to_rows"
+}
+
+func (f *csvToRowsFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Convert CSV content to rows",
+		Description: "Parses CSV content with a header row like keep_mapping and returns a map of column to value for every other row.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "content",
+				Description: "CSV content, the first row contains the column names",
+			},
+			function.StringParameter{
+				Name:        "delimiter",
+				Description: "Single character separating the columns, e.g. \",\" or \";\"",
+			},
+		},
+		Return: function.ListReturn{ElementType: types.MapType{ElemType: types.StringType}},
+	}
+}
+
+func (f *csvToRowsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var content, delimiter string
+	if resp.Error = req.Arguments.Get(ctx, &content, &delimiter); resp.Error != nil {
+		return
+	}
+
+	comma, size := utf8.DecodeRuneInString(delimiter)
+	if size == 0 || size != len(delimiter) || comma == '"' || comma == '\r' || comma == '\n' || comma == utf8.RuneError {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("delimiter must be a single character other than a quote or line break, got %q", delimiter))
+		return
+	}
+
+	rows, err := parseCSVRows(strings.NewReader(content), comma)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("invalid CSV: %s", err))
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, rows)
+}
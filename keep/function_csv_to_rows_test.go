@@ -0,0 +1,69 @@
+package keep
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestFunctionCSVToRows(t *testing.T) {
+	result, funcErr := callFunction(t, "csv_to_rows",
+		tftypes.NewValue(tftypes.String, "service;team\napi;platform\n\"web;app\";frontend\n"),
+		tftypes.NewValue(tftypes.String, ";"),
+	)
+	if funcErr != nil {
+		t.Fatalf("unexpected error: %s", funcErr.Text)
+	}
+
+	var values []tftypes.Value
+	if err := result.As(&values); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := make([]map[string]string, len(values))
+	for i, value := range values {
+		var columns map[string]tftypes.Value
+		value.As(&columns)
+		rows[i] = map[string]string{}
+		for column, cell := range columns {
+			var str string
+			cell.As(&str)
+			rows[i][column] = str
+		}
+	}
+
+	expected := []map[string]string{
+		{"service": "api", "team": "platform"},
+		{"service": "web;app", "team": "frontend"},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("expected rows %v, got %v", expected, rows)
+	}
+}
+
+func TestFunctionCSVToRows_Invalid(t *testing.T) {
+	cases := map[string]struct {
+		content          string
+		delimiter        string
+		expectedArgument int64
+	}{
+		"empty content":       {content: "", delimiter: ",", expectedArgument: 0},
+		"inconsistent fields": {content: "a,b\n1,2,3", delimiter: ",", expectedArgument: 0},
+		"empty delimiter":     {content: "a,b", delimiter: "", expectedArgument: 1},
+		"long delimiter":      {content: "a,b", delimiter: ",,", expectedArgument: 1},
+		"quote delimiter":     {content: "a,b", delimiter: "\"", expectedArgument: 1},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, funcErr := callFunction(t, "csv_to_rows", tftypes.NewValue(tftypes.String, tc.content), tftypes.NewValue(tftypes.String, tc.delimiter))
+			if funcErr == nil {
+				t.Fatal("expected an error")
+			}
+			if funcErr.FunctionArgument == nil || *funcErr.FunctionArgument != tc.expectedArgument {
+				t.Errorf("expected the error to point to argument %d, got %v", tc.expectedArgument, funcErr.FunctionArgument)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package keep
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"gopkg.in/yaml.v2"
+)
+
+// parseWorkflowFunction parses and validates a workflow, called as provider::keep::parse_workflow(yaml)
+type parseWorkflowFunction struct{}
+
+var _ function.Function = &parseWorkflowFunction{}
+
+func newParseWorkflowFunction() function.Function {
+	return &parseWorkflowFunction{}
+}
+
+func (f *parseWorkflowFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "parse_workflow"
+}
+
+func (f *parseWorkflowFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Parse and validate a workflow",
+		Description: "Parses the YAML of a workflow like keep_workflow and returns its id, name, description and the types of its triggers, e.g. to check naming conventions at plan time.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "yaml",
+				Description: "YAML of the workflow",
+			},
+		},
+		Return: function.ObjectReturn{AttributeTypes: map[string]attr.Type{
+			"id":          types.StringType,
+			"name":        types.StringType,
+			"description": types.StringType,
+			"triggers":    types.ListType{ElemType: types.StringType},
+		}},
+	}
+}
+
+func (f *parseWorkflowFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var content string
+	if resp.Error = req.Arguments.Get(ctx, &content); resp.Error != nil {
+		return
+	}
+
+	workflow, err := parseWorkflow(content)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	result := struct {
+		ID          string   `tfsdk:"id"`
+		Name        string   `tfsdk:"name"`
+		Description string   `tfsdk:"description"`
+		Triggers    []string `tfsdk:"triggers"`
+	}{
+		ID:          workflow.ID,
+		Name:        workflow.Name,
+		Description: workflow.Description,
+		Triggers:    make([]string, len(workflow.Triggers)),
+	}
+	for i, trigger := range workflow.Triggers {
+		result.Triggers[i] = trigger.Type
+	}
+
+	resp.Error = resp.Result.Set(ctx, result)
+}
+
+// parsedWorkflow are the attributes of a workflow returned by parse_workflow
+type parsedWorkflow struct {
+	ID          string `yaml:"id"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Triggers    []struct {
+		Type string `yaml:"type"`
+	} `yaml:"triggers"`
+}
+
+// parseWorkflow parses the YAML of a workflow and validates it like keep_workflow
+func parseWorkflow(content string) (*parsedWorkflow, error) {
+	var workflowWrapper struct {
+		Workflow *parsedWorkflow `yaml:"workflow"`
+	}
+
+	if err := yaml.Unmarshal([]byte(content), &workflowWrapper); err != nil {
+		return nil, fmt.Errorf("invalid workflow YAML: %s", err)
+	}
+
+	workflow := workflowWrapper.Workflow
+	if workflow == nil {
+		return nil, fmt.Errorf("invalid workflow structure")
+	}
+	if workflow.Name == "" {
+		return nil, fmt.Errorf("workflow name is required")
+	}
+	for i, trigger := range workflow.Triggers {
+		if trigger.Type == "" {
+			return nil, fmt.Errorf("type of trigger %d is required", i)
+		}
+	}
+
+	return workflow, nil
+}
@@ -0,0 +1,75 @@
+package keep
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestFunctionParseWorkflow(t *testing.T) {
+	content := `
+workflow:
+  id: notify-on-error
+  name: Notify on error
+  description: Sends errors to slack
+  triggers:
+    - type: alert
+      filters:
+        - key: severity
+          value: critical
+    - type: manual
+  actions:
+    - name: notify
+      provider:
+        type: slack
+`
+	result, funcErr := callFunction(t, "parse_workflow", tftypes.NewValue(tftypes.String, content))
+	if funcErr != nil {
+		t.Fatalf("unexpected error: %s", funcErr.Text)
+	}
+
+	var attributes map[string]tftypes.Value
+	if err := result.As(&attributes); err != nil {
+		t.Fatal(err)
+	}
+
+	var id, name, description string
+	attributes["id"].As(&id)
+	attributes["name"].As(&name)
+	attributes["description"].As(&description)
+	if id != "notify-on-error" || name != "Notify on error" || description != "Sends errors to slack" {
+		t.Errorf("unexpected workflow attributes: %s, %s, %s", id, name, description)
+	}
+
+	var triggers []tftypes.Value
+	attributes["triggers"].As(&triggers)
+	types := make([]string, len(triggers))
+	for i, trigger := range triggers {
+		trigger.As(&types[i])
+	}
+	if strings.Join(types, ",") != "alert,manual" {
+		t.Errorf("unexpected triggers: %v", types)
+	}
+}
+
+func TestFunctionParseWorkflow_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"invalid YAML":         "workflow: [",
+		"missing workflow":     "name: test",
+		"missing name":         "workflow:\n  description: test",
+		"missing trigger type": "workflow:\n  name: test\n  triggers:\n    - value: 60",
+	}
+
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, funcErr := callFunction(t, "parse_workflow", tftypes.NewValue(tftypes.String, content))
+			if funcErr == nil {
+				t.Fatal("expected an error")
+			}
+			if funcErr.FunctionArgument == nil || *funcErr.FunctionArgument != 0 {
+				t.Errorf("expected the error to point to the yaml argument, got %v", funcErr.FunctionArgument)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package keep
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// validateCELFunction fails for invalid CEL expressions, called as provider::keep::validate_cel(expr)
+type validateCELFunction struct{}
+
+var _ function.Function = &validateCELFunction{}
+
+func newValidateCELFunction() function.Function {
+	return &validateCELFunction{}
+}
+
+func (f *validateCELFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "validate_cel"
+}
+
+func (f *validateCELFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Validate a CEL expression",
+		Description: "Compiles a CEL expression like the condition of keep_extraction and returns it unchanged, so preset queries, matchers and extraction conditions fail at plan time. The expression is type-checked against the fields of alerts and has to evaluate to a boolean, other fields are dynamic since alerts carry custom and enriched fields.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "expr",
+				Description: "CEL expression to validate",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *validateCELFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var expr string
+	if resp.Error = req.Arguments.Get(ctx, &expr); resp.Error != nil {
+		return
+	}
+
+	if err := checkCELCondition(expr); err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("%q is not a valid CEL expression: %s", expr, err))
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, expr)
+}
@@ -0,0 +1,42 @@
+package keep
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestFunctionValidateCEL(t *testing.T) {
+	cases := map[string]struct {
+		expr        string
+		expectError bool
+	}{
+		"valid":          {expr: `severity == "critical" && source.contains("grafana")`},
+		"unknown fields": {expr: `labels.team == "platform"`},
+		"invalid":        {expr: `severity == `, expectError: true},
+		"unbalanced":     {expr: `(severity == "critical"`, expectError: true},
+		"type error":     {expr: `firingCounter == "many"`, expectError: true},
+		"not a boolean":  {expr: `severity`, expectError: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			result, funcErr := callFunction(t, "validate_cel", tftypes.NewValue(tftypes.String, tc.expr))
+			if tc.expectError {
+				if funcErr == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if funcErr != nil {
+				t.Fatalf("unexpected error: %s", funcErr.Text)
+			}
+
+			var expr string
+			result.As(&expr)
+			if expr != tc.expr {
+				t.Errorf("expected the expression to be returned unchanged, got %q", expr)
+			}
+		})
+	}
+}
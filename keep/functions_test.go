@@ -0,0 +1,79 @@
+package keep
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// callFunction calls a provider defined function through the provider server like terraform does
+func callFunction(t *testing.T, name string, args ...tftypes.Value) (tftypes.Value, *tfprotov6.FunctionError) {
+	t.Helper()
+	ctx := context.Background()
+
+	factory, err := NewProviderServer(ctx, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := factory()
+
+	// terraform reads the schema first, which routes the functions to the servers of the mux
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	definition, ok := schemaResp.Functions[name]
+	if !ok {
+		return tftypes.Value{}, &tfprotov6.FunctionError{Text: "unknown function " + name}
+	}
+
+	arguments := make([]*tfprotov6.DynamicValue, len(args))
+	for i, arg := range args {
+		value, err := tfprotov6.NewDynamicValue(arg.Type(), arg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		arguments[i] = &value
+	}
+
+	resp, err := server.CallFunction(ctx, &tfprotov6.CallFunctionRequest{
+		Name:      name,
+		Arguments: arguments,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error != nil {
+		return tftypes.Value{}, resp.Error
+	}
+
+	result, err := resp.Result.Unmarshal(definition.Return.Type)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result, nil
+}
+
+func TestProviderFunctions(t *testing.T) {
+	ctx := context.Background()
+	factory, err := NewProviderServer(ctx, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemaResp, err := factory().GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"csv_to_rows", "parse_workflow", "validate_cel"} {
+		if schemaResp.Functions[name] == nil {
+			t.Errorf("expected function %s", name)
+		}
+	}
+
+	if _, funcErr := callFunction(t, "csv_to_rows", tftypes.NewValue(tftypes.String, "a,b\n1,2\n")); funcErr == nil {
+		t.Error("expected an error for a missing argument")
+	}
+}
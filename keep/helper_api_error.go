@@ -0,0 +1,190 @@
+package keep
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/go-uuid"
+)
+
+// requestIDHeader is the header correlating requests of the provider with the logs of the backend
+const requestIDHeader = "X-Request-Id"
+
+// Errors of the client are classified by these sentinel errors, which can be checked with errors.Is
+var (
+	// ErrNotFound is returned if the requested object doesn't exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned if the object conflicts with an existing one, e.g. a provider with the same name
+	ErrConflict = errors.New("conflict")
+	// ErrUnauthorized is returned if the credentials are rejected or lack permissions, retrying doesn't help
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrTransient is returned for failures which usually succeed when retried, e.g. rate limits, unavailable
+	// backends or gateways timing out, and requests failing without a response
+	ErrTransient = errors.New("transient failure")
+)
+
+// APIError is returned by the client when the Keep API answers a request with an unsuccessful status code.
+// Wrapped errors of client methods can be inspected with errors.As or the helpers below.
+type APIError struct {
+	StatusCode int
+	Method     string
+	// Endpoint is the path of the request without query
+	Endpoint  string
+	RequestID string
+	// Response is the parsed error of the response body, with the raw body as details if it couldn't be parsed
+	Response ErrorResponse
+	// RetryAfter is the wait requested by the Retry-After header of the response, 0 if it isn't set
+	RetryAfter time.Duration
+
+	message string
+}
+
+func (e *APIError) Error() string {
+	return e.message
+}
+
+// Is classifies the error by its status code as one of the sentinel errors
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrTransient:
+		switch e.StatusCode {
+		case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+	}
+	return false
+}
+
+// transportError is returned if a request fails without a response
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
+
+// Is classifies the failure as transient, unless retrying can't help, e.g. for untrusted certificates,
+// or the request was canceled
+func (e *transportError) Is(target error) bool {
+	return target == ErrTransient && !errors.Is(e.err, context.Canceled) && !isPermanentTransportError(e.err)
+}
+
+// newAPIError parses the error response of a failed request
+func newAPIError(req *http.Request, resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Method:     req.Method,
+		Endpoint:   req.URL.Path,
+		RequestID:  responseRequestID(req, resp),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+
+	if isScopeError, scopeDetails := isScopesError(body); isScopeError {
+		apiErr.Response = ErrorResponse{Error: "Insufficient permissions", Details: scopeDetails}
+		apiErr.message = "API request failed: insufficient permissions"
+		return apiErr
+	}
+
+	var errResp ErrorResponse
+	if err := json.Unmarshal(body, &errResp); err == nil && (errResp.Error != "" || errResp.Details != "") {
+		apiErr.Response = errResp
+		apiErr.message = fmt.Sprintf("API request failed with status %d", resp.StatusCode)
+		return apiErr
+	}
+
+	// FastAPI reports errors as detail
+	var detailResp struct {
+		Detail string `json:"detail"`
+	}
+	if err := json.Unmarshal(body, &detailResp); err == nil && detailResp.Detail != "" {
+		apiErr.Response = ErrorResponse{Error: fmt.Sprintf("request failed with status %d", resp.StatusCode), Details: detailResp.Detail}
+		apiErr.message = fmt.Sprintf("API request failed with status %d: %s", resp.StatusCode, detailResp.Detail)
+		return apiErr
+	}
+
+	apiErr.Response = ErrorResponse{Error: fmt.Sprintf("request failed with status %d", resp.StatusCode), Details: string(body)}
+	apiErr.message = fmt.Sprintf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	return apiErr
+}
+
+// parseRetryAfter returns the wait of a Retry-After header in seconds or as HTTP date, 0 if it is missing or invalid
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// newRequestID returns a random id to correlate a request with the logs of the backend
+func newRequestID() string {
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return fmt.Sprintf("tf-%d", time.Now().UnixNano())
+	}
+	return id
+}
+
+// responseRequestID returns the request id of the response, backends behind proxies may replace the id of the request
+func responseRequestID(req *http.Request, resp *http.Response) string {
+	if resp != nil {
+		if requestID := resp.Header.Get(requestIDHeader); requestID != "" {
+			return requestID
+		}
+	}
+	return req.Header.Get(requestIDHeader)
+}
+
+// apiStatusCode returns the status code of a failed API request, 0 if err isn't an API error
+func apiStatusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// hasStatus reports whether err is an API error with one of the status codes
+func hasStatus(err error, statusCodes ...int) bool {
+	statusCode := apiStatusCode(err)
+	for _, code := range statusCodes {
+		if statusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// isNotFound reports whether the requested object doesn't exist
+func isNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// isServerError reports whether the backend failed to process a request
+func isServerError(err error) bool {
+	return apiStatusCode(err) >= 500
+}
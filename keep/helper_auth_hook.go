@@ -0,0 +1,220 @@
+package keep
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Ensure execAuthSource implements credentialSource interface
+var _ credentialSource = &execAuthSource{}
+
+// execAuthOutput is the output of an auth command. Commands may print a plain token instead.
+type execAuthOutput struct {
+	Token     string            `json:"token"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	Headers   map[string]string `json:"headers"`
+}
+
+// execAuthSource runs a command which prints a token and headers to authenticate with, e.g. gcloud for
+// identity-aware proxy tokens or az for Azure AD tokens. The output is cached until it expires, unless
+// the command runs for every request, e.g. to sign requests with AWS SigV4.
+type execAuthSource struct {
+	Command    string
+	Args       []string
+	Env        map[string]string
+	PerRequest bool
+
+	mu     sync.Mutex
+	output *execAuthOutput
+}
+
+// Token returns the token printed by the command
+func (s *execAuthSource) Token(ctx context.Context) (string, error) {
+	output, err := s.Output(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	return output.Token, nil
+}
+
+// Invalidate drops the cached output, so the command runs again for the next request
+func (s *execAuthSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.output = nil
+}
+
+// Output returns the cached output or runs the command. Commands running for every request
+// get the method, url and body hash of req in the KEEP_REQUEST_* environment variables.
+func (s *execAuthSource) Output(ctx context.Context, req *http.Request) (*execAuthOutput, error) {
+	if s.PerRequest {
+		return s.run(ctx, req)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.output != nil && (s.output.ExpiresAt.IsZero() || time.Now().Before(s.output.ExpiresAt.Add(-oauth2TokenExpiryLeeway))) {
+		return s.output, nil
+	}
+
+	output, err := s.run(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.output = output
+	return s.output, nil
+}
+
+// run executes the command and parses its output
+func (s *execAuthSource) run(ctx context.Context, req *http.Request) (*execAuthOutput, error) {
+	cmd := exec.CommandContext(ctx, s.Command, s.Args...)
+	cmd.Env = os.Environ()
+	for key, value := range s.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	if req != nil {
+		bodyHash, err := requestBodyHash(req)
+		if err != nil {
+			return nil, err
+		}
+		cmd.Env = append(cmd.Env,
+			"KEEP_REQUEST_METHOD="+req.Method,
+			"KEEP_REQUEST_URL="+req.URL.String(),
+			"KEEP_REQUEST_BODY_SHA256="+bodyHash,
+		)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("auth command %s failed: %v: %s", s.Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	content := strings.TrimSpace(stdout.String())
+	if !strings.HasPrefix(content, "{") {
+		if content == "" {
+			return nil, fmt.Errorf("auth command %s printed no token", s.Command)
+		}
+		return &execAuthOutput{Token: content}, nil
+	}
+
+	var output execAuthOutput
+	if err := json.Unmarshal([]byte(content), &output); err != nil {
+		return nil, fmt.Errorf("failed to parse output of auth command %s: %v", s.Command, err)
+	}
+	return &output, nil
+}
+
+// requestBodyHash returns the hex encoded SHA-256 hash of the request body, which signatures like AWS SigV4 cover
+func requestBodyHash(req *http.Request) (string, error) {
+	hash := sha256.New()
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", fmt.Errorf("failed to read request body: %v", err)
+		}
+		defer body.Close()
+		if _, err := io.Copy(hash, body); err != nil {
+			return "", fmt.Errorf("failed to read request body: %v", err)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// authHeaderData is available in header templates
+type authHeaderData struct {
+	Token  string
+	Method string
+	URL    string
+}
+
+// authHook adds headers to every request for authentication in front of the backend, rendered from templates
+// and printed by an auth command, without replacing the credentials sent to the backend itself
+type authHook struct {
+	Exec            *execAuthSource
+	HeaderTemplates map[string]*template.Template
+
+	mu          sync.Mutex
+	headerNames map[string]bool
+}
+
+// newAuthHook parses the header templates, which can use the token of the auth command, the method and url
+// of the request and the env function to read environment variables
+func newAuthHook(source *execAuthSource, headerTemplates map[string]string) (*authHook, error) {
+	hook := &authHook{
+		Exec:            source,
+		HeaderTemplates: make(map[string]*template.Template, len(headerTemplates)),
+		headerNames:     make(map[string]bool),
+	}
+	for name, text := range headerTemplates {
+		tmpl, err := template.New(name).Option("missingkey=error").Funcs(template.FuncMap{"env": os.Getenv}).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template of header %s: %v", name, err)
+		}
+		hook.HeaderTemplates[name] = tmpl
+		hook.headerNames[http.CanonicalHeaderKey(name)] = true
+	}
+	return hook, nil
+}
+
+// Apply sets the headers of the templates and the auth command on the request
+func (h *authHook) Apply(req *http.Request) error {
+	data := authHeaderData{Method: req.Method, URL: req.URL.String()}
+	var headers map[string]string
+	if h.Exec != nil {
+		output, err := h.Exec.Output(req.Context(), req)
+		if err != nil {
+			return err
+		}
+		data.Token = output.Token
+		headers = output.Headers
+	}
+
+	for name, tmpl := range h.HeaderTemplates {
+		var value strings.Builder
+		if err := tmpl.Execute(&value, data); err != nil {
+			return fmt.Errorf("failed to render header %s: %v", name, err)
+		}
+		req.Header.Set(name, value.String())
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for name, value := range headers {
+		req.Header.Set(name, value)
+		h.headerNames[http.CanonicalHeaderKey(name)] = true
+	}
+	return nil
+}
+
+// Invalidate drops the cached output of the auth command after a request was rejected
+func (h *authHook) Invalidate() {
+	if h.Exec != nil {
+		h.Exec.Invalidate()
+	}
+}
+
+// HeaderNames returns the canonical names of the headers set by the hook, which are redacted in logs
+func (h *authHook) HeaderNames() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	names := make([]string, 0, len(h.headerNames))
+	for name := range h.headerNames {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,169 @@
+package keep
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// minimumKeepVersion is the version of the OpenAPI document the provider is built against, see openapi.json.
+// Features whose endpoints an older backend doesn't serve require at least this version.
+const minimumKeepVersion = "0.42.5"
+
+// backendCapabilities are the version and the endpoints of the backend, read once from its OpenAPI document
+// and shared by all copies of the client. An unknown backend, e.g. one not serving its OpenAPI document,
+// supports all endpoints, so only backends known to be too old are rejected.
+type backendCapabilities struct {
+	mu       sync.Mutex
+	detected bool
+	version  string
+	// endpoints are keyed by method and path, their query parameters by method, path and name, e.g. "GET /mapping?name"
+	endpoints map[string]bool
+	// rejected are the endpoints requests were rejected with 405 for, by backends not listing their endpoints
+	rejected map[string]bool
+}
+
+// BackendVersion returns the version of the Keep backend, empty if it cannot be detected
+func (c *Client) BackendVersion(ctx context.Context) string {
+	capabilities := c.backendCapabilities(ctx)
+	if capabilities == nil {
+		return ""
+	}
+	return capabilities.version
+}
+
+// requireEndpoint returns an error if the backend is known not to serve the endpoint a feature needs,
+// which is clearer than the 404 or 405 an older backend responds with
+func (c *Client) requireEndpoint(ctx context.Context, method, path, feature string) (*ErrorResponse, error) {
+	capabilities := c.backendCapabilities(ctx)
+	if capabilities == nil || capabilities.endpoints == nil || capabilities.endpoints[method+" "+path] {
+		return nil, nil
+	}
+
+	version := capabilities.version
+	if version == "" {
+		version = "unknown"
+	}
+	return &ErrorResponse{
+			Error:   "Unsupported Keep version",
+			Details: fmt.Sprintf("%s requires Keep >= %s with the endpoint %s %s, the backend runs Keep %s", feature, minimumKeepVersion, method, path, version),
+		},
+		fmt.Errorf("%s requires Keep >= %s, the backend runs Keep %s", feature, minimumKeepVersion, version)
+}
+
+// supportsEndpoint reports whether the backend may serve the endpoint, i.e. it lists the endpoint or doesn't
+// list its endpoints and didn't reject a request to the endpoint before
+func (c *Client) supportsEndpoint(ctx context.Context, method, path string) bool {
+	capabilities := c.backendCapabilities(ctx)
+	if capabilities == nil {
+		return true
+	}
+	if capabilities.endpoints != nil {
+		return capabilities.endpoints[method+" "+path]
+	}
+
+	capabilities.mu.Lock()
+	defer capabilities.mu.Unlock()
+	return !capabilities.rejected[method+" "+path]
+}
+
+// advertisesEndpoint reports whether the backend is known to serve the endpoint
+func (c *Client) advertisesEndpoint(ctx context.Context, method, path string) bool {
+	capabilities := c.backendCapabilities(ctx)
+	return capabilities != nil && capabilities.endpoints[method+" "+path]
+}
+
+// advertisesQueryParameter reports whether the backend is known to accept the query parameter of the endpoint
+func (c *Client) advertisesQueryParameter(ctx context.Context, method, path, name string) bool {
+	return c.advertisesEndpoint(ctx, method, path+"?"+name)
+}
+
+// rejectEndpoint remembers that the backend rejected a request to the endpoint with 405,
+// so it isn't requested again before falling back to another endpoint
+func (c *Client) rejectEndpoint(method, path string) {
+	if c.capabilities == nil {
+		return
+	}
+
+	c.capabilities.mu.Lock()
+	defer c.capabilities.mu.Unlock()
+	if c.capabilities.rejected == nil {
+		c.capabilities.rejected = make(map[string]bool)
+	}
+	c.capabilities.rejected[method+" "+path] = true
+}
+
+// backendCapabilities detects the capabilities of the backend on first use, so configuring the provider doesn't
+// request the OpenAPI document. The detection is shared by all operations, so it isn't canceled with the operation
+// which happens to trigger it.
+func (c *Client) backendCapabilities(ctx context.Context) *backendCapabilities {
+	if c.capabilities == nil {
+		return nil
+	}
+
+	c.capabilities.mu.Lock()
+	defer c.capabilities.mu.Unlock()
+
+	if !c.capabilities.detected {
+		c.capabilities.detected = true
+		c.capabilities.version, c.capabilities.endpoints = c.detectBackendCapabilities(context.WithoutCancel(ctx))
+	}
+	return c.capabilities
+}
+
+// detectBackendCapabilities reads the version and the endpoints of the backend from its OpenAPI document,
+// failures leave them unknown
+func (c *Client) detectBackendCapabilities(ctx context.Context) (string, map[string]bool) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint("openapi.json"), nil)
+	if err != nil {
+		return "", nil
+	}
+
+	body, _, err := c.doReq(req)
+	if err != nil {
+		tflog.Debug(ctx, "Cannot detect the Keep backend version", map[string]interface{}{"error": err.Error()})
+		return "", nil
+	}
+
+	var document struct {
+		Info struct {
+			Version string `json:"version"`
+		} `json:"info"`
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(body, &document); err != nil || len(document.Paths) == 0 {
+		return document.Info.Version, nil
+	}
+
+	endpoints := make(map[string]bool)
+	for path, methods := range document.Paths {
+		for method, content := range methods {
+			endpoint := strings.ToUpper(method) + " " + path
+			endpoints[endpoint] = true
+
+			var operation struct {
+				Parameters []struct {
+					Name string `json:"name"`
+					In   string `json:"in"`
+				} `json:"parameters"`
+			}
+			// path level fields, e.g. shared parameters, aren't operations
+			if json.Unmarshal(content, &operation) != nil {
+				continue
+			}
+			for _, parameter := range operation.Parameters {
+				if parameter.In == "query" {
+					endpoints[endpoint+"?"+parameter.Name] = true
+				}
+			}
+		}
+	}
+
+	tflog.Info(ctx, "Detected Keep backend version", map[string]interface{}{"version": document.Info.Version})
+	return document.Info.Version, endpoints
+}
@@ -0,0 +1,196 @@
+package keep
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// alertFieldTypes are the CEL types of the fields of alerts, see AlertDto of openapi.json. Optional strings are
+// nullable, source is dynamic since conditions compare it with a single source as well as with lists.
+var alertFieldTypes = map[string]*cel.Type{
+	"apiKeyRef":          cel.NullableType(cel.StringType),
+	"assignee":           cel.NullableType(cel.StringType),
+	"deleted":            cel.BoolType,
+	"description":        cel.NullableType(cel.StringType),
+	"description_format": cel.NullableType(cel.StringType),
+	"dismissUntil":       cel.NullableType(cel.StringType),
+	"dismissed":          cel.BoolType,
+	"duplicateReason":    cel.NullableType(cel.StringType),
+	"enriched_fields":    cel.ListType(cel.DynType),
+	"environment":        cel.NullableType(cel.StringType),
+	"event_id":           cel.NullableType(cel.StringType),
+	"fingerprint":        cel.NullableType(cel.StringType),
+	"firingCounter":      cel.IntType,
+	"firingStartTime":    cel.NullableType(cel.StringType),
+	"id":                 cel.NullableType(cel.StringType),
+	"imageUrl":           cel.NullableType(cel.StringType),
+	"incident":           cel.NullableType(cel.StringType),
+	"isFullDuplicate":    cel.BoolType,
+	"isNoisy":            cel.BoolType,
+	"isPartialDuplicate": cel.BoolType,
+	"labels":             cel.MapType(cel.StringType, cel.DynType),
+	"lastReceived":       cel.NullableType(cel.StringType),
+	"message":            cel.NullableType(cel.StringType),
+	"name":               cel.NullableType(cel.StringType),
+	"note":               cel.NullableType(cel.StringType),
+	"providerId":         cel.NullableType(cel.StringType),
+	"providerType":       cel.NullableType(cel.StringType),
+	"pushed":             cel.BoolType,
+	"service":            cel.NullableType(cel.StringType),
+	"severity":           cel.NullableType(cel.StringType),
+	"source":             cel.DynType,
+	"startedAt":          cel.NullableType(cel.StringType),
+	"status":             cel.NullableType(cel.StringType),
+	"url":                cel.NullableType(cel.StringType),
+}
+
+// checkCELCondition compiles a CEL condition and type-checks it against the fields of alerts. Fields which are
+// not alert fields are dynamic, since alerts carry custom and enriched fields which are not known upfront.
+func checkCELCondition(expr string) error {
+	env, err := cel.NewEnv()
+	if err != nil {
+		return fmt.Errorf("cannot create CEL environment: %s", err)
+	}
+
+	parsed, issues := env.Parse(expr)
+	if issues != nil && issues.Err() != nil {
+		return issues.Err()
+	}
+
+	declared := make(map[string]bool, len(alertFieldTypes))
+	opts := make([]cel.EnvOption, 0, len(alertFieldTypes))
+	for field, fieldType := range alertFieldTypes {
+		declared[field] = true
+		opts = append(opts, cel.Variable(field, fieldType))
+	}
+	celast.PostOrderVisit(parsed.NativeRep().Expr(), celast.NewExprVisitor(func(e celast.Expr) {
+		if e.Kind() == celast.IdentKind && !declared[e.AsIdent()] {
+			declared[e.AsIdent()] = true
+			opts = append(opts, cel.Variable(e.AsIdent(), cel.DynType))
+		}
+	}))
+
+	if env, err = cel.NewEnv(opts...); err != nil {
+		return fmt.Errorf("cannot create CEL environment: %s", err)
+	}
+
+	checked, issues := env.Check(parsed)
+	if issues != nil && issues.Err() != nil {
+		return issues.Err()
+	}
+
+	if kind := checked.OutputType().Kind(); kind != types.BoolKind && kind != types.DynKind {
+		return fmt.Errorf("condition must evaluate to a boolean, got %s", checked.OutputType())
+	}
+
+	return nil
+}
+
+// normalizeCELExpression returns the canonical form of a CEL expression, so that expressions which
+// only differ in formatting (whitespace, redundant parentheses) compare equal
+func normalizeCELExpression(expr string) (string, error) {
+	env, err := cel.NewEnv()
+	if err != nil {
+		return "", fmt.Errorf("cannot create CEL environment: %s", err)
+	}
+
+	ast, issues := env.Parse(expr)
+	if issues != nil && issues.Err() != nil {
+		return "", issues.Err()
+	}
+
+	return cel.AstToString(ast)
+}
+
+// suppressEquivalentCELDiff is a DiffSuppressFunc ignoring formatting only changes of CEL expressions
+func suppressEquivalentCELDiff(k, old, new string, d *schema.ResourceData) bool {
+	return equivalentCELExpressions(old, new)
+}
+
+// equivalentCELExpressions reports whether two CEL expressions only differ in formatting
+func equivalentCELExpressions(old, new string) bool {
+	if old == new {
+		return true
+	}
+
+	if old == "" || new == "" {
+		return false
+	}
+
+	oldNormalized, err := normalizeCELExpression(old)
+	if err != nil {
+		return false
+	}
+
+	newNormalized, err := normalizeCELExpression(new)
+	if err != nil {
+		return false
+	}
+
+	return oldNormalized == newNormalized
+}
+
+// validateCELCondition is a ValidateDiagFunc for optional CEL condition attributes
+func validateCELCondition(v interface{}, path cty.Path) diag.Diagnostics {
+	expr, ok := v.(string)
+	if !ok {
+		return diag.Errorf("expected condition to be a string")
+	}
+
+	if expr == "" {
+		return nil
+	}
+
+	if err := checkCELCondition(expr); err != nil {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid CEL expression",
+			Detail:        fmt.Sprintf("condition %q is not a valid CEL expression: %s", expr, err),
+			AttributePath: path,
+		}}
+	}
+
+	return nil
+}
+
+// evaluateCELCondition evaluates a CEL condition against an alert payload,
+// the top level fields of the alert are declared as variables of the expression
+func evaluateCELCondition(expr string, alert map[string]interface{}) (bool, error) {
+	opts := make([]cel.EnvOption, 0, len(alert))
+	for field := range alert {
+		opts = append(opts, cel.Variable(field, cel.DynType))
+	}
+
+	env, err := cel.NewEnv(opts...)
+	if err != nil {
+		return false, fmt.Errorf("cannot create CEL environment: %s", err)
+	}
+
+	ast, issues := env.Compile(expr)
+	if issues != nil && issues.Err() != nil {
+		return false, issues.Err()
+	}
+
+	program, err := env.Program(ast)
+	if err != nil {
+		return false, fmt.Errorf("cannot create CEL program: %s", err)
+	}
+
+	out, _, err := program.Eval(alert)
+	if err != nil {
+		return false, fmt.Errorf("cannot evaluate CEL expression: %s", err)
+	}
+
+	result, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("CEL expression does not evaluate to a boolean, got %v", out.Value())
+	}
+
+	return result, nil
+}
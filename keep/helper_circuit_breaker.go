@@ -0,0 +1,70 @@
+package keep
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCircuitBreakerThreshold = 5
+	// defaultCircuitBreakerCooldown is how long requests fail fast before a single request probes the backend again
+	defaultCircuitBreakerCooldown = time.Minute
+)
+
+// circuitBreaker fails requests immediately after Threshold consecutive requests failed without a response
+// from the backend, so a plan with many resources fails within seconds instead of timing out every resource.
+// It is shared by all copies of the client.
+type circuitBreaker struct {
+	Threshold int
+	Cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	lastErr  error
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{Threshold: threshold, Cooldown: cooldown}
+}
+
+// allow returns an error if the circuit is open. After the cooldown the circuit lets a single request
+// through, which closes it again if the backend responds.
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.Threshold {
+		return nil
+	}
+	if time.Since(b.openedAt) >= b.Cooldown {
+		b.openedAt = time.Now()
+		return nil
+	}
+	return fmt.Errorf("the Keep backend is unreachable, %d consecutive requests failed, the last one with: %v. Requests fail immediately until the backend is reachable again", b.failures, b.lastErr)
+}
+
+// record counts requests which failed without a response, any response of the backend closes the circuit
+func (b *circuitBreaker) record(statusCode int, err error) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if statusCode != 0 || err == nil {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	b.lastErr = err
+	if b.failures == b.Threshold {
+		b.openedAt = time.Now()
+	}
+}
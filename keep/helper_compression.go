@@ -0,0 +1,79 @@
+package keep
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+const (
+	compressionNone = "none"
+	compressionGzip = "gzip"
+
+	// defaultCompressionMinSize is the size of request bodies compressed with gzip, smaller bodies don't
+	// hit body size limits of gateways and aren't worth the compression
+	defaultCompressionMinSize = 64 * 1024
+)
+
+// requestCompression compresses large request bodies with gzip. It is shared by all copies of the client,
+// so once the backend rejects a compressed body no further bodies are compressed.
+type requestCompression struct {
+	MinSize int64
+
+	unsupported atomic.Bool
+}
+
+func newRequestCompression(minSize int64) *requestCompression {
+	return &requestCompression{MinSize: minSize}
+}
+
+// compress replaces the body of the request by its gzip compressed content if it is at least MinSize bytes
+// and compresses well. The returned function restores the uncompressed body, it is nil if the body wasn't replaced.
+func (r *requestCompression) compress(req *http.Request) (func() error, error) {
+	if r == nil || r.unsupported.Load() || req.GetBody == nil || req.ContentLength < r.MinSize || req.Header.Get("Content-Encoding") != "" {
+		return nil, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %v", err)
+	}
+	defer body.Close()
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := io.Copy(writer, body); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %v", err)
+	}
+	if int64(compressed.Len()) >= req.ContentLength {
+		return nil, nil
+	}
+
+	getBody, contentLength := req.GetBody, req.ContentLength
+	content := compressed.Bytes()
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(content)), nil
+	}
+	req.Body, _ = req.GetBody()
+	req.ContentLength = int64(len(content))
+	req.Header.Set("Content-Encoding", compressionGzip)
+
+	return func() error {
+		req.GetBody, req.ContentLength = getBody, contentLength
+		req.Header.Del("Content-Encoding")
+		var err error
+		req.Body, err = getBody()
+		return err
+	}, nil
+}
+
+// disable stops compressing request bodies after the backend rejected a compressed body
+func (r *requestCompression) disable() {
+	r.unsupported.Store(true)
+}
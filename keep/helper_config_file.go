@@ -0,0 +1,66 @@
+package keep
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// defaultConfigFile is the configuration file shared with the Keep CLI, relative to the home directory
+const defaultConfigFile = ".keep/config.yaml"
+
+// configProfile are the connection settings of a profile in the configuration file
+type configProfile struct {
+	APIURL   string `yaml:"api_url"`
+	APIKey   string `yaml:"api_key"`
+	TenantID string `yaml:"tenant_id"`
+}
+
+// configFile is the configuration file. Its top level settings are the default profile like
+// in the Keep CLI, further profiles are configured below profiles.
+type configFile struct {
+	configProfile `yaml:",inline"`
+	Profiles      map[string]configProfile `yaml:"profiles"`
+}
+
+// loadConfigProfile reads a profile of the configuration file. An empty path reads the default configuration file,
+// which may not exist unless a profile is requested. An empty profile is the default profile.
+func loadConfigProfile(path, profile string) (*configProfile, error) {
+	explicit := path != "" || profile != ""
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			if explicit {
+				return nil, fmt.Errorf("cannot find the home directory: %s", err)
+			}
+			return &configProfile{}, nil
+		}
+		path = filepath.Join(home, defaultConfigFile)
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return &configProfile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read config file: %s", err)
+	}
+
+	var config configFile
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %s", path, err)
+	}
+
+	if profile == "" || profile == "default" {
+		return &config.configProfile, nil
+	}
+
+	selected, ok := config.Profiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("profile %s not found in config file %s", profile, path)
+	}
+	return &selected, nil
+}
@@ -0,0 +1,59 @@
+package keep
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// credentialSource provides the api key or token requests are authenticated with
+type credentialSource interface {
+	// Token returns the current api key or token
+	Token(ctx context.Context) (string, error)
+	// Invalidate drops a cached api key or token after it was rejected, so the next call of Token provides a new one
+	Invalidate()
+}
+
+// Ensure fileCredentialSource implements credentialSource interface
+var _ credentialSource = &fileCredentialSource{}
+
+// fileCredentialSource reads the api key from a file and keeps it until it is rejected,
+// so keys rotated by a secret agent are picked up without restarting terraform
+type fileCredentialSource struct {
+	Path string
+
+	mu     sync.Mutex
+	apiKey string
+}
+
+// Token returns the cached api key or reads it from the file
+func (s *fileCredentialSource) Token(_ context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.apiKey != "" {
+		return s.apiKey, nil
+	}
+
+	content, err := os.ReadFile(s.Path)
+	if err != nil {
+		return "", err
+	}
+
+	apiKey := strings.TrimSpace(string(content))
+	if apiKey == "" {
+		return "", fmt.Errorf("file %s is empty", s.Path)
+	}
+
+	s.apiKey = apiKey
+	return s.apiKey, nil
+}
+
+// Invalidate drops the cached api key, so it is read from the file again
+func (s *fileCredentialSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apiKey = ""
+}
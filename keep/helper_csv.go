@@ -0,0 +1,75 @@
+package keep
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// parseCSVRows reads CSV content with a header row and returns a map of column to value for every other row
+func parseCSVRows(r io.Reader, delimiter rune) ([]map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("CSV has no header row")
+	}
+
+	headers := records[0]
+	records = records[1:]
+
+	rows := make([]map[string]string, len(records))
+	for i, record := range records {
+		row := make(map[string]string)
+		for j, cell := range record {
+			row[headers[j]] = cell
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// parseCSVHeader reads the header row of CSV content
+func parseCSVHeader(r io.Reader, delimiter rune) ([]string, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("CSV has no header row")
+	}
+	return header, err
+}
+
+// formatCSVRows writes the rows as CSV content with a header row of all columns in alphabetical order
+func formatCSVRows(rows []map[string]string) (string, error) {
+	columnSet := make(map[string]bool)
+	for _, row := range rows {
+		for column := range row {
+			columnSet[column] = true
+		}
+	}
+	columns := getKeysFromMap(columnSet)
+	sort.Strings(columns)
+
+	var content strings.Builder
+	writer := csv.NewWriter(&content)
+	if err := writer.Write(columns); err != nil {
+		return "", err
+	}
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = row[column]
+		}
+		if err := writer.Write(record); err != nil {
+			return "", err
+		}
+	}
+	writer.Flush()
+	return content.String(), writer.Error()
+}
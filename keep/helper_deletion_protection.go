@@ -0,0 +1,30 @@
+package keep
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// deletionProtectionSchema returns the deletion_protection attribute of resources whose destroy is refused by
+// the provider while it is enabled. Unlike lifecycle.prevent_destroy it is stored in the state, so it also applies
+// to destroys of resources removed from the configuration.
+func deletionProtectionSchema(kind string) *schema.Schema {
+	return &schema.Schema{
+		Type: schema.TypeBool,
+		// without a default, states of earlier versions of the provider don't plan a change of the attribute
+		Optional:    true,
+		Description: fmt.Sprintf("Refuse to delete the %s, including replacements, until deletion_protection is disabled and applied. Default is false.", kind),
+	}
+}
+
+// checkDeletionProtection returns an error if the deletion protection of the resource is enabled
+func checkDeletionProtection(d *schema.ResourceData, kind string) diag.Diagnostics {
+	if !d.Get("deletion_protection").(bool) {
+		return nil
+	}
+	return attributeErrorf(cty.GetAttrPath("deletion_protection"),
+		"cannot delete %s %s while deletion_protection is enabled, set deletion_protection = false and apply before deleting it", kind, d.Id())
+}
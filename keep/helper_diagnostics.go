@@ -0,0 +1,18 @@
+package keep
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// attributeErrorf returns an error diagnostic of the attribute at path, so terraform points at the
+// attribute in the configuration instead of the whole block
+func attributeErrorf(path cty.Path, format string, a ...interface{}) diag.Diagnostics {
+	return diag.Diagnostics{{
+		Severity:      diag.Error,
+		Summary:       fmt.Sprintf(format, a...),
+		AttributePath: path,
+	}}
+}
@@ -0,0 +1,165 @@
+package keep
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"gopkg.in/yaml.v2"
+)
+
+// defaultAlertAttributes are the attributes of every alert, workflows reference them without any enrichment
+var defaultAlertAttributes = []string{
+	"apiKeyRef", "assignee", "deleted", "description", "description_format", "dismissUntil", "dismissed",
+	"duplicateReason", "enriched_fields", "environment", "event_id", "fingerprint", "firingCounter",
+	"firingStartTime", "group", "id", "imageUrl", "incident", "isFullDuplicate", "isNoisy", "isPartialDuplicate",
+	"labels", "lastReceived", "message", "name", "note", "providerId", "providerType", "pushed", "service",
+	"severity", "source", "startedAt", "status", "unresolvedCounter", "url",
+}
+
+// alertReference matches references of workflows to attributes of the alert, e.g. {{ alert.service }}
+var alertReference = regexp.MustCompile(`\balert\.([A-Za-z_][A-Za-z0-9_]*)`)
+
+// mappingEnrichedAttributes returns the columns which are not part of any matcher, the backend adds them to
+// the alerts matching a row
+func mappingEnrichedAttributes(columns []string, matchers []string) []string {
+	matched := make(map[string]bool)
+	for _, matcher := range formatMatchers(matchers) {
+		for _, column := range matcher {
+			matched[strings.TrimSpace(column)] = true
+		}
+	}
+
+	attributes := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if !matched[column] {
+			attributes = append(attributes, column)
+		}
+	}
+	sort.Strings(attributes)
+	return attributes
+}
+
+// extractionEnrichedAttributes returns the names of the capture groups of the regex, the backend adds them to
+// the alerts the regex matches
+func extractionEnrichedAttributes(expr string) []string {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return []string{}
+	}
+
+	attributes := make([]string, 0)
+	for _, name := range re.SubexpNames() {
+		if name != "" {
+			attributes = append(attributes, name)
+		}
+	}
+	sort.Strings(attributes)
+	return attributes
+}
+
+// workflowAlertReferences returns the attributes of the alert which the workflow references in templates and
+// conditions or filters its alert triggers by, sorted and without duplicates
+func workflowAlertReferences(content []byte) ([]string, error) {
+	var workflowWrapper struct {
+		Workflow struct {
+			Triggers []struct {
+				Type    string `yaml:"type"`
+				Filters []struct {
+					Key string `yaml:"key"`
+				} `yaml:"filters"`
+			} `yaml:"triggers"`
+		} `yaml:"workflow"`
+	}
+	if err := yaml.Unmarshal(content, &workflowWrapper); err != nil {
+		return nil, fmt.Errorf("invalid workflow YAML: %s", err)
+	}
+
+	references := make(map[string]bool)
+	for _, trigger := range workflowWrapper.Workflow.Triggers {
+		if trigger.Type != "alert" {
+			continue
+		}
+		for _, filter := range trigger.Filters {
+			if key := strings.Split(filter.Key, ".")[0]; key != "" {
+				references[key] = true
+			}
+		}
+	}
+	for _, match := range alertReference.FindAllSubmatch(content, -1) {
+		references[string(match[1])] = true
+	}
+
+	attributes := getKeysFromMap(references)
+	sort.Strings(attributes)
+	return attributes, nil
+}
+
+// validateWorkflowEnrichmentReferences warns about references of the workflow to attributes of the alert which
+// neither every alert has nor enrichment_attributes lists. It only validates if enrichment_attributes is set and
+// the workflow is known, terraform validates the configuration again during plan with the values of other resources.
+func validateWorkflowEnrichmentReferences(ctx context.Context, req schema.ValidateResourceConfigFuncRequest, resp *schema.ValidateResourceConfigFuncResponse) {
+	if req.RawConfig.IsNull() || !req.RawConfig.IsKnown() {
+		return
+	}
+
+	enrichments := req.RawConfig.GetAttr("enrichment_attributes")
+	if enrichments.IsNull() || !enrichments.IsWhollyKnown() {
+		return
+	}
+
+	var content []byte
+	for _, name := range []string{"content", "file", "workflow_file_path"} {
+		value := req.RawConfig.GetAttr(name)
+		if !value.IsKnown() {
+			return
+		}
+		if value.IsNull() {
+			continue
+		}
+		if name == "content" {
+			content = []byte(value.AsString())
+		} else if fileContent, err := os.ReadFile(value.AsString()); err == nil {
+			content = fileContent
+		}
+		break
+	}
+	if content == nil {
+		return
+	}
+
+	// invalid workflows are reported when they are uploaded
+	references, err := workflowAlertReferences(content)
+	if err != nil {
+		return
+	}
+
+	known := make(map[string]bool)
+	for _, attribute := range defaultAlertAttributes {
+		known[attribute] = true
+	}
+	for it := enrichments.ElementIterator(); it.Next(); {
+		if _, attribute := it.Element(); !attribute.IsNull() {
+			known[attribute.AsString()] = true
+		}
+	}
+
+	for _, reference := range references {
+		if known[reference] {
+			continue
+		}
+		resp.Diagnostics = append(resp.Diagnostics, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Alert attribute is not enriched",
+			Detail: fmt.Sprintf("The workflow references alert.%s, which is neither an attribute of every alert nor in enrichment_attributes. "+
+				"Add the enriched_attributes of the mapping or extraction which adds it to enrichment_attributes.", reference),
+			AttributePath: cty.GetAttrPath("enrichment_attributes"),
+		})
+	}
+}
@@ -10,9 +10,13 @@ import (
 
 // FileHasher provides functionality for file content hash checking
 type FileHasher struct {
-	FilePath    string
+	FilePath string
+	// Content is hashed instead of the file at FilePath if set, e.g. content configured inline
+	Content     []byte
 	HashField   string
 	Description string
+	// UpdateInPlace plans content changes as an update instead of a replacement
+	UpdateInPlace bool
 }
 
 // calculateFileHash calculates SHA256 hash of file content
@@ -22,12 +26,20 @@ func calculateFileHash(filePath string) (string, error) {
 		return "", fmt.Errorf("cannot read file: %s", err)
 	}
 
-	h := sha256.New()
-	if _, err := h.Write(content); err != nil {
-		return "", fmt.Errorf("cannot calculate hash: %s", err)
-	}
+	return calculateContentHash(content), nil
+}
 
-	return fmt.Sprintf("%x", h.Sum(nil)), nil
+// calculateContentHash calculates SHA256 hash of content, the same as of a file with the content
+func calculateContentHash(content []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(content))
+}
+
+// hash returns the hash of Content if set, otherwise of the file
+func (h *FileHasher) hash() (string, error) {
+	if h.Content != nil {
+		return calculateContentHash(h.Content), nil
+	}
+	return calculateFileHash(h.FilePath)
 }
 
 // AddHashFieldToSchema adds a content hash field to a schema
@@ -35,34 +47,39 @@ func (h *FileHasher) AddHashFieldToSchema(s map[string]*schema.Schema) {
 	s[h.HashField] = &schema.Schema{
 		Type:        schema.TypeString,
 		Computed:    true,
-		ForceNew:    true,
+		ForceNew:    !h.UpdateInPlace,
 		Description: h.Description,
 	}
 }
 
 // CustomizeDiff adds hash checking to resource diff
 func (h *FileHasher) CustomizeDiff(ctx interface{}, d *schema.ResourceDiff) error {
-	if h.FilePath == "" {
+	if h.FilePath == "" && h.Content == nil {
 		return nil
 	}
 
-	hash, err := calculateFileHash(h.FilePath)
+	hash, err := h.hash()
 	if err != nil {
 		return fmt.Errorf("cannot calculate file hash: %s", err)
 	}
 
 	oldHash := d.Get(h.HashField).(string)
 	if oldHash != hash {
-		d.ForceNew(h.HashField)
-		d.SetNew(h.HashField, hash)
+		if err := d.SetNew(h.HashField, hash); err != nil {
+			return err
+		}
+		// ForceNew needs the change set before
+		if !h.UpdateInPlace {
+			return d.ForceNew(h.HashField)
+		}
 	}
 
 	return nil
 }
 
-// SetFileHash calculates and sets the file hash in ResourceData
+// SetFileHash calculates and sets the hash of the file or Content in ResourceData
 func (h *FileHasher) SetFileHash(d *schema.ResourceData) error {
-	hash, err := calculateFileHash(h.FilePath)
+	hash, err := h.hash()
 	if err != nil {
 		return fmt.Errorf("cannot calculate file hash: %s", err)
 	}
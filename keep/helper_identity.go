@@ -0,0 +1,61 @@
+package keep
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// idIdentitySchema is the identity schema of resources identified by the id the backend assigned. Terraform 1.12+
+// stores the identity next to the state, import blocks can use it instead of the import ID.
+func idIdentitySchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"id": {
+			Type:              schema.TypeString,
+			RequiredForImport: true,
+			Description:       "ID of the object in the backend",
+		},
+	}
+}
+
+// idIdentity returns the identity of resources identified by their id
+func idIdentity() *schema.ResourceIdentity {
+	return &schema.ResourceIdentity{
+		SchemaFunc: idIdentitySchema,
+	}
+}
+
+// setIdentity sets the identity of the resource to its id and the given attributes
+func setIdentity(d *schema.ResourceData, attributes map[string]string) diag.Diagnostics {
+	identity, err := d.Identity()
+	if err != nil {
+		return diag.Errorf("Failed to get identity: %s", err.Error())
+	}
+	if err := identity.Set("id", d.Id()); err != nil {
+		return diag.Errorf("Failed to set identity id: %s", err.Error())
+	}
+	for name, value := range attributes {
+		if err := identity.Set(name, value); err != nil {
+			return diag.Errorf("Failed to set identity %s: %s", name, err.Error())
+		}
+	}
+	return nil
+}
+
+// importIDFromIdentity returns the import ID, which is the id of the identity if the resource is imported by identity
+func importIDFromIdentity(d *schema.ResourceData) (string, error) {
+	if d.Id() != "" {
+		return d.Id(), nil
+	}
+
+	identity, err := d.Identity()
+	if err != nil {
+		return "", fmt.Errorf("error getting identity: %s", err)
+	}
+	id, _ := identity.Get("id").(string)
+	if id == "" {
+		return "", fmt.Errorf("expected the identity to contain the id")
+	}
+	return id, nil
+}
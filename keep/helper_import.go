@@ -0,0 +1,33 @@
+package keep
+
+import (
+	"fmt"
+	"strings"
+)
+
+// importNamePrefix selects the object to import by name instead of id, e.g. "name=<name>". Ids are generated
+// by the backend and often not visible in the UI, names are.
+const importNamePrefix = "name="
+
+// resolveImportID returns the id of the object to import. Import ids using the "name=<name>" syntax are resolved
+// with findIDs, which returns the ids of all objects with the name, other import ids are returned unchanged.
+func resolveImportID(importID, kind string, findIDs func(name string) ([]string, error)) (string, error) {
+	name, ok := strings.CutPrefix(importID, importNamePrefix)
+	if !ok {
+		return importID, nil
+	}
+
+	ids, err := findIDs(name)
+	if err != nil {
+		return "", err
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("%s with name '%s' not found", kind, name)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("multiple %ss with name '%s' found (ids: %v), import by id instead", kind, name, ids)
+	}
+}
@@ -0,0 +1,83 @@
+package keep
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultListCacheTTL is how long list responses are reused by the client of the provider, short enough
+// for objects changed outside of terraform during a long apply to show up
+const defaultListCacheTTL = 30 * time.Second
+
+// listCache keeps the responses of list endpoints for a short time, so the many resources of a plan share
+// their list requests. It is shared by all copies of the client and every write request invalidates it.
+type listCache struct {
+	TTL time.Duration
+
+	mu         sync.Mutex
+	generation uint64
+	entries    map[string]*listCacheEntry
+}
+
+// listCacheEntry is a cached list response. Its mutex is held while the list is requested,
+// so concurrent reads of the same list share a single request.
+type listCacheEntry struct {
+	mu         sync.Mutex
+	content    []byte
+	generation uint64
+	fetched    time.Time
+}
+
+func newListCache(ttl time.Duration) *listCache {
+	return &listCache{
+		TTL:     ttl,
+		entries: make(map[string]*listCacheEntry),
+	}
+}
+
+// get returns the cached response of key or fetches it. Failed requests are not cached.
+func (l *listCache) get(key string, fetch func() ([]byte, *ErrorResponse, error)) ([]byte, *ErrorResponse, error) {
+	if l == nil {
+		return fetch()
+	}
+
+	l.mu.Lock()
+	entry, ok := l.entries[key]
+	if !ok {
+		entry = &listCacheEntry{}
+		l.entries[key] = entry
+	}
+	l.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	// a write finishing while the list is requested increases the generation, so the response isn't reused
+	generation := l.currentGeneration()
+	if entry.content != nil && entry.generation == generation && time.Since(entry.fetched) < l.TTL {
+		return entry.content, nil, nil
+	}
+
+	content, errResp, err := fetch()
+	if err != nil {
+		return nil, errResp, err
+	}
+	entry.content, entry.generation, entry.fetched = content, generation, time.Now()
+	return content, nil, nil
+}
+
+// invalidate drops all cached responses
+func (l *listCache) invalidate() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.generation++
+}
+
+func (l *listCache) currentGeneration() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.generation
+}
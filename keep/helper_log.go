@@ -0,0 +1,137 @@
+package keep
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// redactedValue replaces secrets in log messages
+const redactedValue = "***"
+
+// redactedHeaders are the request headers carrying credentials
+var redactedHeaders = []string{"X-API-Key", "Authorization", "Cookie"}
+
+// unredactedBodyKeys are the keys of provider requests and responses which never contain auth config values
+var unredactedBodyKeys = map[string]bool{
+	"id":                  true,
+	"type":                true,
+	"display_name":        true,
+	"provider_id":         true,
+	"provider_name":       true,
+	"provider_type":       true,
+	"pulling_enabled":     true,
+	"pulling_interval":    true,
+	"installed":           true,
+	"last_alert_received": true,
+}
+
+// logRequest logs the request at debug level and its headers and body at trace level with credentials redacted
+func (c *Client) logRequest(req *http.Request, resp *http.Response, respBody []byte, duration time.Duration, err error) {
+	ctx := req.Context()
+
+	fields := map[string]interface{}{
+		"http_method": req.Method,
+		"http_url":    req.URL.String(),
+		"duration_ms": duration.Milliseconds(),
+	}
+	if requestID := responseRequestID(req, resp); requestID != "" {
+		fields["request_id"] = requestID
+	}
+	if resp != nil {
+		fields["http_status"] = resp.StatusCode
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	tflog.Debug(ctx, "Keep API request", fields)
+
+	traceFields := map[string]interface{}{
+		"http_request_headers": c.redactHeaders(req.Header),
+	}
+	// compressed bodies are logged with their size only
+	if req.Header.Get("Content-Encoding") != "" {
+		traceFields["http_request_body_size"] = req.ContentLength
+	} else if req.GetBody != nil && strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		if body, err := req.GetBody(); err == nil {
+			content, _ := io.ReadAll(body)
+			traceFields["http_request_body"] = redactBody(req.URL.Path, content)
+		}
+	}
+	if respBody != nil {
+		traceFields["http_response_body"] = redactBody(req.URL.Path, respBody)
+	}
+	tflog.Trace(ctx, "Keep API request details", traceFields)
+}
+
+// redactHeaders returns the request headers with the values of credentials and custom headers redacted
+func (c *Client) redactHeaders(header http.Header) map[string]string {
+	redacted := make(map[string]string, len(header))
+	for key := range header {
+		redacted[key] = header.Get(key)
+	}
+	for _, key := range redactedHeaders {
+		if _, ok := redacted[http.CanonicalHeaderKey(key)]; ok {
+			redacted[http.CanonicalHeaderKey(key)] = redactedValue
+		}
+	}
+	// custom headers are used for proxies like Cloudflare Access and often carry secrets
+	for key := range c.Headers {
+		redacted[http.CanonicalHeaderKey(key)] = redactedValue
+	}
+	if c.AuthHook != nil {
+		for _, key := range c.AuthHook.HeaderNames() {
+			redacted[key] = redactedValue
+		}
+	}
+	return redacted
+}
+
+// redactBody returns the body for logging. Bodies of provider requests contain auth config values,
+// so all their strings are redacted except those of unredactedBodyKeys.
+func redactBody(path string, body []byte) string {
+	if !strings.Contains(path, "/providers") {
+		return string(body)
+	}
+
+	var content interface{}
+	if err := json.Unmarshal(body, &content); err != nil {
+		return redactedValue
+	}
+
+	redacted, err := json.Marshal(redactJSON(content))
+	if err != nil {
+		return redactedValue
+	}
+	return string(redacted)
+}
+
+// redactJSON replaces all strings in a decoded JSON value except the values of unredactedBodyKeys
+func redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if _, isString := item.(string); isString && unredactedBodyKeys[key] {
+				redacted[key] = item
+				continue
+			}
+			redacted[key] = redactJSON(item)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = redactJSON(item)
+		}
+		return redacted
+	case string:
+		return redactedValue
+	default:
+		return v
+	}
+}
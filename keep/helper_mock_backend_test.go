@@ -0,0 +1,666 @@
+package keep
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"gopkg.in/yaml.v2"
+)
+
+// mockBackend is an in-process Keep backend for unit tests of the resources. It keeps providers, workflows,
+// mappings and extractions in memory, serves the OpenAPI document of the repository and records the
+// requests it received.
+type mockBackend struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	nextID      int
+	providers   map[string]KeepProvider
+	workflows   map[string]Workflow
+	mappings    map[string]Mapping
+	extractions map[string]Extraction
+	webhooks    map[string]bool
+	// apiKeys are the api keys by reference id, apiKeyIDs their reference ids by name
+	apiKeys   map[string]APIKey
+	apiKeyIDs map[string]string
+	// secrets are the secrets of the workflows by workflow id
+	secrets  map[string]map[string]string
+	requests []string
+	// bodies are the bodies of the last write requests by method and path, e.g. "POST /mapping"
+	bodies map[string][]byte
+	// disabled endpoints, e.g. "DELETE /extraction/{rule_id}", are answered with 405 and left out of the OpenAPI document
+	disabled map[string]bool
+}
+
+type mockRoute struct {
+	pattern string
+	handler func(w http.ResponseWriter, r *http.Request)
+}
+
+// newMockBackend starts a mock backend which is closed at the end of the test. Disabled endpoints are
+// answered with 405, like older backends do.
+func newMockBackend(t testing.TB, disabled ...string) *mockBackend {
+	b := &mockBackend{
+		providers:   make(map[string]KeepProvider),
+		workflows:   make(map[string]Workflow),
+		mappings:    make(map[string]Mapping),
+		extractions: make(map[string]Extraction),
+		webhooks:    make(map[string]bool),
+		apiKeys:     make(map[string]APIKey),
+		apiKeyIDs:   make(map[string]string),
+		secrets:     make(map[string]map[string]string),
+		bodies:      make(map[string][]byte),
+		disabled:    make(map[string]bool),
+	}
+	for _, endpoint := range disabled {
+		b.disabled[endpoint] = true
+	}
+
+	routes := []mockRoute{
+		{"GET /providers", b.getAvailableProviders},
+		{"GET /providers/export", b.listProviders},
+		{"POST /providers/install", b.installProvider},
+		{"PUT /providers/{provider_id}", b.updateProvider},
+		{"DELETE /providers/{provider_type}/{provider_id}", b.deleteProvider},
+		{"POST /providers/test", b.ok},
+		{"POST /providers/{provider_id}/scopes", b.validateScopes},
+		{"POST /providers/install/webhook/{provider_type}/{provider_id}", b.installWebhook},
+		{"GET /settings/webhook", b.webhookSettings},
+		{"POST /settings/apikey", b.createAPIKey},
+		{"DELETE /settings/apikey/{keyId}", b.deleteAPIKey},
+		{"GET /workflows", b.listWorkflows},
+		{"GET /workflows/{workflow_id}", b.getWorkflow},
+		{"POST /workflows/json", b.createWorkflow},
+		{"DELETE /workflows/{workflow_id}", b.deleteWorkflow},
+		{"POST /workflows/{workflow_id}/secrets", b.writeWorkflowSecrets},
+		{"DELETE /workflows/{workflow_id}/secrets/{secret_name}", b.deleteWorkflowSecret},
+		{"GET /mapping", b.listMappings},
+		{"GET /mapping/{rule_id}", b.getMapping},
+		{"POST /mapping", b.createMapping},
+		{"DELETE /mapping/{rule_id}", b.deleteMapping},
+		{"GET /extraction", b.listExtractions},
+		{"POST /extraction", b.createExtraction},
+		{"PUT /extraction/{rule_id}", b.updateExtraction},
+		{"DELETE /extraction/{rule_id}", b.deleteExtraction},
+		{"GET /alerts/facets/fields", b.alertFields},
+		{"GET /whoami", b.whoami},
+	}
+
+	mux := http.NewServeMux()
+	for _, route := range routes {
+		route := route
+		mux.HandleFunc(route.pattern, func(w http.ResponseWriter, r *http.Request) {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.requests = append(b.requests, r.Method+" "+r.URL.Path)
+			if r.Method != http.MethodGet {
+				body, _ := io.ReadAll(r.Body)
+				b.bodies[r.Method+" "+r.URL.Path] = body
+				r.Body = io.NopCloser(bytes.NewReader(body))
+			}
+			if b.disabled[route.pattern] {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			route.handler(w, r)
+		})
+	}
+
+	openAPI := mockOpenAPIDocument(t, routes, b.disabled)
+	mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(openAPI)
+	})
+
+	b.Server = httptest.NewServer(mux)
+	t.Cleanup(b.Close)
+	return b
+}
+
+// mockOpenAPIDocument returns the OpenAPI document checked in with the provider without the disabled endpoints.
+// Every route has to be part of it, so the mock doesn't serve endpoints the backend doesn't have.
+func mockOpenAPIDocument(t testing.TB, routes []mockRoute, disabled map[string]bool) []byte {
+	content, err := os.ReadFile("../openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var document struct {
+		Info  map[string]interface{}            `json:"info"`
+		Paths map[string]map[string]interface{} `json:"paths"`
+	}
+	if err := json.Unmarshal(content, &document); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, route := range routes {
+		method, path, _ := strings.Cut(route.pattern, " ")
+		if _, ok := document.Paths[path][strings.ToLower(method)]; !ok {
+			t.Fatalf("the route %s is not part of openapi.json", route.pattern)
+		}
+	}
+
+	for endpoint := range disabled {
+		method, path, _ := strings.Cut(endpoint, " ")
+		delete(document.Paths[path], strings.ToLower(method))
+	}
+
+	openAPI, err := json.Marshal(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return openAPI
+}
+
+// client returns a client of the backend which doesn't retry failed requests
+func (b *mockBackend) client() *Client {
+	client := NewClient(b.URL, "key", 10*time.Second)
+	client.MaxRetries = 0
+	return client
+}
+
+// requestCount returns how often the backend received requests of the method to the path
+func (b *mockBackend) requestCount(method, path string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	count := 0
+	for _, request := range b.requests {
+		if request == method+" "+path {
+			count++
+		}
+	}
+	return count
+}
+
+// lastBody returns the body of the last request of the method to the path
+func (b *mockBackend) lastBody(method, path string) []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.bodies[method+" "+path]
+}
+
+// applyMockResource plans and applies the configuration of the resource like terraform does, a nil configuration
+// destroys the resource and a change of a ForceNew attribute replaces it. The state is nil before the resource is created and after it is destroyed.
+func applyMockResource(t *testing.T, r *schema.Resource, state *terraform.InstanceState, config map[string]interface{}, meta interface{}) (*terraform.InstanceState, diag.Diagnostics) {
+	t.Helper()
+	ctx := context.Background()
+
+	if state == nil {
+		state = &terraform.InstanceState{}
+	}
+
+	var instanceDiff *terraform.InstanceDiff
+	if config == nil {
+		instanceDiff = &terraform.InstanceDiff{Destroy: true}
+	} else {
+		var err error
+		resourceConfig := terraform.NewResourceConfigRaw(config)
+		if instanceDiff, err = r.SimpleDiff(ctx, state, resourceConfig, meta); err != nil {
+			return state, diag.FromErr(err)
+		}
+		// write-only attributes are planned as null, so changing only them plans no changes
+		for name, s := range r.SchemaMap() {
+			if s.WriteOnly && instanceDiff != nil {
+				delete(instanceDiff.Attributes, name)
+			}
+		}
+		if instanceDiff == nil || instanceDiff.Empty() {
+			return state, nil
+		}
+
+		// terraform sends the timeouts of the configuration along with the planned changes
+		if r.Timeouts != nil {
+			timeouts := *r.Timeouts
+			if err := timeouts.ConfigDecode(r, resourceConfig); err != nil {
+				return state, diag.FromErr(err)
+			}
+			if err := timeouts.DiffEncode(instanceDiff); err != nil {
+				return state, diag.FromErr(err)
+			}
+		}
+
+		// terraform sends the configuration along with the planned changes, write-only attributes are only available in it
+		rawConfig, err := json.Marshal(config)
+		if err != nil {
+			return state, diag.FromErr(err)
+		}
+		if instanceDiff.RawConfig, err = ctyjson.Unmarshal(rawConfig, r.CoreConfigSchema().ImpliedType()); err != nil {
+			return state, diag.FromErr(err)
+		}
+
+		// replacements are planned as destroy and a create without prior state
+		if instanceDiff.RequiresNew() && state.ID != "" {
+			if _, diags := r.Apply(ctx, state, &terraform.InstanceDiff{Destroy: true}, meta); diags.HasError() {
+				return state, diags
+			}
+			return applyMockResource(t, r, nil, config, meta)
+		}
+	}
+
+	newState, diags := r.Apply(ctx, state, instanceDiff, meta)
+	if newState != nil && newState.ID == "" {
+		newState = nil
+	}
+	if newState != nil {
+		for name, s := range r.SchemaMap() {
+			if s.WriteOnly {
+				delete(newState.Attributes, name)
+			}
+		}
+	}
+	return newState, diags
+}
+
+// refreshMockResource reads the resource like terraform does during refresh, the state is nil if the resource is gone
+func refreshMockResource(t *testing.T, r *schema.Resource, state *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, diag.Diagnostics) {
+	t.Helper()
+	newState, diags := r.RefreshWithoutUpgrade(context.Background(), state, meta)
+	if newState != nil && newState.ID == "" {
+		newState = nil
+	}
+	return newState, diags
+}
+
+func (b *mockBackend) newID() string {
+	b.nextID++
+	return strconv.Itoa(b.nextID)
+}
+
+func (b *mockBackend) ok(w http.ResponseWriter, r *http.Request) {
+	writeMockJSON(w, http.StatusOK, map[string]interface{}{})
+}
+
+func (b *mockBackend) whoami(w http.ResponseWriter, r *http.Request) {
+	writeMockJSON(w, http.StatusOK, map[string]interface{}{"tenant_id": "keep"})
+}
+
+func (b *mockBackend) getAvailableProviders(w http.ResponseWriter, r *http.Request) {
+	writeMockJSON(w, http.StatusOK, map[string]interface{}{
+		"providers": []KeepProvider{{
+			Type: "test",
+			Config: map[string]ProviderConfigField{
+				"host":  {Required: true},
+				"token": {Required: true, Sensitive: true},
+			},
+			SupportsWebhook: true,
+		}},
+	})
+}
+
+func (b *mockBackend) listProviders(w http.ResponseWriter, r *http.Request) {
+	providers := make([]KeepProvider, 0, len(b.providers))
+	for _, id := range sortedMockKeys(b.providers) {
+		providers = append(providers, b.providers[id])
+	}
+	writeMockJSON(w, http.StatusOK, providers)
+}
+
+// installProvider installs a provider like the backend, secrets of the auth config are returned masked
+func (b *mockBackend) installProvider(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]interface{}
+	if !readMockJSON(w, r, &payload) {
+		return
+	}
+
+	name, _ := payload["provider_name"].(string)
+	for _, provider := range b.providers {
+		if provider.Details.Name == name {
+			writeMockError(w, http.StatusConflict, fmt.Sprintf("Provider %s already installed", name))
+			return
+		}
+	}
+
+	provider := KeepProvider{ID: apiID(b.newID()), Type: fmt.Sprint(payload["provider_id"])}
+	b.applyProviderPayload(&provider, payload)
+	b.providers[string(provider.ID)] = provider
+	writeMockJSON(w, http.StatusOK, provider)
+}
+
+func (b *mockBackend) updateProvider(w http.ResponseWriter, r *http.Request) {
+	provider, ok := b.providers[r.PathValue("provider_id")]
+	if !ok {
+		writeMockError(w, http.StatusNotFound, "Provider not found")
+		return
+	}
+
+	var payload map[string]interface{}
+	if !readMockJSON(w, r, &payload) {
+		return
+	}
+	b.applyProviderPayload(&provider, payload)
+	b.providers[string(provider.ID)] = provider
+	writeMockJSON(w, http.StatusOK, provider)
+}
+
+func (b *mockBackend) applyProviderPayload(provider *KeepProvider, payload map[string]interface{}) {
+	authentication := make(map[string]interface{})
+	for key, value := range payload {
+		switch key {
+		case "provider_id":
+		case "provider_name":
+			provider.Details.Name = fmt.Sprint(value)
+		case "pulling_enabled":
+			enabled, _ := value.(bool)
+			provider.PullingEnabled = &enabled
+		case "pulling_interval":
+			interval := int(value.(float64))
+			provider.PullingInterval = &interval
+		case "token":
+			authentication[key] = "******"
+		default:
+			authentication[key] = value
+		}
+	}
+	provider.Details.Authentication = authentication
+	provider.SupportsWebhook = true
+}
+
+func (b *mockBackend) deleteProvider(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("provider_id")
+	if _, ok := b.providers[id]; !ok {
+		writeMockError(w, http.StatusNotFound, "Provider not found")
+		return
+	}
+	delete(b.providers, id)
+	delete(b.webhooks, id)
+	writeMockJSON(w, http.StatusOK, map[string]interface{}{})
+}
+
+func (b *mockBackend) validateScopes(w http.ResponseWriter, r *http.Request) {
+	writeMockJSON(w, http.StatusOK, map[string]interface{}{})
+}
+
+func (b *mockBackend) installWebhook(w http.ResponseWriter, r *http.Request) {
+	b.webhooks[r.PathValue("provider_id")] = true
+	writeMockJSON(w, http.StatusOK, map[string]interface{}{})
+}
+
+func (b *mockBackend) webhookSettings(w http.ResponseWriter, r *http.Request) {
+	writeMockJSON(w, http.StatusOK, WebhookSettings{WebhookAPI: b.URL + "/alerts/event", APIKey: "webhook-api-key"})
+}
+
+func (b *mockBackend) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]string
+	if !readMockJSON(w, r, &payload) {
+		return
+	}
+	if _, ok := b.apiKeyIDs[payload["name"]]; ok {
+		writeMockError(w, http.StatusConflict, "API key already exists")
+		return
+	}
+	// the reference id differs from the name like the one of the backend
+	apiKey := APIKey{ReferenceID: "key-" + b.newID(), Role: payload["role"], Secret: "secret-" + b.newID()}
+	b.apiKeys[apiKey.ReferenceID] = apiKey
+	b.apiKeyIDs[payload["name"]] = apiKey.ReferenceID
+	writeMockJSON(w, http.StatusOK, apiKey)
+}
+
+func (b *mockBackend) deleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("keyId")
+	if _, ok := b.apiKeys[id]; !ok {
+		writeMockError(w, http.StatusNotFound, "API key not found")
+		return
+	}
+	delete(b.apiKeys, id)
+	for name, keyID := range b.apiKeyIDs {
+		if keyID == id {
+			delete(b.apiKeyIDs, name)
+		}
+	}
+	writeMockJSON(w, http.StatusOK, map[string]interface{}{})
+}
+
+func (b *mockBackend) listWorkflows(w http.ResponseWriter, r *http.Request) {
+	workflows := make([]Workflow, 0, len(b.workflows))
+	for _, id := range sortedMockKeys(b.workflows) {
+		workflows = append(workflows, b.workflows[id])
+	}
+	writeMockJSON(w, http.StatusOK, workflows)
+}
+
+func (b *mockBackend) getWorkflow(w http.ResponseWriter, r *http.Request) {
+	workflow, ok := b.workflows[r.PathValue("workflow_id")]
+	if !ok {
+		writeMockError(w, http.StatusNotFound, "Workflow not found")
+		return
+	}
+	writeMockJSON(w, http.StatusOK, workflow)
+}
+
+// createWorkflow creates a workflow or, like the backend, adds a revision to the workflow with the same id
+func (b *mockBackend) createWorkflow(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Workflow map[string]interface{} `json:"workflow"`
+	}
+	if !readMockJSON(w, r, &payload) {
+		return
+	}
+
+	name, _ := payload.Workflow["name"].(string)
+	if name == "" {
+		writeMockError(w, http.StatusBadRequest, "workflow name is required")
+		return
+	}
+	id, _ := payload.Workflow["id"].(string)
+	if id == "" {
+		id = name
+	}
+	raw, err := yaml.Marshal(map[string]interface{}{"workflow": payload.Workflow})
+	if err != nil {
+		writeMockError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	status := "created"
+	workflow, exists := b.workflows[id]
+	if exists {
+		status = "updated"
+	}
+	description, _ := payload.Workflow["description"].(string)
+	workflow.ID, workflow.Name, workflow.Description = apiID(id), name, description
+	workflow.WorkflowRaw = string(raw)
+	workflow.Revision++
+	b.workflows[id] = workflow
+
+	writeMockJSON(w, http.StatusOK, WorkflowRevision{WorkflowID: apiID(id), Status: status, Revision: workflow.Revision})
+}
+
+func (b *mockBackend) deleteWorkflow(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("workflow_id")
+	if _, ok := b.workflows[id]; !ok {
+		writeMockError(w, http.StatusNotFound, "Workflow not found")
+		return
+	}
+	delete(b.workflows, id)
+	writeMockJSON(w, http.StatusOK, map[string]interface{}{})
+}
+
+func (b *mockBackend) writeWorkflowSecrets(w http.ResponseWriter, r *http.Request) {
+	var secrets map[string]string
+	if !readMockJSON(w, r, &secrets) {
+		return
+	}
+	id := r.PathValue("workflow_id")
+	if b.secrets[id] == nil {
+		b.secrets[id] = make(map[string]string)
+	}
+	for name, secret := range secrets {
+		b.secrets[id][name] = secret
+	}
+	writeMockJSON(w, http.StatusOK, map[string]interface{}{})
+}
+
+func (b *mockBackend) deleteWorkflowSecret(w http.ResponseWriter, r *http.Request) {
+	id, name := r.PathValue("workflow_id"), r.PathValue("secret_name")
+	if _, ok := b.secrets[id][name]; !ok {
+		writeMockError(w, http.StatusNotFound, "Secret not found")
+		return
+	}
+	delete(b.secrets[id], name)
+	writeMockJSON(w, http.StatusOK, map[string]interface{}{})
+}
+
+func (b *mockBackend) listMappings(w http.ResponseWriter, r *http.Request) {
+	mappings := make([]Mapping, 0, len(b.mappings))
+	for _, id := range sortedMockKeys(b.mappings) {
+		mapping := b.mappings[id]
+		mapping.Rows = nil
+		mappings = append(mappings, mapping)
+	}
+	writeMockJSON(w, http.StatusOK, mappings)
+}
+
+func (b *mockBackend) getMapping(w http.ResponseWriter, r *http.Request) {
+	mapping, ok := b.mappings[r.PathValue("rule_id")]
+	if !ok {
+		writeMockError(w, http.StatusNotFound, "Mapping not found")
+		return
+	}
+	writeMockJSON(w, http.StatusOK, mapping)
+}
+
+func (b *mockBackend) createMapping(w http.ResponseWriter, r *http.Request) {
+	var mapping Mapping
+	if !readMockJSON(w, r, &mapping) {
+		return
+	}
+
+	mapping.ID = apiID(b.newID())
+	mapping.CreatedBy = "keep"
+	if len(mapping.Rows) > 0 {
+		mapping.Attributes = make([]string, 0)
+		for column := range mapping.Rows[0] {
+			if !mappingMatchesColumn(mapping.Matchers, column) {
+				mapping.Attributes = append(mapping.Attributes, column)
+			}
+		}
+		sort.Strings(mapping.Attributes)
+	}
+	b.mappings[string(mapping.ID)] = mapping
+	writeMockJSON(w, http.StatusOK, mapping)
+}
+
+func mappingMatchesColumn(matchers []MappingMatcher, column string) bool {
+	for _, matcher := range matchers {
+		for _, attribute := range matcher {
+			if attribute == column {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (b *mockBackend) deleteMapping(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("rule_id")
+	if _, ok := b.mappings[id]; !ok {
+		writeMockError(w, http.StatusNotFound, "Mapping not found")
+		return
+	}
+	delete(b.mappings, id)
+	writeMockJSON(w, http.StatusOK, map[string]interface{}{})
+}
+
+func (b *mockBackend) listExtractions(w http.ResponseWriter, r *http.Request) {
+	extractions := make([]Extraction, 0, len(b.extractions))
+	for _, id := range sortedMockKeys(b.extractions) {
+		extractions = append(extractions, b.extractions[id])
+	}
+	writeMockJSON(w, http.StatusOK, extractions)
+}
+
+func (b *mockBackend) createExtraction(w http.ResponseWriter, r *http.Request) {
+	var extraction Extraction
+	if !readMockJSON(w, r, &extraction) {
+		return
+	}
+
+	extraction.ID = apiID(b.newID())
+	extraction.CreatedBy = "keep"
+	b.extractions[string(extraction.ID)] = extraction
+	writeMockJSON(w, http.StatusOK, extraction)
+}
+
+func (b *mockBackend) updateExtraction(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("rule_id")
+	current, ok := b.extractions[id]
+	if !ok {
+		writeMockError(w, http.StatusNotFound, "Extraction not found")
+		return
+	}
+
+	var extraction Extraction
+	if !readMockJSON(w, r, &extraction) {
+		return
+	}
+	extraction.ID, extraction.CreatedBy, extraction.UpdatedBy = current.ID, current.CreatedBy, "keep"
+	b.extractions[id] = extraction
+	writeMockJSON(w, http.StatusOK, extraction)
+}
+
+func (b *mockBackend) deleteExtraction(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("rule_id")
+	if _, ok := b.extractions[id]; !ok {
+		writeMockError(w, http.StatusNotFound, "Extraction not found")
+		return
+	}
+	delete(b.extractions, id)
+	writeMockJSON(w, http.StatusOK, map[string]interface{}{})
+}
+
+func (b *mockBackend) alertFields(w http.ResponseWriter, r *http.Request) {
+	writeMockJSON(w, http.StatusOK, []string{"name", "message", "labels.service", "source"})
+}
+
+func readMockJSON(w http.ResponseWriter, r *http.Request, out interface{}) bool {
+	body, err := io.ReadAll(r.Body)
+	if err == nil {
+		err = json.Unmarshal(body, out)
+	}
+	if err != nil {
+		writeMockError(w, http.StatusUnprocessableEntity, err.Error())
+		return false
+	}
+	return true
+}
+
+func writeMockJSON(w http.ResponseWriter, statusCode int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeMockError(w http.ResponseWriter, statusCode int, detail string) {
+	writeMockJSON(w, statusCode, map[string]string{"detail": detail})
+}
+
+func sortedMockKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, errA := strconv.Atoi(keys[i])
+		b, errB := strconv.Atoi(keys[j])
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
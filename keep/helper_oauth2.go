@@ -0,0 +1,105 @@
+package keep
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// oauth2TokenExpiryLeeway refreshes tokens before they expire, so requests in flight do not fail
+const oauth2TokenExpiryLeeway = 30 * time.Second
+
+// Ensure oauth2TokenSource implements credentialSource interface
+var _ credentialSource = &oauth2TokenSource{}
+
+// oauth2TokenSource fetches access tokens with the OAuth2 client credentials flow and caches them until they expire
+type oauth2TokenSource struct {
+	HTTPClient   *http.Client
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// Token returns the cached access token or fetches a new one if it is expired
+func (s *oauth2TokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && (s.expiry.IsZero() || time.Now().Before(s.expiry)) {
+		return s.token, nil
+	}
+
+	token, expiresIn, err := s.fetchToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	s.token = token
+	s.expiry = time.Time{}
+	if expiresIn > 0 {
+		s.expiry = time.Now().Add(time.Duration(expiresIn)*time.Second - oauth2TokenExpiryLeeway)
+	}
+	return s.token, nil
+}
+
+// Invalidate drops the cached access token, e.g. after it was rejected by the backend
+func (s *oauth2TokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = ""
+}
+
+// fetchToken requests a new access token from the token url
+func (s *oauth2TokenSource) fetchToken(ctx context.Context) (string, int64, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(s.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create token request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(s.ClientID), url.QueryEscape(s.ClientSecret))
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("token request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read token response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return "", 0, fmt.Errorf("failed to parse token response: %v", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return "", 0, fmt.Errorf("token response contains no access_token")
+	}
+
+	return tokenResp.AccessToken, tokenResp.ExpiresIn, nil
+}
@@ -0,0 +1,142 @@
+package keep
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// listPageSize is the number of items requested per page of list endpoints
+var listPageSize = 100
+
+// maxListPages bounds the pages of a list request, so a backend repeating its pages can't loop forever
+const maxListPages = 1000
+
+// listPage is a page of a paginated list endpoint. Depending on the version, backends return the items
+// as "items" or "results" and the next page as cursor, as link or implicitly by the total number of items.
+type listPage struct {
+	Items      []json.RawMessage `json:"items"`
+	Results    []json.RawMessage `json:"results"`
+	NextCursor string            `json:"next_cursor"`
+	Next       string            `json:"next"`
+	Total      *int              `json:"total"`
+	Count      *int              `json:"count"`
+}
+
+// getAllPages decodes the items of all pages of a list endpoint into out, which must point to a slice.
+// The items are taken from the list cache of the client if it has one.
+func (c *Client) getAllPages(ctx context.Context, path string, out interface{}) (*ErrorResponse, error) {
+	return c.getAllFilteredPages(ctx, path, nil, out)
+}
+
+// getAllFilteredPages is getAllPages with filter parameters sent with every page, e.g. name=<name>
+func (c *Client) getAllFilteredPages(ctx context.Context, path string, filter url.Values, out interface{}) (*ErrorResponse, error) {
+	key := c.TenantID + " " + path
+	if len(filter) > 0 {
+		key += "?" + filter.Encode()
+	}
+	content, errResp, err := c.listCache.get(key, func() ([]byte, *ErrorResponse, error) {
+		return c.fetchAllPages(ctx, path, filter)
+	})
+	if err != nil {
+		return errResp, err
+	}
+	return nil, json.Unmarshal(content, out)
+}
+
+// fetchAllPages requests all pages of a list endpoint and returns their items as JSON list. Backends without
+// pagination return a plain list, which is requested with limit and offset as well, since backends which
+// paginate plain lists return at most limit items.
+func (c *Client) fetchAllPages(ctx context.Context, path string, filter url.Values) ([]byte, *ErrorResponse, error) {
+	query := url.Values{}
+	for name, values := range filter {
+		query[name] = values
+	}
+	query.Set("limit", strconv.Itoa(listPageSize))
+	query.Set("offset", "0")
+	next := c.endpoint(path) + "?" + query.Encode()
+
+	items := make([]json.RawMessage, 0)
+	var previous []json.RawMessage
+	for page := 0; next != ""; page++ {
+		if page >= maxListPages {
+			return nil, nil, fmt.Errorf("%s returned more than %d pages", path, maxListPages)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "GET", next, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		body, errResp, err := c.doReq(req)
+		if err != nil {
+			return nil, errResp, err
+		}
+
+		var pageItems []json.RawMessage
+		next = ""
+		if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+			if err := json.Unmarshal(body, &pageItems); err != nil {
+				return nil, nil, err
+			}
+			// a backend ignoring offset returns the same page again
+			if len(previous) > 0 && len(pageItems) > 0 && bytes.Equal(previous[0], pageItems[0]) {
+				break
+			}
+			if len(pageItems) >= listPageSize {
+				next = nextOffsetPage(req.URL, len(items)+len(pageItems))
+			}
+		} else {
+			var p listPage
+			if err := json.Unmarshal(body, &p); err != nil {
+				return nil, nil, err
+			}
+			pageItems = p.Items
+			if pageItems == nil {
+				pageItems = p.Results
+			}
+
+			total := p.Total
+			if total == nil {
+				total = p.Count
+			}
+			switch {
+			case len(pageItems) == 0:
+			case p.NextCursor != "":
+				nextURL := *req.URL
+				nextQuery := nextURL.Query()
+				nextQuery.Del("offset")
+				nextQuery.Set("cursor", p.NextCursor)
+				nextURL.RawQuery = nextQuery.Encode()
+				next = nextURL.String()
+			case p.Next != "":
+				link, err := req.URL.Parse(p.Next)
+				if err != nil {
+					return nil, nil, fmt.Errorf("invalid link to the next page of %s: %v", path, err)
+				}
+				next = link.String()
+			case total != nil && len(items)+len(pageItems) < *total:
+				next = nextOffsetPage(req.URL, len(items)+len(pageItems))
+			}
+		}
+
+		items = append(items, pageItems...)
+		previous = pageItems
+	}
+
+	content, err := json.Marshal(items)
+	return content, nil, err
+}
+
+// nextOffsetPage returns the url of the page starting at offset
+func nextOffsetPage(current *url.URL, offset int) string {
+	next := *current
+	query := next.Query()
+	query.Set("offset", strconv.Itoa(offset))
+	next.RawQuery = query.Encode()
+	return next.String()
+}
@@ -0,0 +1,52 @@
+package keep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+)
+
+// errNotVisible is the error of waitUntilVisible while the created object is not returned by the backend
+var errNotVisible = errors.New("not returned by the backend yet")
+
+// defaultReadinessTimeout bounds the wait for created objects, see readiness_timeout of the provider
+const defaultReadinessTimeout = 30 * time.Second
+
+// readinessTimeout returns how long to wait until the backend returns created objects
+func readinessTimeout(client KeepClient) time.Duration {
+	if c, ok := client.(*Client); ok && c.ReadinessTimeout > 0 {
+		return c.ReadinessTimeout
+	}
+	return defaultReadinessTimeout
+}
+
+// waitUntilVisible polls with backoff after an object was created until the backend returns it, since reads of the
+// eventually consistent backend can miss objects right after they were created, which would drop them from state.
+// Failed requests end the wait, unless they failed with ErrTransient. Cached lists are dropped before every poll,
+// since a list cached before the object was visible would hide it for longer than the wait.
+func waitUntilVisible(ctx context.Context, client KeepClient, kind, id string, visible func() (bool, error)) error {
+	timeout := readinessTimeout(client)
+	err := retry.RetryContext(ctx, timeout, func() *retry.RetryError {
+		if c, ok := client.(*Client); ok {
+			c.listCache.invalidate()
+		}
+		ok, err := visible()
+		switch {
+		case err != nil && errors.Is(err, ErrTransient):
+			return retry.RetryableError(err)
+		case err != nil:
+			return retry.NonRetryableError(err)
+		case !ok:
+			return retry.RetryableError(errNotVisible)
+		}
+		return nil
+	})
+
+	if errors.Is(err, errNotVisible) {
+		return fmt.Errorf("%s %s was created, but is not returned by the backend after %s. Raise readiness_timeout of the provider to wait longer", kind, id, timeout)
+	}
+	return err
+}
@@ -0,0 +1,33 @@
+package keep
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+)
+
+// transientRetryTimeout limits how long requests failing with a transient status code are retried
+var transientRetryTimeout = 2 * time.Minute
+
+// retryTransient calls f with backoff until it succeeds, fails with an error other than ErrTransient,
+// e.g. ErrUnauthorized, or the retry timeout is reached.
+// The error of the last call is returned.
+func retryTransient(ctx context.Context, f func() error) error {
+	var lastErr error
+	err := retry.RetryContext(ctx, transientRetryTimeout, func() *retry.RetryError {
+		lastErr = f()
+		if lastErr == nil {
+			return nil
+		}
+		if errors.Is(lastErr, ErrTransient) {
+			return retry.RetryableError(lastErr)
+		}
+		return retry.NonRetryableError(lastErr)
+	})
+	if err != nil && lastErr != nil {
+		return lastErr
+	}
+	return err
+}
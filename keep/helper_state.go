@@ -0,0 +1,25 @@
+package keep
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// setAttributes sets the attributes of the resource by name. They are set in the order of their names, so the
+// same attribute is reported if several values don't match the schema.
+func setAttributes(d *schema.ResourceData, values map[string]interface{}) error {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := d.Set(name, values[name]); err != nil {
+			return fmt.Errorf("Failed to set %s: %s", name, err.Error())
+		}
+	}
+	return nil
+}
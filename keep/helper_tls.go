@@ -0,0 +1,57 @@
+package keep
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// buildTLSConfig builds the TLS configuration of the http client from the provider settings,
+// nil means the defaults of the http client are used
+func buildTLSConfig(d *schema.ResourceData) (*tls.Config, error) {
+	caCertPEM := d.Get("ca_cert_pem").(string)
+	if caCertFile := d.Get("ca_cert_file").(string); caCertFile != "" {
+		content, err := os.ReadFile(caCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read ca_cert_file: %s", err)
+		}
+		caCertPEM = string(content)
+	}
+
+	clientCertPEM := d.Get("client_cert_pem").(string)
+	clientKeyPEM := d.Get("client_key_pem").(string)
+
+	insecureSkipVerify := d.Get("insecure_skip_verify").(bool)
+	if caCertPEM == "" && clientCertPEM == "" && !insecureSkipVerify {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: insecureSkipVerify,
+	}
+
+	if caCertPEM != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(caCertPEM)) {
+			return nil, fmt.Errorf("no valid PEM certificate found in the CA certificate")
+		}
+		config.RootCAs = pool
+	}
+
+	if clientCertPEM != "" {
+		cert, err := tls.X509KeyPair([]byte(clientCertPEM), []byte(clientKeyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("cannot load client certificate: %s", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
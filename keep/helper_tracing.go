@@ -0,0 +1,92 @@
+package keep
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the spans of API calls
+const tracerName = "github.com/justtrackio/terraform-provider-keep/keep"
+
+var (
+	tracerProviderMu sync.Mutex
+	tracerProvider   *sdktrace.TracerProvider
+)
+
+// newTracer returns the tracer of API calls. Spans are exported with OTLP over HTTP, configured by the
+// OTEL_EXPORTER_OTLP_* environment variables. The exporter is shared by all provider instances of the process.
+func newTracer(ctx context.Context) (trace.Tracer, error) {
+	tracerProviderMu.Lock()
+	defer tracerProviderMu.Unlock()
+
+	if tracerProvider == nil {
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tracerProvider = sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(sdkresource.NewSchemaless(attribute.String("service.name", "terraform-provider-keep"))),
+		)
+	}
+	return tracerProvider.Tracer(tracerName), nil
+}
+
+// ShutdownTracing exports the spans which haven't been exported yet, it is called before the provider exits
+func ShutdownTracing(ctx context.Context) error {
+	tracerProviderMu.Lock()
+	defer tracerProviderMu.Unlock()
+
+	if tracerProvider == nil {
+		return nil
+	}
+	err := tracerProvider.Shutdown(ctx)
+	tracerProvider = nil
+	return err
+}
+
+// startSpan starts the span of an API call including all its attempts. The trace context is sent to the
+// backend, so its spans are part of the trace.
+func (c *Client) startSpan(req *http.Request) (*http.Request, trace.Span) {
+	ctx, span := c.Tracer.Start(req.Context(), req.Method+" "+req.URL.Path,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.path", req.URL.Path),
+			attribute.String("server.address", req.URL.Host),
+			attribute.String("keep.request_id", req.Header.Get(requestIDHeader)),
+		),
+	)
+	propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	return req.WithContext(ctx), span
+}
+
+// recordAttempt adds the status code of an attempt to the span of the API call, 0 if no response was received
+func recordAttempt(ctx context.Context, attempt, statusCode int) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	span.SetAttributes(attribute.Int("http.request.resend_count", attempt))
+	if statusCode != 0 {
+		span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+	}
+}
+
+// endSpan ends the span of an API call with the error of the call
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
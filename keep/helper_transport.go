@@ -0,0 +1,100 @@
+package keep
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// transportMaxIdleConnsPerHost keeps enough idle connections for the parallel operations of terraform, which
+	// all go to the same backend, the default of the http package is 2
+	transportMaxIdleConnsPerHost = 32
+	transportMaxIdleConns        = 100
+	transportIdleConnTimeout     = 90 * time.Second
+	transportKeepAlive           = 30 * time.Second
+	transportDialTimeout         = 30 * time.Second
+	transportTLSHandshakeTimeout = 10 * time.Second
+)
+
+var (
+	sharedTransportOnce sync.Once
+	sharedTransport     *http.Transport
+)
+
+// transportOptions configures the connections to the backend. The zero value of a field uses the tuned default.
+type transportOptions struct {
+	TLSConfig           *tls.Config
+	ProxyURL            *url.URL
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	TLSHandshakeTimeout time.Duration
+	DisableHTTP2        bool
+}
+
+// isDefault reports whether the options configure the same connections as the shared transport
+func (o transportOptions) isDefault() bool {
+	return o.TLSConfig == nil && o.ProxyURL == nil && !o.DisableHTTP2 &&
+		(o.MaxIdleConnsPerHost == 0 || o.MaxIdleConnsPerHost == transportMaxIdleConnsPerHost) &&
+		(o.IdleConnTimeout == 0 || o.IdleConnTimeout == transportIdleConnTimeout) &&
+		(o.TLSHandshakeTimeout == 0 || o.TLSHandshakeTimeout == transportTLSHandshakeTimeout)
+}
+
+// defaultTransport returns the transport shared by all clients without own TLS configuration or proxy,
+// so provider instances, e.g. of aliased providers, reuse each other's connections
+func defaultTransport() *http.Transport {
+	sharedTransportOnce.Do(func() {
+		sharedTransport = tunedTransport()
+	})
+	return sharedTransport
+}
+
+// tunedTransport returns a copy of the default transport with connection pool sizes and keep-alives
+// suited for many requests to a single backend
+func tunedTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   transportDialTimeout,
+		KeepAlive: transportKeepAlive,
+	}).DialContext
+	transport.MaxIdleConns = transportMaxIdleConns
+	transport.MaxIdleConnsPerHost = transportMaxIdleConnsPerHost
+	transport.IdleConnTimeout = transportIdleConnTimeout
+	transport.TLSHandshakeTimeout = transportTLSHandshakeTimeout
+	return transport
+}
+
+// newTransport returns the shared transport, or a tuned transport of its own if the options differ from the defaults.
+// Without a proxy url the proxy is taken from the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.
+func newTransport(opts transportOptions) *http.Transport {
+	if opts.isDefault() {
+		return defaultTransport()
+	}
+
+	transport := tunedTransport()
+	if opts.TLSConfig != nil {
+		transport.TLSClientConfig = opts.TLSConfig
+	}
+	if opts.ProxyURL != nil {
+		transport.Proxy = http.ProxyURL(opts.ProxyURL)
+	}
+	if opts.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+		transport.MaxIdleConns = max(transportMaxIdleConns, opts.MaxIdleConnsPerHost)
+	}
+	if opts.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = opts.IdleConnTimeout
+	}
+	if opts.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = opts.TLSHandshakeTimeout
+	}
+	if opts.DisableHTTP2 {
+		// a non-nil empty map disables the upgrade to HTTP/2 during the TLS handshake
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return transport
+}
@@ -0,0 +1,109 @@
+package keep
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// newFileUploadRequest returns a request uploading the file as the "file" field of a multipart form. The body
+// is streamed from the file through a pipe, so large files aren't held in memory, and every retry streams
+// the file again.
+func (c *Client) newFileUploadRequest(ctx context.Context, method, path, filePath string) (*http.Request, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// the envelope of the file content is known upfront, so the request has a content length
+	// instead of being sent chunked, which some gateways reject
+	var envelope bytes.Buffer
+	writer := multipart.NewWriter(&envelope)
+	if _, err := writer.CreateFormFile("file", filePath); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	body := func() (io.ReadCloser, error) {
+		return &fileUploadBody{filePath: filePath, boundary: writer.Boundary()}, nil
+	}
+	initialBody, _ := body()
+
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint(path), initialBody)
+	if err != nil {
+		return nil, err
+	}
+	req.GetBody = body
+	req.ContentLength = int64(envelope.Len()) + info.Size()
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return req, nil
+}
+
+// fileUploadBody streams a file as multipart form. The file is opened on the first read, so bodies
+// of requests which are never sent, e.g. because the backend is unreachable, don't leak the writing goroutine.
+type fileUploadBody struct {
+	filePath string
+	boundary string
+
+	mu     sync.Mutex
+	reader *io.PipeReader
+	closed bool
+}
+
+func (b *fileUploadBody) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return 0, io.ErrClosedPipe
+	}
+	if b.reader == nil {
+		var writer *io.PipeWriter
+		b.reader, writer = io.Pipe()
+		go func() {
+			writer.CloseWithError(b.write(writer))
+		}()
+	}
+	reader := b.reader
+	b.mu.Unlock()
+
+	return reader.Read(p)
+}
+
+func (b *fileUploadBody) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	if b.reader != nil {
+		return b.reader.Close()
+	}
+	return nil
+}
+
+// write writes the multipart form with the content of the file
+func (b *fileUploadBody) write(w io.Writer) error {
+	file, err := os.Open(b.filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := multipart.NewWriter(w)
+	if err := writer.SetBoundary(b.boundary); err != nil {
+		return err
+	}
+	part, err := writer.CreateFormFile("file", b.filePath)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return err
+	}
+	return writer.Close()
+}
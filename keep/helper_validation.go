@@ -0,0 +1,76 @@
+package keep
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// validateDuration is a ValidateDiagFunc for attributes with a positive duration, e.g. "30s" or "5m".
+// Empty values are left to the defaults of the attribute.
+func validateDuration(v interface{}, path cty.Path) diag.Diagnostics {
+	value, ok := v.(string)
+	if !ok {
+		return diag.Errorf("expected duration to be a string")
+	}
+	if value == "" {
+		return nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return attributeErrorf(path, "%q is not a valid duration, e.g. 30s or 5m: %s", value, err)
+	}
+	if duration <= 0 {
+		return attributeErrorf(path, "duration %q must be positive", value)
+	}
+	return nil
+}
+
+// validateMatcher is a ValidateDiagFunc for matchers of mappings, which are CSV columns joined by " && "
+func validateMatcher(v interface{}, path cty.Path) diag.Diagnostics {
+	matcher, ok := v.(string)
+	if !ok {
+		return diag.Errorf("expected matcher to be a string")
+	}
+
+	for _, column := range strings.Split(matcher, " && ") {
+		if strings.TrimSpace(column) == "" || strings.TrimSpace(column) != column || strings.Contains(column, "&&") {
+			return attributeErrorf(path, "matcher %q must be CSV column names joined by \" && \", e.g. \"alert_name && severity\"", matcher)
+		}
+	}
+	return nil
+}
+
+// validateAuthConfigURLs is a ValidateDiagFunc for the auth config of providers. Values of keys named url or
+// ending with _url, e.g. host_url or webhook_url, must be absolute URLs, unless they reference a secret.
+func validateAuthConfigURLs(v interface{}, path cty.Path) diag.Diagnostics {
+	authConfig, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	keys := make([]string, 0, len(authConfig))
+	for key := range authConfig {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var diags diag.Diagnostics
+	for _, key := range keys {
+		value, ok := authConfig[key].(string)
+		lowerKey := strings.ToLower(key)
+		if !ok || value == "" || isCredentialReference(value) || (lowerKey != "url" && !strings.HasSuffix(lowerKey, "_url")) {
+			continue
+		}
+
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+			diags = append(diags, attributeErrorf(path.IndexString(key), "%s must be an absolute URL, e.g. https://example.com, got %q", key, value)...)
+		}
+	}
+	return diags
+}
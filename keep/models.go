@@ -0,0 +1,188 @@
+package keep
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Models of endpoints which aren't managed by resources yet are generated from the OpenAPI document of the backend
+//go:generate go run ../tools/openapi-models -spec ../openapi.json -out models_openapi.go -schemas DeduplicationRuleRequestDto,IncidentDto,IncidentDtoIn,IncidentsPaginatedResultsDto,TopologyApplicationDtoIn,TopologyApplicationDtoOut,TopologyServiceCreateRequestDTO,TopologyServiceDependencyCreateRequestDto,TopologyServiceDtoOut,TopologyServiceUpdateRequestDTO
+
+// apiID is the id of a backend object. Ids of some objects are numbers, e.g. of mappings and extractions,
+// and of others strings, so both are accepted and kept as string.
+type apiID string
+
+func (id *apiID) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*id = ""
+		return nil
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case string:
+		*id = apiID(v)
+	case json.Number:
+		*id = apiID(v.String())
+	default:
+		return fmt.Errorf("id must be a string or a number, got %s", string(data))
+	}
+	return nil
+}
+
+// KeepProvider is a provider type available in the backend or an installed provider
+type KeepProvider struct {
+	ID                apiID                          `json:"id"`
+	Type              string                         `json:"type"`
+	Details           ProviderDetails                `json:"details"`
+	Config            map[string]ProviderConfigField `json:"config"`
+	PullingEnabled    *bool                          `json:"pulling_enabled"`
+	PullingInterval   *int                           `json:"pulling_interval"`
+	LastAlertReceived string                         `json:"last_alert_received"`
+	SupportsWebhook   bool                           `json:"supports_webhook"`
+	WebhookRequired   bool                           `json:"webhook_required"`
+	InstalledBy       string                         `json:"installed_by"`
+	InstallationTime  string                         `json:"installation_time"`
+	LastPullTime      string                         `json:"last_pull_time"`
+	Scopes            []ProviderScope                `json:"scopes"`
+	// ValidatedScopes are true for granted scopes and an error message otherwise
+	ValidatedScopes map[string]interface{} `json:"validatedScopes"`
+}
+
+// ProviderDetails are the name and the masked authentication config of an installed provider
+type ProviderDetails struct {
+	Name           string                 `json:"name"`
+	Authentication map[string]interface{} `json:"authentication"`
+}
+
+// ProviderConfigField is a key of the config schema of a provider type
+type ProviderConfigField struct {
+	Required    bool   `json:"required"`
+	Sensitive   bool   `json:"sensitive"`
+	Description string `json:"description"`
+}
+
+// ProviderScope is a permission a provider needs in the source system
+type ProviderScope struct {
+	Name                string `json:"name"`
+	Description         string `json:"description"`
+	Mandatory           bool   `json:"mandatory"`
+	MandatoryForWebhook bool   `json:"mandatory_for_webhook"`
+}
+
+// WebhookSettings are the webhook endpoint and api key providers push alerts with
+type WebhookSettings struct {
+	WebhookAPI string `json:"webhookApi"`
+	APIKey     string `json:"apiKey"`
+}
+
+// APIKey is an api key of the tenant, the backend returns its secret only when it is created
+type APIKey struct {
+	ReferenceID string `json:"reference_id"`
+	Role        string `json:"role"`
+	Secret      string `json:"secret"`
+}
+
+// Workflow is a workflow of the backend
+type Workflow struct {
+	ID                  apiID           `json:"id"`
+	Name                string          `json:"name"`
+	Description         string          `json:"description"`
+	CreatedBy           string          `json:"created_by"`
+	CreationTime        string          `json:"creation_time"`
+	Triggers            json.RawMessage `json:"triggers"`
+	Interval            int             `json:"interval"`
+	LastExecutionTime   string          `json:"last_execution_time"`
+	LastExecutionStatus string          `json:"last_execution_status"`
+	Providers           json.RawMessage `json:"providers"`
+	WorkflowRawID       string          `json:"workflow_raw_id"`
+	WorkflowRaw         string          `json:"workflow_raw"`
+	Revision            int             `json:"revision"`
+	LastUpdated         string          `json:"last_updated"`
+	Invalid             bool            `json:"invalid"`
+}
+
+// WorkflowRevision is the response of creating or updating a workflow
+type WorkflowRevision struct {
+	WorkflowID apiID  `json:"workflow_id"`
+	Status     string `json:"status"`
+	Revision   int    `json:"revision"`
+}
+
+// Mapping is a mapping rule enriching alerts with the rows of a CSV file
+type Mapping struct {
+	ID          apiID               `json:"id,omitempty"`
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	FileName    string              `json:"file_name"`
+	Priority    int                 `json:"priority"`
+	Matchers    []MappingMatcher    `json:"matchers"`
+	Override    *bool               `json:"override,omitempty"`
+	Rows        []map[string]string `json:"rows,omitempty"`
+	Attributes  []string            `json:"attributes,omitempty"`
+	CreatedAt   string              `json:"created_at,omitempty"`
+	CreatedBy   string              `json:"created_by,omitempty"`
+}
+
+// MappingMatcher are the attributes which all have to match for a row to apply, written as "a && b" in terraform
+type MappingMatcher []string
+
+// String returns the matcher as written in terraform
+func (m MappingMatcher) String() string {
+	return strings.Join(m, " && ")
+}
+
+// UnmarshalJSON accepts matchers as list of attributes and, like older backends return them, as single string
+func (m *MappingMatcher) UnmarshalJSON(data []byte) error {
+	var matcher string
+	if err := json.Unmarshal(data, &matcher); err == nil {
+		*m = strings.Split(matcher, " && ")
+		return nil
+	}
+
+	var attributes []string
+	if err := json.Unmarshal(data, &attributes); err != nil {
+		return err
+	}
+	*m = attributes
+	return nil
+}
+
+// Extraction is an extraction rule applying a regex to an attribute of alerts
+type Extraction struct {
+	ID          apiID  `json:"id,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Priority    int    `json:"priority"`
+	Attribute   string `json:"attribute"`
+	Condition   string `json:"condition"`
+	Disabled    bool   `json:"disabled"`
+	Regex       string `json:"regex"`
+	Pre         bool   `json:"pre"`
+	CreatedAt   string `json:"created_at,omitempty"`
+	CreatedBy   string `json:"created_by,omitempty"`
+	UpdatedAt   string `json:"updated_at,omitempty"`
+	UpdatedBy   string `json:"updated_by,omitempty"`
+}
+
+// Payload returns the extraction without the attributes set by the backend, as sent on create and update
+func (e Extraction) Payload() Extraction {
+	return Extraction{
+		Name:        e.Name,
+		Description: e.Description,
+		Priority:    e.Priority,
+		Attribute:   e.Attribute,
+		Condition:   e.Condition,
+		Disabled:    e.Disabled,
+		Regex:       e.Regex,
+		Pre:         e.Pre,
+	}
+}
@@ -0,0 +1,194 @@
+// Code generated by tools/openapi-models -schemas DeduplicationRuleRequestDto,IncidentDto,IncidentDtoIn,IncidentsPaginatedResultsDto,TopologyApplicationDtoIn,TopologyApplicationDtoOut,TopologyServiceCreateRequestDTO,TopologyServiceDependencyCreateRequestDto,TopologyServiceDtoOut,TopologyServiceUpdateRequestDTO; DO NOT EDIT.
+
+package keep
+
+// DeduplicationRuleRequestDto is the DeduplicationRuleRequestDto schema of the Keep API
+type DeduplicationRuleRequestDto struct {
+	Description       string   `json:"description,omitempty"`
+	FingerprintFields []string `json:"fingerprint_fields"`
+	FullDeduplication bool     `json:"full_deduplication,omitempty"`
+	IgnoreFields      []string `json:"ignore_fields,omitempty"`
+	Name              string   `json:"name"`
+	ProviderID        string   `json:"provider_id,omitempty"`
+	ProviderType      string   `json:"provider_type"`
+}
+
+// IncidentDto is the IncidentDto schema of the Keep API
+type IncidentDto struct {
+	AIGeneratedName      string                 `json:"ai_generated_name,omitempty"`
+	AlertSources         []string               `json:"alert_sources"`
+	AlertsCount          int                    `json:"alerts_count"`
+	Assignee             string                 `json:"assignee,omitempty"`
+	CreationTime         string                 `json:"creation_time,omitempty"`
+	EndTime              string                 `json:"end_time,omitempty"`
+	Enrichments          map[string]interface{} `json:"enrichments,omitempty"`
+	Fingerprint          string                 `json:"fingerprint,omitempty"`
+	GeneratedSummary     string                 `json:"generated_summary,omitempty"`
+	ID                   string                 `json:"id"`
+	IncidentApplication  string                 `json:"incident_application,omitempty"`
+	IncidentType         string                 `json:"incident_type,omitempty"`
+	IsCandidate          bool                   `json:"is_candidate"`
+	IsPredicted          bool                   `json:"is_predicted"`
+	LastSeenTime         string                 `json:"last_seen_time,omitempty"`
+	MergedAt             string                 `json:"merged_at,omitempty"`
+	MergedBy             string                 `json:"merged_by,omitempty"`
+	MergedIntoIncidentID string                 `json:"merged_into_incident_id,omitempty"`
+	// Resolution strategy for the incident
+	ResolveOn               string           `json:"resolve_on,omitempty"`
+	RuleFingerprint         string           `json:"rule_fingerprint,omitempty"`
+	RuleID                  string           `json:"rule_id,omitempty"`
+	RuleIsDeleted           bool             `json:"rule_is_deleted,omitempty"`
+	RuleName                string           `json:"rule_name,omitempty"`
+	SameIncidentInThePastID string           `json:"same_incident_in_the_past_id,omitempty"`
+	Services                []string         `json:"services"`
+	Severity                IncidentSeverity `json:"severity,omitempty"`
+	StartTime               string           `json:"start_time,omitempty"`
+	Status                  IncidentStatus   `json:"status,omitempty"`
+	UserGeneratedName       string           `json:"user_generated_name,omitempty"`
+	UserSummary             string           `json:"user_summary,omitempty"`
+}
+
+// IncidentDtoIn is the IncidentDtoIn schema of the Keep API
+type IncidentDtoIn struct {
+	Assignee                string           `json:"assignee,omitempty"`
+	SameIncidentInThePastID string           `json:"same_incident_in_the_past_id,omitempty"`
+	Severity                IncidentSeverity `json:"severity,omitempty"`
+	UserGeneratedName       string           `json:"user_generated_name,omitempty"`
+	UserSummary             string           `json:"user_summary,omitempty"`
+}
+
+// IncidentSeverity is the IncidentSeverity schema of the Keep API
+type IncidentSeverity string
+
+const (
+	IncidentSeverityCritical IncidentSeverity = "critical"
+	IncidentSeverityHigh     IncidentSeverity = "high"
+	IncidentSeverityWarning  IncidentSeverity = "warning"
+	IncidentSeverityInfo     IncidentSeverity = "info"
+	IncidentSeverityLow      IncidentSeverity = "low"
+)
+
+// IncidentStatus is the IncidentStatus schema of the Keep API
+type IncidentStatus string
+
+const (
+	IncidentStatusFiring       IncidentStatus = "firing"
+	IncidentStatusResolved     IncidentStatus = "resolved"
+	IncidentStatusAcknowledged IncidentStatus = "acknowledged"
+	IncidentStatusMerged       IncidentStatus = "merged"
+	IncidentStatusDeleted      IncidentStatus = "deleted"
+)
+
+// IncidentsPaginatedResultsDto is the IncidentsPaginatedResultsDto schema of the Keep API
+type IncidentsPaginatedResultsDto struct {
+	Count  int           `json:"count"`
+	Items  []IncidentDto `json:"items"`
+	Limit  int           `json:"limit,omitempty"`
+	Offset int           `json:"offset,omitempty"`
+}
+
+// TopologyApplicationDtoIn is the TopologyApplicationDtoIn schema of the Keep API
+type TopologyApplicationDtoIn struct {
+	Description string                 `json:"description,omitempty"`
+	ID          string                 `json:"id,omitempty"`
+	Name        string                 `json:"name"`
+	Repository  string                 `json:"repository,omitempty"`
+	Services    []TopologyServiceDtoIn `json:"services,omitempty"`
+}
+
+// TopologyApplicationDtoOut is the TopologyApplicationDtoOut schema of the Keep API
+type TopologyApplicationDtoOut struct {
+	Description string                          `json:"description,omitempty"`
+	ID          string                          `json:"id"`
+	Name        string                          `json:"name"`
+	Repository  string                          `json:"repository,omitempty"`
+	Services    []TopologyApplicationServiceDto `json:"services,omitempty"`
+}
+
+// TopologyApplicationServiceDto is the TopologyApplicationServiceDto schema of the Keep API
+type TopologyApplicationServiceDto struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Service string `json:"service"`
+}
+
+// TopologyServiceCreateRequestDTO is the TopologyServiceCreateRequestDTO schema of the Keep API
+type TopologyServiceCreateRequestDTO struct {
+	Category     string   `json:"category,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	DisplayName  string   `json:"display_name"`
+	Email        string   `json:"email,omitempty"`
+	Environment  string   `json:"environment,omitempty"`
+	IPAddress    string   `json:"ip_address,omitempty"`
+	MacAddress   string   `json:"mac_address,omitempty"`
+	Manufacturer string   `json:"manufacturer,omitempty"`
+	Namespace    string   `json:"namespace,omitempty"`
+	Repository   string   `json:"repository,omitempty"`
+	Service      string   `json:"service"`
+	Slack        string   `json:"slack,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Team         string   `json:"team,omitempty"`
+}
+
+// TopologyServiceDependencyCreateRequestDto is the TopologyServiceDependencyCreateRequestDto schema of the Keep API
+type TopologyServiceDependencyCreateRequestDto struct {
+	DependsOnServiceID int    `json:"depends_on_service_id"`
+	Protocol           string `json:"protocol,omitempty"`
+	ServiceID          int    `json:"service_id"`
+}
+
+// TopologyServiceDependencyDto is the TopologyServiceDependencyDto schema of the Keep API
+type TopologyServiceDependencyDto struct {
+	ID          string `json:"id,omitempty"`
+	Protocol    string `json:"protocol,omitempty"`
+	ServiceID   string `json:"serviceId"`
+	ServiceName string `json:"serviceName"`
+}
+
+// TopologyServiceDtoIn is the TopologyServiceDtoIn schema of the Keep API
+type TopologyServiceDtoIn struct {
+	ID int `json:"id"`
+}
+
+// TopologyServiceDtoOut is the TopologyServiceDtoOut schema of the Keep API
+type TopologyServiceDtoOut struct {
+	ApplicationIDs   []string                       `json:"application_ids"`
+	Category         string                         `json:"category,omitempty"`
+	Dependencies     []TopologyServiceDependencyDto `json:"dependencies"`
+	Description      string                         `json:"description,omitempty"`
+	DisplayName      string                         `json:"display_name"`
+	Email            string                         `json:"email,omitempty"`
+	Environment      string                         `json:"environment,omitempty"`
+	ID               string                         `json:"id"`
+	IPAddress        string                         `json:"ip_address,omitempty"`
+	IsManual         bool                           `json:"is_manual,omitempty"`
+	MacAddress       string                         `json:"mac_address,omitempty"`
+	Manufacturer     string                         `json:"manufacturer,omitempty"`
+	Namespace        string                         `json:"namespace,omitempty"`
+	Repository       string                         `json:"repository,omitempty"`
+	Service          string                         `json:"service"`
+	Slack            string                         `json:"slack,omitempty"`
+	SourceProviderID string                         `json:"source_provider_id,omitempty"`
+	Tags             []string                       `json:"tags,omitempty"`
+	Team             string                         `json:"team,omitempty"`
+	UpdatedAt        string                         `json:"updated_at,omitempty"`
+}
+
+// TopologyServiceUpdateRequestDTO is the TopologyServiceUpdateRequestDTO schema of the Keep API
+type TopologyServiceUpdateRequestDTO struct {
+	Category     string   `json:"category,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	DisplayName  string   `json:"display_name"`
+	Email        string   `json:"email,omitempty"`
+	Environment  string   `json:"environment,omitempty"`
+	ID           int      `json:"id"`
+	IPAddress    string   `json:"ip_address,omitempty"`
+	MacAddress   string   `json:"mac_address,omitempty"`
+	Manufacturer string   `json:"manufacturer,omitempty"`
+	Namespace    string   `json:"namespace,omitempty"`
+	Repository   string   `json:"repository,omitempty"`
+	Service      string   `json:"service"`
+	Slack        string   `json:"slack,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Team         string   `json:"team,omitempty"`
+}
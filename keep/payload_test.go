@@ -0,0 +1,105 @@
+package keep
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files of the payload tests")
+
+// assertGoldenPayload compares the JSON payload with the golden file in testdata/payloads,
+// "go test ./keep -run TestPayload -update" writes the current payloads to the golden files
+func assertGoldenPayload(t *testing.T, name string, payload []byte) {
+	t.Helper()
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, payload, "", "  "); err != nil {
+		t.Fatalf("payload is not valid JSON: %v: %s", err, payload)
+	}
+	indented.WriteString("\n")
+
+	golden := filepath.Join("testdata", "payloads", name+".json")
+	if *updateGolden {
+		if err := os.WriteFile(golden, indented.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("cannot read golden file, run the test with -update to create it: %v", err)
+	}
+	if !bytes.Equal(expected, indented.Bytes()) {
+		t.Errorf("payload differs from %s, run the test with -update if the change is intended:\n%s", golden, indented.String())
+	}
+}
+
+func TestPayloadInstallProvider(t *testing.T) {
+	backend := newMockBackend(t)
+	config := map[string]interface{}{
+		"type":             "test",
+		"name":             "test-provider",
+		"auth_config":      map[string]interface{}{"host": "https://example.com", "token": "secret"},
+		"pulling_interval": 300,
+	}
+
+	if _, diags := applyMockResource(t, resourceProvider(), nil, config, backend.client()); diags.HasError() {
+		t.Fatalf("unexpected error on create: %v", diags)
+	}
+	assertGoldenPayload(t, "install_provider", backend.lastBody("POST", "/providers/install"))
+}
+
+func TestPayloadCreateMapping(t *testing.T) {
+	backend := newMockBackend(t)
+	mappingPath := filepath.Join(t.TempDir(), "services.csv")
+	if err := os.WriteFile(mappingPath, []byte("service,environment,team,priority\ncheckout,production,payments,P1\nsearch,staging,discovery,P3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := map[string]interface{}{
+		"name":              "services",
+		"description":       "Team of every service",
+		"mapping_file_path": mappingPath,
+		"matchers":          []interface{}{"service && environment", "service"},
+		"priority":          2,
+		"override":          false,
+	}
+
+	if _, diags := applyMockResource(t, resourceMapping(), nil, config, backend.client()); diags.HasError() {
+		t.Fatalf("unexpected error on create: %v", diags)
+	}
+	assertGoldenPayload(t, "create_mapping", backend.lastBody("POST", "/mapping"))
+}
+
+func TestPayloadCreateWorkflowJSON(t *testing.T) {
+	backend := newMockBackend(t)
+	workflowPath := filepath.Join(t.TempDir(), "workflow.yml")
+	workflow := `workflow:
+  id: on-field-change
+  name: on-field-change
+  description: Notify on status changes
+  triggers:
+    - type: alert
+      only_on_change:
+        - status
+  actions:
+    - name: notify
+      provider:
+        type: console
+        config: "{{ providers.console }}"
+        with:
+          message: "{{ alert.name }} is {{ alert.status }}"
+          retries: 3
+`
+	if err := os.WriteFile(workflowPath, []byte(workflow), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, diags := applyMockResource(t, resourceWorkflow(), nil, map[string]interface{}{"file": workflowPath}, backend.client()); diags.HasError() {
+		t.Fatalf("unexpected error on create: %v", diags)
+	}
+	assertGoldenPayload(t, "create_workflow_json", backend.lastBody("POST", "/workflows/json"))
+}
@@ -1,43 +1,347 @@
 package keep
 
 import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
-// Provider for Keep
+// Provider for Keep with a development version, used by tests
 func Provider() *schema.Provider {
-	return &schema.Provider{
-		Schema: map[string]*schema.Schema{
-			"backend_url": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Keep backend url",
-				DefaultFunc: schema.EnvDefaultFunc("KEEP_BACKEND_URL", nil),
+	return New("dev")()
+}
+
+// New returns the provider factory for the given version of the provider
+func New(version string) func() *schema.Provider {
+	return func() *schema.Provider {
+		p := &schema.Provider{
+			Schema: map[string]*schema.Schema{
+				"backend_url": {
+					Type:         schema.TypeString,
+					ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+					Optional:     true,
+					Description:  "Keep backend url. Defaults to the KEEP_BACKEND_URL environment variable",
+					DefaultFunc:  schema.EnvDefaultFunc("KEEP_BACKEND_URL", nil),
+				},
+				"config_file": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Path of the config file shared with the Keep CLI, providing api_url, api_key and tenant_id if they are not set otherwise. Defaults to the KEEP_CONFIG_FILE environment variable or ~/.keep/config.yaml",
+					DefaultFunc: schema.EnvDefaultFunc("KEEP_CONFIG_FILE", nil),
+				},
+				"profile": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Profile of the config file to use, the top level settings of the file are the default profile. Defaults to the KEEP_PROFILE environment variable",
+					DefaultFunc: schema.EnvDefaultFunc("KEEP_PROFILE", nil),
+				},
+				"base_path": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Path the Keep API is mounted under, appended to backend_url, e.g. /api for backends behind a reverse proxy. Defaults to the KEEP_BASE_PATH environment variable",
+					DefaultFunc: schema.EnvDefaultFunc("KEEP_BASE_PATH", nil),
+				},
+				"api_key": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Sensitive:   true,
+					Description: "Keep API Key. Defaults to the KEEP_API_KEY environment variable",
+					DefaultFunc: schema.EnvDefaultFunc("KEEP_API_KEY", nil),
+				},
+				"api_key_file": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Path of a file containing the Keep API Key, used instead of api_key. The file is read again when the key is rejected, so rotated keys are picked up. Defaults to the KEEP_API_KEY_FILE environment variable",
+					DefaultFunc: schema.EnvDefaultFunc("KEEP_API_KEY_FILE", nil),
+				},
+				"tenant_id": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Tenant sent as X-Tenant-Id header with every request, uses the tenant of the API key if not set. Defaults to the KEEP_TENANT_ID environment variable",
+					DefaultFunc: schema.EnvDefaultFunc("KEEP_TENANT_ID", nil),
+				},
+				"auth_type": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      authTypeAPIKey,
+					ValidateFunc: validation.StringInSlice([]string{authTypeAPIKey, authTypeBearer}, false),
+					Description:  "How api_key is sent to the backend. api_key sends it as X-API-Key header, bearer as Authorization: Bearer header, e.g. for a JWT of an OIDC gateway. Default is api_key.",
+				},
+				"oauth2": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					MaxItems:    1,
+					Description: "Authenticate with access tokens of the OAuth2 client credentials flow instead of api_key. Tokens are refreshed automatically when they expire.",
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"token_url": {
+								Type:         schema.TypeString,
+								Required:     true,
+								ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+								Description:  "Token endpoint of the identity provider",
+							},
+							"client_id": {
+								Type:        schema.TypeString,
+								Required:    true,
+								Description: "OAuth2 client id",
+							},
+							"client_secret": {
+								Type:        schema.TypeString,
+								Required:    true,
+								Sensitive:   true,
+								Description: "OAuth2 client secret",
+							},
+							"scopes": {
+								Type:        schema.TypeList,
+								Optional:    true,
+								Elem:        &schema.Schema{Type: schema.TypeString},
+								Description: "Scopes to request for the access token",
+							},
+						},
+					},
+				},
+				"auth_exec": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					MaxItems:    1,
+					Description: "Run a command to authenticate with gateways in front of the backend, e.g. gcloud for identity-aware proxy tokens or az for Azure AD tokens. The command prints a token or a JSON object with token, expires_at (RFC 3339) and headers. The headers are sent with every request and the token is available in header_templates. If api_key, api_key_file and oauth2 are not set, the token authenticates with the backend according to auth_type.",
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"command": {
+								Type:        schema.TypeString,
+								Required:    true,
+								Description: "Command to run, looked up in PATH",
+							},
+							"args": {
+								Type:        schema.TypeList,
+								Optional:    true,
+								Elem:        &schema.Schema{Type: schema.TypeString},
+								Description: "Arguments of the command",
+							},
+							"env": {
+								Type:        schema.TypeMap,
+								Optional:    true,
+								Elem:        &schema.Schema{Type: schema.TypeString},
+								Description: "Environment variables set in addition to the environment of terraform",
+							},
+							"per_request": {
+								Type:        schema.TypeBool,
+								Optional:    true,
+								Default:     false,
+								Description: "Run the command for every request instead of caching its output until it expires, e.g. to sign requests with AWS SigV4. The request is passed in the KEEP_REQUEST_METHOD, KEEP_REQUEST_URL and KEEP_REQUEST_BODY_SHA256 environment variables. The token cannot authenticate with the backend then. Default is false.",
+							},
+						},
+					},
+				},
+				"header_templates": {
+					Type:        schema.TypeMap,
+					Optional:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Description: "Headers sent with every request whose values are Go templates, e.g. Proxy-Authorization = \"Bearer {{ .Token }}\". Templates can use .Token of auth_exec, .Method and .URL of the request and the env function to read environment variables. Their values are redacted in logs.",
+				},
+				"timeout": {
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validateDuration,
+					Optional:         true,
+					Description:      "Timeout duration of requests, used if read_timeout or write_timeout is not set. Defaults to the KEEP_TIMEOUT environment variable or 30 seconds (30s). Requests of keep_provider, keep_mapping and keep_extraction are bounded by their timeouts instead.",
+					DefaultFunc:      schema.EnvDefaultFunc("KEEP_TIMEOUT", "30s"),
+				},
+				"read_timeout": {
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validateDuration,
+					Optional:         true,
+					Description:      "Timeout duration of requests reading from the backend, defaults to timeout",
+				},
+				"write_timeout": {
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validateDuration,
+					Optional:         true,
+					Description:      "Timeout duration of requests changing the backend, e.g. uploads of large workflows or mapping files, defaults to timeout",
+				},
+				"headers": {
+					Type:        schema.TypeMap,
+					Optional:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Description: "Additional headers sent with every request, e.g. CF-Access-Client-Id and CF-Access-Client-Secret for Cloudflare Access. X-API-Key and X-Tenant-Id cannot be overridden",
+				},
+				"proxy_url": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validation.IsURLWithScheme([]string{"http", "https", "socks5"}),
+					Description:  "URL of the proxy to send requests through. Defaults to the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables",
+				},
+				"max_idle_conns": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      transportMaxIdleConnsPerHost,
+					ValidateFunc: validation.IntAtLeast(1),
+					Description:  "Maximum number of idle connections to the backend kept open for following requests. Raise it together with the parallelism of terraform, so requests don't have to open new connections. Default is 32.",
+				},
+				"idle_conn_timeout": {
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validateDuration,
+					Optional:         true,
+					Default:          "90s",
+					Description:      "Duration after which idle connections to the backend are closed. Default is 90 seconds (90s).",
+				},
+				"tls_handshake_timeout": {
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validateDuration,
+					Optional:         true,
+					Default:          "10s",
+					Description:      "Timeout duration of TLS handshakes with the backend, raise it for backends with a high latency. Default is 10 seconds (10s).",
+				},
+				"disable_http2": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Use HTTP/1.1 even if the backend supports HTTP/2, e.g. for gateways with broken HTTP/2 support. Default is false.",
+				},
+				"opentelemetry_tracing": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("KEEP_OPENTELEMETRY_TRACING", false),
+					Description: "Record an OpenTelemetry span of every call to the Keep API with its method, path, status code and duration, and send the trace context to the backend. Spans are exported with OTLP over HTTP, configured with the OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_EXPORTER_OTLP_HEADERS and related environment variables. Defaults to the KEEP_OPENTELEMETRY_TRACING environment variable or false.",
+				},
+				"request_compression": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      compressionNone,
+					ValidateFunc: validation.StringInSlice([]string{compressionNone, compressionGzip}, false),
+					Description:  "Compression of request bodies. gzip compresses bodies of 64 KiB or more, e.g. large mapping rows and workflows, with Content-Encoding: gzip, to stay below body size limits of gateways. If the backend rejects a compressed body with 415, the request and all following ones are sent uncompressed. Default is none.",
+				},
+				"ca_cert_pem": {
+					Type:          schema.TypeString,
+					Optional:      true,
+					ConflictsWith: []string{"ca_cert_file"},
+					Description:   "PEM encoded CA certificates to trust in addition to the system trust store",
+				},
+				"ca_cert_file": {
+					Type:          schema.TypeString,
+					Optional:      true,
+					ConflictsWith: []string{"ca_cert_pem"},
+					Description:   "Path of a file with PEM encoded CA certificates to trust in addition to the system trust store",
+				},
+				"client_cert_pem": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					RequiredWith: []string{"client_key_pem"},
+					Description:  "PEM encoded client certificate for mutual TLS authentication",
+				},
+				"client_key_pem": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Sensitive:    true,
+					RequiredWith: []string{"client_cert_pem"},
+					Description:  "PEM encoded private key of the client certificate",
+				},
+				"insecure_skip_verify": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Skip verifying the TLS certificate of the backend. Only use this for testing. Default is false.",
+				},
+				"user_agent_suffix": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Appended to the User-Agent header of every request, e.g. to identify a pipeline",
+				},
+				"validate_credentials": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Send an authenticated request to the backend when the provider is configured, to report a wrong backend_url, rejected credentials or TLS failures before any resource is changed. Default is false.",
+				},
+				"max_retries": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      3,
+					ValidateFunc: validation.IntAtLeast(0),
+					Description:  "Number of retries of requests which are rate limited, time out, fail with a server error or fail to connect. Only rate limited requests are retried for all methods, the other failures only for idempotent requests. Retries wait as long as the Retry-After header of the response requests, up to 5 minutes. Default is 3.",
+				},
+				"max_concurrent_requests": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      0,
+					ValidateFunc: validation.IntAtLeast(0),
+					Description:  "Maximum number of requests sent to the backend at the same time, independent of the parallelism of terraform. Default is 0, which does not limit requests.",
+				},
+				"requests_per_second": {
+					Type:         schema.TypeFloat,
+					Optional:     true,
+					Default:      0,
+					ValidateFunc: validation.FloatAtLeast(0),
+					Description:  "Maximum average number of requests per second sent to the backend, including retries. Default is 0, which does not limit requests.",
+				},
+				"burst": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      1,
+					ValidateFunc: validation.IntAtLeast(1),
+					Description:  "Number of requests which can be sent at once before requests_per_second applies. Default is 1.",
+				},
+				"circuit_breaker_threshold": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      defaultCircuitBreakerThreshold,
+					ValidateFunc: validation.IntAtLeast(0),
+					Description:  "Number of consecutive requests failing without a response, e.g. because the backend is down, after which all requests fail immediately instead of timing out one by one. A request probes the backend again after a minute. Default is 5, 0 disables the circuit breaker.",
+				},
+				"retry_min_wait": {
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validateDuration,
+					Optional:         true,
+					Default:          "1s",
+					Description:      "Wait duration before the first retry, doubled for every following retry. Up to half of every wait is random, so clients failing at the same time don't retry at the same time. Default is 1 second (1s).",
+				},
+				"retry_max_wait": {
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validateDuration,
+					Optional:         true,
+					Default:          "30s",
+					Description:      "Maximum wait duration between retries. Default is 30 seconds (30s).",
+				},
+				"readiness_timeout": {
+					Type:             schema.TypeString,
+					ValidateDiagFunc: validateDuration,
+					Optional:         true,
+					Default:          "30s",
+					Description:      "Maximum wait duration until created providers and mappings are returned by the backend, which is eventually consistent. The backend is polled with backoff meanwhile. Default is 30 seconds (30s).",
+				},
 			},
-			"api_key": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Keep API Key",
-				DefaultFunc: schema.EnvDefaultFunc("KEEP_API_KEY", nil),
+			ResourcesMap: map[string]*schema.Resource{
+				"keep_provider":         resourceProvider(),
+				"keep_workflow":         resourceWorkflow(),
+				"keep_workflows_sync":   resourceWorkflowsSync(),
+				"keep_mapping":          resourceMapping(),
+				"keep_extraction":       resourceExtraction(),
+				"keep_extractions":      resourceExtractions(),
+				"keep_extraction_order": resourceExtractionOrder(),
 			},
-			"timeout": {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "Timeout duration for the http client. Default is 30 seconds (30s).",
-				Default:     "30s",
-				DefaultFunc: schema.EnvDefaultFunc("KEEP_TIMEOUT", "30s"),
+			DataSourcesMap: map[string]*schema.Resource{
+				"keep_workflow":          dataSourceWorkflows(),
+				"keep_mapping":           dataSourceMapping(),
+				"keep_extraction":        dataSourceExtraction(),
+				"keep_import_candidates": dataSourceImportCandidates(),
 			},
-		},
-		ResourcesMap: map[string]*schema.Resource{
-			"keep_provider":   resourceProvider(),
-			"keep_workflow":   resourceWorkflow(),
-			"keep_mapping":    resourceMapping(),
-			"keep_extraction": resourceExtraction(),
-		},
-		DataSourcesMap: map[string]*schema.Resource{
-			"keep_workflow": dataSourceWorkflows(),
-			"keep_mapping":  dataSourceMapping(),
-		},
-		ConfigureContextFunc: ClientConfigurer,
+		}
+
+		p.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+			return ClientConfigurer(ctx, d, userAgent(version, p.TerraformVersion, d.Get("user_agent_suffix").(string)))
+		}
+
+		return p
+	}
+}
+
+// userAgent returns the User-Agent header identifying the provider and terraform versions
+func userAgent(version, terraformVersion, suffix string) string {
+	if terraformVersion == "" {
+		terraformVersion = "unknown"
 	}
+	return strings.TrimSpace(fmt.Sprintf("terraform-provider-keep/%s (terraform %s) %s", version, terraformVersion, suffix))
 }
@@ -0,0 +1,225 @@
+package keep
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	providerschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// frameworkProvider serves the terraform-plugin-framework resources next to the SDK provider. Both share one
+// provider configuration: the schema is the one of the SDK provider and the client is the one it configured.
+type frameworkProvider struct {
+	version     string
+	sdkProvider *schema.Provider
+}
+
+var (
+	_ provider.ProviderWithFunctions          = &frameworkProvider{}
+	_ provider.ProviderWithEphemeralResources = &frameworkProvider{}
+)
+
+func newFrameworkProvider(version string, sdkProvider *schema.Provider) provider.Provider {
+	return &frameworkProvider{version: version, sdkProvider: sdkProvider}
+}
+
+func (p *frameworkProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "keep"
+	resp.Version = p.version
+}
+
+// Schema returns the schema of the SDK provider, the mux rejects servers with differing provider schemas
+func (p *frameworkProvider) Schema(ctx context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
+	schemaResp, err := schema.NewGRPCProviderServer(p.sdkProvider).GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		resp.Diagnostics.AddError("Cannot read the schema of the SDK provider", err.Error())
+		return
+	}
+
+	attributes, blocks, err := frameworkSchemaBlock(schemaResp.Provider.Block)
+	if err != nil {
+		resp.Diagnostics.AddError("Cannot convert the schema of the SDK provider", err.Error())
+		return
+	}
+	resp.Schema = providerschema.Schema{
+		Description: schemaResp.Provider.Block.Description,
+		Attributes:  attributes,
+		Blocks:      blocks,
+	}
+}
+
+// Configure passes the client of the SDK provider to the framework resources. The mux configures the SDK provider
+// first and stops if that fails.
+func (p *frameworkProvider) Configure(_ context.Context, _ provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	client, ok := p.sdkProvider.Meta().(KeepClient)
+	if !ok {
+		resp.Diagnostics.AddError("The provider is not configured", "The SDK provider has to be configured before the framework provider")
+		return
+	}
+	resp.DataSourceData = client
+	resp.ResourceData = client
+	resp.EphemeralResourceData = client
+}
+
+func (p *frameworkProvider) Resources(_ context.Context) []func() resource.Resource {
+	return nil
+}
+
+func (p *frameworkProvider) DataSources(_ context.Context) []func() datasource.DataSource {
+	return nil
+}
+
+func (p *frameworkProvider) EphemeralResources(_ context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		newAPIKeyEphemeralResource,
+	}
+}
+
+// Functions are the provider defined functions, called as provider::keep::<name>(...)
+func (p *frameworkProvider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		newCSVToRowsFunction,
+		newParseWorkflowFunction,
+		newValidateCELFunction,
+	}
+}
+
+// frameworkSchemaBlock converts the attributes and nested blocks of a protocol schema block to the framework
+func frameworkSchemaBlock(block *tfprotov5.SchemaBlock) (map[string]providerschema.Attribute, map[string]providerschema.Block, error) {
+	attributes := make(map[string]providerschema.Attribute, len(block.Attributes))
+	for _, attribute := range block.Attributes {
+		converted, err := frameworkSchemaAttribute(attribute)
+		if err != nil {
+			return nil, nil, fmt.Errorf("attribute %s: %s", attribute.Name, err)
+		}
+		attributes[attribute.Name] = converted
+	}
+
+	blocks := make(map[string]providerschema.Block, len(block.BlockTypes))
+	for _, nested := range block.BlockTypes {
+		nestedAttributes, nestedBlocks, err := frameworkSchemaBlock(nested.Block)
+		if err != nil {
+			return nil, nil, fmt.Errorf("block %s: %s", nested.TypeName, err)
+		}
+		object := providerschema.NestedBlockObject{Attributes: nestedAttributes, Blocks: nestedBlocks}
+
+		switch nested.Nesting {
+		case tfprotov5.SchemaNestedBlockNestingModeList:
+			blocks[nested.TypeName] = providerschema.ListNestedBlock{NestedObject: object, Description: nested.Block.Description}
+		case tfprotov5.SchemaNestedBlockNestingModeSet:
+			blocks[nested.TypeName] = providerschema.SetNestedBlock{NestedObject: object, Description: nested.Block.Description}
+		default:
+			return nil, nil, fmt.Errorf("block %s: unsupported nesting mode %s", nested.TypeName, nested.Nesting)
+		}
+	}
+
+	return attributes, blocks, nil
+}
+
+// frameworkSchemaAttribute converts an attribute of a protocol schema block to the framework
+func frameworkSchemaAttribute(attribute *tfprotov5.SchemaAttribute) (providerschema.Attribute, error) {
+	if attribute.Computed {
+		return nil, fmt.Errorf("computed attributes are not supported by provider schemas")
+	}
+
+	var deprecationMessage string
+	if attribute.Deprecated {
+		deprecationMessage = attribute.Description
+	}
+
+	attributeType, err := frameworkAttributeType(attribute.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	switch attributeType := attributeType.(type) {
+	case basetypes.StringType:
+		return providerschema.StringAttribute{
+			Description:        attribute.Description,
+			Required:           attribute.Required,
+			Optional:           attribute.Optional,
+			Sensitive:          attribute.Sensitive,
+			DeprecationMessage: deprecationMessage,
+		}, nil
+	case basetypes.BoolType:
+		return providerschema.BoolAttribute{
+			Description:        attribute.Description,
+			Required:           attribute.Required,
+			Optional:           attribute.Optional,
+			Sensitive:          attribute.Sensitive,
+			DeprecationMessage: deprecationMessage,
+		}, nil
+	case basetypes.NumberType:
+		return providerschema.NumberAttribute{
+			Description:        attribute.Description,
+			Required:           attribute.Required,
+			Optional:           attribute.Optional,
+			Sensitive:          attribute.Sensitive,
+			DeprecationMessage: deprecationMessage,
+		}, nil
+	case basetypes.ListType:
+		return providerschema.ListAttribute{
+			ElementType:        attributeType.ElemType,
+			Description:        attribute.Description,
+			Required:           attribute.Required,
+			Optional:           attribute.Optional,
+			Sensitive:          attribute.Sensitive,
+			DeprecationMessage: deprecationMessage,
+		}, nil
+	case basetypes.SetType:
+		return providerschema.SetAttribute{
+			ElementType:        attributeType.ElemType,
+			Description:        attribute.Description,
+			Required:           attribute.Required,
+			Optional:           attribute.Optional,
+			Sensitive:          attribute.Sensitive,
+			DeprecationMessage: deprecationMessage,
+		}, nil
+	case basetypes.MapType:
+		return providerschema.MapAttribute{
+			ElementType:        attributeType.ElemType,
+			Description:        attribute.Description,
+			Required:           attribute.Required,
+			Optional:           attribute.Optional,
+			Sensitive:          attribute.Sensitive,
+			DeprecationMessage: deprecationMessage,
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", attribute.Type)
+}
+
+// frameworkAttributeType converts a value type of the protocol to the framework
+func frameworkAttributeType(valueType tftypes.Type) (attr.Type, error) {
+	switch valueType := valueType.(type) {
+	case tftypes.List:
+		elementType, err := frameworkAttributeType(valueType.ElementType)
+		return types.ListType{ElemType: elementType}, err
+	case tftypes.Set:
+		elementType, err := frameworkAttributeType(valueType.ElementType)
+		return types.SetType{ElemType: elementType}, err
+	case tftypes.Map:
+		elementType, err := frameworkAttributeType(valueType.ElementType)
+		return types.MapType{ElemType: elementType}, err
+	}
+
+	switch {
+	case valueType.Is(tftypes.String):
+		return types.StringType, nil
+	case valueType.Is(tftypes.Bool):
+		return types.BoolType, nil
+	case valueType.Is(tftypes.Number):
+		return types.NumberType, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", valueType)
+}
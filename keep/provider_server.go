@@ -0,0 +1,38 @@
+package keep
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-mux/tf5to6server"
+	"github.com/hashicorp/terraform-plugin-mux/tf6muxserver"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// NewProviderServer returns a factory of the protocol v6 server for the given version of the provider. It muxes the
+// protocol v5 server of the SDK provider, upgraded to v6, with the server of the terraform-plugin-framework provider,
+// so resources can be migrated to the framework one by one. Every resource type may only be served by one of them.
+func NewProviderServer(ctx context.Context, version string) (func() tfprotov6.ProviderServer, error) {
+	provider := New(version)()
+
+	upgraded, err := tf5to6server.UpgradeServer(ctx, func() tfprotov5.ProviderServer {
+		return schema.NewGRPCProviderServer(provider)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot upgrade the protocol v5 server: %s", err)
+	}
+
+	// the mux configures the servers in order, the framework provider uses the client of the SDK provider
+	muxServer, err := tf6muxserver.NewMuxServer(ctx,
+		func() tfprotov6.ProviderServer { return upgraded },
+		providerserver.NewProtocol6(newFrameworkProvider(version, provider)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot mux the provider servers: %s", err)
+	}
+
+	return muxServer.ProviderServer, nil
+}
@@ -0,0 +1,114 @@
+package keep
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestProviderServer(t *testing.T) {
+	ctx := context.Background()
+	factory, err := NewProviderServer(ctx, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := factory()
+
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schemaResp.Diagnostics) > 0 {
+		t.Fatalf("unexpected diagnostics: %s: %s", schemaResp.Diagnostics[0].Summary, schemaResp.Diagnostics[0].Detail)
+	}
+	for _, name := range []string{"keep_workflow", "keep_mapping", "keep_provider"} {
+		if schemaResp.ResourceSchemas[name] == nil {
+			t.Errorf("expected the schema of %s", name)
+		}
+	}
+	if schemaResp.Functions["csv_to_rows"] == nil {
+		t.Error("expected the functions of the framework provider")
+	}
+
+	var arguments []*tfprotov6.DynamicValue
+	for _, value := range []string{"a,b\n1,2\n", ","} {
+		argument, err := tfprotov6.NewDynamicValue(tftypes.String, tftypes.NewValue(tftypes.String, value))
+		if err != nil {
+			t.Fatal(err)
+		}
+		arguments = append(arguments, &argument)
+	}
+	callResp, err := server.CallFunction(ctx, &tfprotov6.CallFunctionRequest{Name: "csv_to_rows", Arguments: arguments})
+	if err != nil || callResp.Error != nil {
+		t.Fatalf("unexpected result calling csv_to_rows: %+v, %v", callResp.Error, err)
+	}
+}
+
+// newConfiguredProviderServer returns the provider server configured for the backend and its schemas
+func newConfiguredProviderServer(t *testing.T, backendURL string) (tfprotov6.ProviderServer, *tfprotov6.GetProviderSchemaResponse) {
+	t.Helper()
+
+	ctx := context.Background()
+	factory, err := NewProviderServer(ctx, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := factory()
+
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	configType := schemaResp.Provider.ValueType().(tftypes.Object)
+	attributes := make(map[string]tftypes.Value, len(configType.AttributeTypes))
+	for name, attributeType := range configType.AttributeTypes {
+		attributes[name] = tftypes.NewValue(attributeType, nil)
+	}
+	attributes["backend_url"] = tftypes.NewValue(tftypes.String, backendURL)
+	attributes["api_key"] = tftypes.NewValue(tftypes.String, "secret")
+	config, err := tfprotov6.NewDynamicValue(configType, tftypes.NewValue(configType, attributes))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the framework provider fails unless the mux configured the SDK provider and its client first
+	configureResp, err := server.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{Config: &config})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range configureResp.Diagnostics {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			t.Errorf("unexpected diagnostics: %s: %s", d.Summary, d.Detail)
+		}
+	}
+
+	return server, schemaResp
+}
+
+func TestProviderServer_Configure(t *testing.T) {
+	newConfiguredProviderServer(t, "http://localhost:8080")
+}
+
+func TestProviderServerResourceIdentities(t *testing.T) {
+	factory, err := NewProviderServer(context.Background(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server, ok := factory().(tfprotov6.ProviderServerWithResourceIdentity)
+	if !ok {
+		t.Fatal("expected the provider server to serve resource identities")
+	}
+
+	resp, err := server.GetResourceIdentitySchemas(context.Background(), &tfprotov6.GetResourceIdentitySchemasRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"keep_mapping", "keep_provider", "keep_workflow"} {
+		if resp.IdentitySchemas[name] == nil {
+			t.Errorf("expected the identity schema of %s", name)
+		}
+	}
+}
@@ -2,8 +2,20 @@ package keep
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -42,14 +54,9 @@ var testAccProviderFactories map[string]func() (*schema.Provider, error)
 
 func init() {
 	testAccProvider = Provider()
-	err := testAccProvider.Configure(context.Background(), &terraform.ResourceConfig{
-		Raw: map[string]interface{}{
-			"backend_url": os.Getenv("KEEP_BACKEND_URL"),
-			"api_key":     os.Getenv("KEEP_API_KEY"),
-			"timeout":     os.Getenv("KEEP_TIMEOUT"),
-		},
-	})
-	if err != nil {
+	// The configuration is taken from the KEEP_* environment variables, which are only required by acceptance tests
+	err := testAccProvider.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{}))
+	if err != nil && os.Getenv(resource.EnvTfAcc) != "" {
 		panic(fmt.Sprintf("Failed to configure provider: %v", err))
 	}
 
@@ -66,6 +73,380 @@ func TestProvider(t *testing.T) {
 	}
 }
 
+func TestProvider_EnvDefaults(t *testing.T) {
+	t.Setenv("KEEP_BACKEND_URL", "https://keep.example.com")
+	t.Setenv("KEEP_API_KEY", "key")
+	t.Setenv("KEEP_TIMEOUT", "1m")
+	t.Setenv("KEEP_TENANT_ID", "tenant")
+
+	p := Provider()
+	if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{})); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	client := p.Meta().(*Client)
+	if client.HostURL != "https://keep.example.com" || client.ApiKey != "key" || client.ReadTimeout != time.Minute || client.WriteTimeout != time.Minute || client.TenantID != "tenant" {
+		t.Errorf("unexpected client configuration: %s, %s, %s, %s, %s", client.HostURL, client.ApiKey, client.ReadTimeout, client.WriteTimeout, client.TenantID)
+	}
+
+	t.Setenv("KEEP_API_KEY", "")
+	if diags := Provider().Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{})); !diags.HasError() {
+		t.Error("expected error without api_key")
+	}
+}
+
+func TestProvider_CACert(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	caCertPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	cases := map[string]struct {
+		config      map[string]interface{}
+		expectError bool
+	}{
+		"system trust store": {config: map[string]interface{}{}, expectError: true},
+		"ca_cert_pem":        {config: map[string]interface{}{"ca_cert_pem": caCertPEM}},
+		"insecure":           {config: map[string]interface{}{"insecure_skip_verify": true}},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tc.config["backend_url"] = server.URL
+			tc.config["api_key"] = "key"
+
+			p := Provider()
+			if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(tc.config)); diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			_, _, err := p.Meta().(*Client).GetInstalledProviders(context.Background())
+			if (err != nil) != tc.expectError {
+				t.Errorf("expected error %t, got %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestProvider_ClientCert(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "terraform"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caCertPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	clientCertPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}))
+	clientKeyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+
+	cases := map[string]struct {
+		config      map[string]interface{}
+		expectError bool
+	}{
+		"without client certificate": {config: map[string]interface{}{}, expectError: true},
+		"with client certificate":    {config: map[string]interface{}{"client_cert_pem": clientCertPEM, "client_key_pem": clientKeyPEM}},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tc.config["backend_url"] = server.URL
+			tc.config["api_key"] = "key"
+			tc.config["ca_cert_pem"] = caCertPEM
+
+			p := Provider()
+			if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(tc.config)); diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			_, _, err := p.Meta().(*Client).GetInstalledProviders(context.Background())
+			if (err != nil) != tc.expectError {
+				t.Errorf("expected error %t, got %v", tc.expectError, err)
+			}
+		})
+	}
+
+	t.Run("invalid key pair", func(t *testing.T) {
+		p := Provider()
+		diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+			"backend_url":     server.URL,
+			"api_key":         "key",
+			"client_cert_pem": clientCertPEM,
+			"client_key_pem":  "invalid",
+		}))
+		if !diags.HasError() {
+			t.Fatal("expected an error")
+		}
+	})
+}
+
+func TestProvider_ProxyURL(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte(`[]`))
+	}))
+	defer proxy.Close()
+
+	p := Provider()
+	diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"backend_url": "http://keep.invalid",
+		"api_key":     "key",
+		"proxy_url":   proxy.URL,
+	}))
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if _, _, err := p.Meta().(*Client).GetInstalledProviders(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if proxied != "http://keep.invalid/providers/export?limit=100&offset=0" {
+		t.Errorf("expected the request to be sent through the proxy, got %q", proxied)
+	}
+}
+
+func TestProvider_ValidateDurations(t *testing.T) {
+	cases := []struct {
+		name     string
+		config   map[string]interface{}
+		hasError bool
+	}{
+		{name: "valid", config: map[string]interface{}{"timeout": "45s", "retry_max_wait": "1m"}},
+		{name: "missing unit", config: map[string]interface{}{"timeout": "30"}, hasError: true},
+		{name: "negative", config: map[string]interface{}{"read_timeout": "-5s"}, hasError: true},
+		{name: "zero", config: map[string]interface{}{"idle_conn_timeout": "0s"}, hasError: true},
+		{name: "invalid backend url", config: map[string]interface{}{"backend_url": "keep.example.com"}, hasError: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			diags := Provider().Validate(terraform.NewResourceConfigRaw(tc.config))
+			if diags.HasError() != tc.hasError {
+				t.Errorf("expected error %t, got %v", tc.hasError, diags)
+			}
+		})
+	}
+}
+
+func TestProvider_ValidateCredentials(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("X-API-Key") {
+		case "valid":
+			w.Write([]byte(`{"tenant_id":"keep"}`))
+		case "not-found":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	tlsServer := httptest.NewTLSServer(handler)
+	defer tlsServer.Close()
+	closedServer := httptest.NewServer(handler)
+	closedServer.Close()
+
+	cases := map[string]struct {
+		backendURL      string
+		apiKey          string
+		expectedSummary string
+	}{
+		"valid":             {backendURL: server.URL, apiKey: "valid"},
+		"rejected":          {backendURL: server.URL, apiKey: "invalid", expectedSummary: "Credentials were rejected by the Keep backend"},
+		"wrong path":        {backendURL: server.URL, apiKey: "not-found", expectedSummary: "backend_url does not point to the Keep API"},
+		"untrusted TLS":     {backendURL: tlsServer.URL, apiKey: "valid", expectedSummary: "TLS certificate of the Keep backend cannot be verified"},
+		"unreachable":       {backendURL: closedServer.URL, apiKey: "valid", expectedSummary: "Keep backend is not reachable at backend_url"},
+		"unresolvable host": {backendURL: "http://keep.invalid", apiKey: "valid", expectedSummary: "Host of backend_url cannot be resolved"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			diags := Provider().Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+				"backend_url":          tc.backendURL,
+				"api_key":              tc.apiKey,
+				"validate_credentials": true,
+				"retry_min_wait":       "1ms",
+				"retry_max_wait":       "5ms",
+			}))
+
+			if tc.expectedSummary == "" {
+				if diags.HasError() {
+					t.Fatalf("unexpected error: %v", diags)
+				}
+				return
+			}
+			if !diags.HasError() || diags[0].Summary != tc.expectedSummary {
+				t.Errorf("expected error %q, got %v", tc.expectedSummary, diags)
+			}
+		})
+	}
+}
+
+func TestProvider_BasePath(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		backendURL   string
+		basePath     string
+		expectedPath string
+	}{
+		{backendURL: server.URL, expectedPath: "/providers/export"},
+		{backendURL: server.URL + "/", expectedPath: "/providers/export"},
+		{backendURL: server.URL + "/keep/", basePath: "/api/", expectedPath: "/keep/api/providers/export"},
+		{backendURL: server.URL, basePath: "api", expectedPath: "/api/providers/export"},
+	}
+
+	for _, tc := range cases {
+		paths = nil
+		p := Provider()
+		diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+			"backend_url": tc.backendURL,
+			"base_path":   tc.basePath,
+			"api_key":     "key",
+		}))
+		if diags.HasError() {
+			t.Fatalf("unexpected error: %v", diags)
+		}
+
+		p.Meta().(*Client).GetInstalledProviders(context.Background())
+		if len(paths) != 1 || paths[0] != tc.expectedPath {
+			t.Errorf("expected path %s for backend_url %s and base_path %s, got %q", tc.expectedPath, tc.backendURL, tc.basePath, paths)
+		}
+	}
+}
+
+func TestProvider_UserAgent(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	for _, suffix := range []string{"", "ci/pipeline-1"} {
+		p := New("1.2.3")()
+		p.TerraformVersion = "1.9.0"
+		diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+			"backend_url":       server.URL,
+			"api_key":           "key",
+			"user_agent_suffix": suffix,
+		}))
+		if diags.HasError() {
+			t.Fatalf("unexpected error: %v", diags)
+		}
+		p.Meta().(*Client).GetInstalledProviders(context.Background())
+	}
+
+	expected := "terraform-provider-keep/1.2.3 (terraform 1.9.0),terraform-provider-keep/1.2.3 (terraform 1.9.0) ci/pipeline-1"
+	if strings.Join(userAgents, ",") != expected {
+		t.Errorf("expected user agents %s, got %q", expected, userAgents)
+	}
+}
+
+func TestProvider_ConfigFile(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := `
+api_url: https://keep.example.com
+api_key: default-key
+profiles:
+  staging:
+    api_url: https://staging.keep.example.com
+    api_key: staging-key
+    tenant_id: staging
+`
+	if err := os.WriteFile(configFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		config           map[string]interface{}
+		expectedHostURL  string
+		expectedApiKey   string
+		expectedTenantID string
+		expectError      bool
+	}{
+		"default profile": {
+			config:          map[string]interface{}{},
+			expectedHostURL: "https://keep.example.com",
+			expectedApiKey:  "default-key",
+		},
+		"named profile": {
+			config:           map[string]interface{}{"profile": "staging"},
+			expectedHostURL:  "https://staging.keep.example.com",
+			expectedApiKey:   "staging-key",
+			expectedTenantID: "staging",
+		},
+		"provider block takes precedence": {
+			config:           map[string]interface{}{"profile": "staging", "api_key": "key", "tenant_id": "tenant"},
+			expectedHostURL:  "https://staging.keep.example.com",
+			expectedApiKey:   "key",
+			expectedTenantID: "tenant",
+		},
+		"unknown profile": {
+			config:      map[string]interface{}{"profile": "production"},
+			expectError: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tc.config["config_file"] = configFile
+
+			p := Provider()
+			diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(tc.config))
+			if diags.HasError() != tc.expectError {
+				t.Fatalf("expected error %t, got %v", tc.expectError, diags)
+			}
+			if tc.expectError {
+				return
+			}
+
+			client := p.Meta().(*Client)
+			if client.HostURL != tc.expectedHostURL || client.ApiKey != tc.expectedApiKey || client.TenantID != tc.expectedTenantID {
+				t.Errorf("unexpected client configuration: %s, %s, %s", client.HostURL, client.ApiKey, client.TenantID)
+			}
+		})
+	}
+
+	missing := filepath.Join(t.TempDir(), "config.yaml")
+	diags := Provider().Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"backend_url": "https://keep.example.com",
+		"api_key":     "key",
+		"config_file": missing,
+	}))
+	if !diags.HasError() {
+		t.Error("expected error for a missing config file")
+	}
+}
+
 func TestProvider_impl(t *testing.T) {
 	var _ *schema.Provider = Provider()
 }
@@ -90,10 +471,10 @@ func testAccPreCheck(t *testing.T) {
 
 	// Clean up any existing test providers
 	client := testAccProvider.Meta().(*Client)
-	cleanupTestProviders(t, client, []string{"test-aks", "test-aks-updated"})
+	cleanupTestProviders(t, client, []string{"tf-acc-aks", "tf-acc-aks-updated"})
 
 	// Check if API is accessible
-	providers, errResp, err := client.GetAvailableProviders()
+	providers, errResp, err := client.GetAvailableProviders(context.Background())
 	if err != nil {
 		if errResp != nil {
 			t.Fatalf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
@@ -107,7 +488,7 @@ func testAccPreCheck(t *testing.T) {
 
 func cleanupTestProviders(t *testing.T, client *Client, names []string) {
 	// Get all installed providers
-	providers, errResp, err := client.GetInstalledProviders()
+	providers, errResp, err := client.GetInstalledProviders(context.Background())
 	if err != nil {
 		if errResp != nil {
 			t.Logf("Warning: API Error: %s. Details: %s", errResp.Error, errResp.Details)
@@ -124,26 +505,23 @@ func cleanupTestProviders(t *testing.T, client *Client, names []string) {
 
 	// Try to delete each matching provider
 	for _, provider := range providers {
-		p := provider.(map[string]interface{})
-		if details, ok := p["details"].(map[string]interface{}); ok {
-			if name, exists := details["name"].(string); exists && nameMap[name] {
-				providerType := p["type"].(string)
-				providerID := p["id"].(string)
-
-				// Try to delete the provider
-				errResp, err := client.DeleteProvider(providerType, providerID)
-				if err != nil {
-					if errResp != nil {
-						t.Logf("Warning: API Error: %s. Details: %s", errResp.Error, errResp.Details)
-					}
-					t.Logf("Warning: Failed to cleanup provider %s: %s", name, err)
-					continue
-				}
+		if name := provider.Details.Name; nameMap[name] {
+			providerType := provider.Type
+			providerID := string(provider.ID)
 
-				// Wait for deletion to complete
-				time.Sleep(2 * time.Second)
-				t.Logf("Successfully cleaned up provider %s", name)
+			// Try to delete the provider
+			errResp, err := client.DeleteProvider(context.Background(), providerType, providerID)
+			if err != nil {
+				if errResp != nil {
+					t.Logf("Warning: API Error: %s. Details: %s", errResp.Error, errResp.Details)
+				}
+				t.Logf("Warning: Failed to cleanup provider %s: %s", name, err)
+				continue
 			}
+
+			// Wait for deletion to complete
+			time.Sleep(2 * time.Second)
+			t.Logf("Successfully cleaned up provider %s", name)
 		}
 	}
 }
@@ -2,11 +2,21 @@ package keep
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/spf13/cast"
 )
 
 func resourceExtraction() *schema.Resource {
@@ -16,8 +26,18 @@ func resourceExtraction() *schema.Resource {
 		UpdateContext: resourceUpdateExtraction,
 		DeleteContext: resourceDeleteExtraction,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceImportExtraction,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		CustomizeDiff: customdiff.All(
+			customizeDiffExtractionSample,
+			customizeDiffExtractionAttribute,
+			customizeDiffExtractionEnrichedAttributes,
+		),
 		Schema: map[string]*schema.Schema{
 			"id": {
 				Type:        schema.TypeString,
@@ -25,9 +45,10 @@ func resourceExtraction() *schema.Resource {
 				Description: "ID of the extraction",
 			},
 			"name": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Name of the extraction",
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Required:     true,
+				Description:  "Name of the extraction",
 			},
 			"description": {
 				Type:        schema.TypeString,
@@ -36,21 +57,31 @@ func resourceExtraction() *schema.Resource {
 				Default:     "",
 			},
 			"priority": {
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Description: "Priority of the extraction",
-				Default:     0,
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntAtLeast(0),
+				Optional:     true,
+				Description:  "Priority of the extraction",
+				Default:      0,
 			},
 			"attribute": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Attribute of the extraction",
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Required:     true,
+				Description:  "Attribute of the extraction",
 			},
-			"condition": {
-				Type:        schema.TypeString,
+			"allow_custom_attribute": {
+				Type:        schema.TypeBool,
 				Optional:    true,
-				Description: "Condition of the extraction",
-				Default:     "",
+				Default:     false,
+				Description: "Skip validating attribute against the alert fields known to the backend",
+			},
+			"condition": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "CEL condition of the extraction",
+				Default:          "",
+				ValidateDiagFunc: validateCELCondition,
+				DiffSuppressFunc: suppressEquivalentCELDiff,
 			},
 			"disabled": {
 				Type:     schema.TypeBool,
@@ -58,9 +89,10 @@ func resourceExtraction() *schema.Resource {
 				Default:  false,
 			},
 			"regex": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Regex of the extraction",
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      "Regex of the extraction. Must contain at least one named capture group, e.g. `(?P<name>...)`",
+				ValidateDiagFunc: validateExtractionRegex,
 			},
 			"pre": {
 				Type:        schema.TypeBool,
@@ -68,25 +100,324 @@ func resourceExtraction() *schema.Resource {
 				Default:     false,
 				Description: "Pre of the extraction",
 			},
+			"on_duplicate_name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "ignore",
+				ValidateFunc: validation.StringInSlice([]string{"ignore", "error", "warn", "adopt"}, false),
+				Description:  "What to do on create if an extraction with the same name already exists: `ignore` (default), `error`, `warn` or `adopt` the existing extraction",
+			},
+			"disable_on_destroy": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{"strict_destroy"},
+				Description:   "Disable the extraction on destroy if the backend does not support deleting extractions, instead of only removing it from state",
+			},
+			"strict_destroy": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{"disable_on_destroy"},
+				Description:   "Fail the destroy if the backend does not support deleting extractions, instead of only removing it from state",
+			},
+			"sample": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "Sample alert payload (JSON) the extraction is applied to locally during plan, the result is exposed in sample_result",
+				ValidateFunc: validation.StringIsJSON,
+			},
+			"enriched_attributes": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "Attributes the extraction adds to alerts, the named capture groups of the regex. Pass them to enrichment_attributes of keep_workflow to validate the references of workflows",
+			},
+			"sample_result": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Attributes extracted from the sample payload, empty if the condition or the regex does not match",
+			},
+			"created_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Creation time of the extraction",
+			},
+			"created_by": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Creator of the extraction",
+			},
+			"updated_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Time of the last update of the extraction",
+			},
+			"updated_by": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "User who last updated the extraction",
+			},
 		},
 	}
 }
 
+// validateExtractionRegex checks that the regex compiles and has a named capture group,
+// since Keep uses the group names as the extracted attribute names
+func validateExtractionRegex(v interface{}, path cty.Path) diag.Diagnostics {
+	expr, ok := v.(string)
+	if !ok {
+		return diag.Errorf("expected regex to be a string")
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return diag.Diagnostics{{
+			Severity:      diag.Error,
+			Summary:       "Invalid regex",
+			Detail:        fmt.Sprintf("regex %q does not compile: %s", expr, err),
+			AttributePath: path,
+		}}
+	}
+
+	for _, name := range re.SubexpNames() {
+		if name != "" {
+			return nil
+		}
+	}
+
+	return diag.Diagnostics{{
+		Severity:      diag.Error,
+		Summary:       "Regex has no named capture group",
+		Detail:        fmt.Sprintf("regex %q must contain at least one named capture group, e.g. (?P<name>...)", expr),
+		AttributePath: path,
+	}}
+}
+
+// customizeDiffExtractionSample dry runs the extraction against the sample payload and plans sample_result
+func customizeDiffExtractionSample(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	for _, key := range []string{"sample", "attribute", "regex", "condition"} {
+		if !d.NewValueKnown(key) {
+			return d.SetNewComputed("sample_result")
+		}
+	}
+
+	sample := d.Get("sample").(string)
+	if sample == "" {
+		return d.SetNew("sample_result", map[string]interface{}{})
+	}
+
+	result, err := applyExtraction(sample, d.Get("attribute").(string), d.Get("regex").(string), d.Get("condition").(string))
+	if err != nil {
+		return fmt.Errorf("cannot apply extraction to sample: %s", err)
+	}
+
+	return d.SetNew("sample_result", result)
+}
+
+// customizeDiffExtractionEnrichedAttributes plans enriched_attributes from the regex
+func customizeDiffExtractionEnrichedAttributes(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("regex") {
+		return d.SetNewComputed("enriched_attributes")
+	}
+	return d.SetNew("enriched_attributes", extractionEnrichedAttributes(d.Get("regex").(string)))
+}
+
+// customizeDiffExtractionAttribute validates a changed attribute against the alert fields known to the backend.
+// Backends which do not report any fields, e.g. older ones without the endpoint, are not validated.
+func customizeDiffExtractionAttribute(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Get("allow_custom_attribute").(bool) || !d.HasChange("attribute") || !d.NewValueKnown("attribute") {
+		return nil
+	}
+
+	client := m.(KeepClient)
+	fields, _, err := client.GetAlertFields(ctx)
+	if err != nil {
+		tflog.Warn(ctx, "Cannot get the alert fields, the attribute of the extraction is not validated", map[string]interface{}{"error": err.Error()})
+		return nil
+	}
+
+	attribute := d.Get("attribute").(string)
+	if len(fields) == 0 || isKnownAlertField(attribute, fields) {
+		return nil
+	}
+
+	sort.Strings(fields)
+	return fmt.Errorf("attribute '%s' is not a known alert field, set allow_custom_attribute = true to use it anyway. Known fields: %v", attribute, fields)
+}
+
+// isKnownAlertField reports whether the attribute is one of the fields or a nested field of one of them
+func isKnownAlertField(attribute string, fields []string) bool {
+	for _, field := range fields {
+		if attribute == field || strings.HasPrefix(attribute, field+".") || strings.HasPrefix(field, attribute+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// applyExtraction mimics the backend: if the condition matches the alert, the regex is applied to the
+// attribute and every named capture group becomes an extracted attribute
+func applyExtraction(sample string, attribute string, expr string, condition string) (map[string]interface{}, error) {
+	var alert map[string]interface{}
+	if err := json.Unmarshal([]byte(sample), &alert); err != nil {
+		return nil, fmt.Errorf("sample is not a JSON object: %s", err)
+	}
+
+	result := make(map[string]interface{})
+
+	if condition != "" {
+		matches, err := evaluateCELCondition(condition, alert)
+		if err != nil {
+			return nil, err
+		}
+		if !matches {
+			return result, nil
+		}
+	}
+
+	value, ok := lookupAlertField(alert, attribute)
+	if !ok {
+		return result, nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	match := re.FindStringSubmatch(cast.ToString(value))
+	if match == nil {
+		return result, nil
+	}
+
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			result[name] = match[i]
+		}
+	}
+
+	return result, nil
+}
+
+// lookupAlertField resolves a dotted attribute path like "labels.priority" in an alert payload
+func lookupAlertField(alert map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = alert
+	for _, key := range strings.Split(path, ".") {
+		fields, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = fields[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// findExtractionIDsByName returns the ids of all extractions with the given name
+func findExtractionIDsByName(ctx context.Context, client KeepClient, name string) ([]string, error) {
+	extractions, errResp, err := client.GetExtractions(ctx)
+	if err != nil {
+		if errResp != nil {
+			return nil, fmt.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
+		}
+		return nil, fmt.Errorf("error reading extractions: %s", err)
+	}
+
+	ids := make([]string, 0)
+	for _, extraction := range extractions {
+		if extraction.Name == name {
+			ids = append(ids, string(extraction.ID))
+		}
+	}
+
+	return ids, nil
+}
+
+// resourceImportExtraction supports importing by ID or by name using the "name=<extraction-name>" syntax
+func resourceImportExtraction(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	// These attributes only exist in the configuration, use their defaults
+	if err := setAttributes(d, map[string]interface{}{
+		"allow_custom_attribute": false,
+		"disable_on_destroy":     false,
+		"strict_destroy":         false,
+		"on_duplicate_name":      "ignore",
+	}); err != nil {
+		return nil, err
+	}
+
+	id, err := resolveImportID(d.Id(), "extraction", func(name string) ([]string, error) {
+		return findExtractionIDsByName(ctx, m.(KeepClient), name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	d.SetId(id)
+	return []*schema.ResourceData{d}, nil
+}
+
+// extractionPayload builds the API payload of an extraction from the resource data
+func extractionPayload(d *schema.ResourceData) Extraction {
+	return Extraction{
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+		Priority:    d.Get("priority").(int),
+		Attribute:   d.Get("attribute").(string),
+		Condition:   d.Get("condition").(string),
+		Disabled:    d.Get("disabled").(bool),
+		Regex:       d.Get("regex").(string),
+		Pre:         d.Get("pre").(bool),
+	}
+}
+
 func resourceCreateExtraction(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Client)
+	client := m.(KeepClient)
+	name := d.Get("name").(string)
+
+	extraction := extractionPayload(d)
+
+	var diags diag.Diagnostics
+	if onDuplicate := d.Get("on_duplicate_name").(string); onDuplicate != "ignore" {
+		ids, err := findExtractionIDsByName(ctx, client, name)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		if len(ids) > 0 {
+			switch onDuplicate {
+			case "error":
+				return attributeErrorf(cty.GetAttrPath("name"), "extraction with name '%s' already exists (ids: %v)", name, ids)
+			case "warn":
+				diags = append(diags, diag.Diagnostic{
+					Severity:      diag.Warning,
+					Summary:       "Duplicate extraction name",
+					Detail:        fmt.Sprintf("extraction with name '%s' already exists (ids: %v), creating another one", name, ids),
+					AttributePath: cty.GetAttrPath("name"),
+				})
+			case "adopt":
+				if len(ids) > 1 {
+					return attributeErrorf(cty.GetAttrPath("name"), "cannot adopt extraction: multiple extractions with name '%s' found (ids: %v)", name, ids)
+				}
 
-	extraction := map[string]interface{}{
-		"name":        d.Get("name").(string),
-		"description": d.Get("description").(string),
-		"priority":    d.Get("priority").(int),
-		"attribute":   d.Get("attribute").(string),
-		"condition":   d.Get("condition").(string),
-		"disabled":    d.Get("disabled").(bool),
-		"regex":       d.Get("regex").(string),
-		"pre":         d.Get("pre").(bool),
+				errResp, err := client.UpdateExtraction(ctx, ids[0], extraction)
+				if err != nil {
+					if errResp != nil {
+						return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
+					}
+					return diag.Errorf("error adopting extraction: %s", err)
+				}
+
+				d.SetId(ids[0])
+				return resourceReadExtraction(ctx, d, m)
+			}
+		}
 	}
 
-	response, errResp, err := client.CreateExtraction(extraction)
+	response, errResp, err := client.CreateExtraction(ctx, extraction)
 	if err != nil {
 		if errResp != nil {
 			return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
@@ -94,34 +425,57 @@ func resourceCreateExtraction(ctx context.Context, d *schema.ResourceData, m int
 		return diag.Errorf("error creating extraction: %s", err)
 	}
 
-	if id, ok := response["id"]; ok {
-		d.SetId(fmt.Sprintf("%v", id))
-	} else {
+	if response.ID == "" {
 		return diag.Errorf("no id found in response")
 	}
+	d.SetId(string(response.ID))
 
-	return resourceReadExtraction(ctx, d, m)
+	return append(diags, resourceReadExtraction(ctx, d, m)...)
 }
 
-func resourceReadExtraction(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Client)
+// getExtraction fetches a single extraction by id. Backends without the single-extraction
+// endpoint answer with 405, in which case the full list is scanned instead, also for later reads.
+// A nil extraction without error means the extraction does not exist.
+func getExtraction(ctx context.Context, client KeepClient, id string) (*Extraction, *ErrorResponse, error) {
+	if client.supportsEndpoint(ctx, "GET", "/extraction/{rule_id}") {
+		extraction, errResp, err := client.GetExtraction(ctx, id)
+		if err == nil {
+			return extraction, nil, nil
+		}
 
-	extractions, errResp, err := client.GetExtractions()
+		if isNotFound(err) {
+			return nil, nil, nil
+		}
+
+		if !hasStatus(err, http.StatusMethodNotAllowed) {
+			return nil, errResp, err
+		}
+		client.rejectEndpoint("GET", "/extraction/{rule_id}")
+	}
+
+	extractions, errResp, err := client.GetExtractions(ctx)
 	if err != nil {
-		if errResp != nil {
-			return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
+		return nil, errResp, err
+	}
+
+	for i := range extractions {
+		if string(extractions[i].ID) == id {
+			return &extractions[i], nil, nil
 		}
-		return diag.Errorf("error reading extractions: %s", err)
 	}
 
-	id := d.Id()
-	var extraction map[string]interface{}
-	for _, e := range extractions {
-		ext := e.(map[string]interface{})
-		if fmt.Sprintf("%v", ext["id"]) == id {
-			extraction = ext
-			break
+	return nil, nil, nil
+}
+
+func resourceReadExtraction(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(KeepClient)
+
+	extraction, errResp, err := getExtraction(ctx, client, d.Id())
+	if err != nil {
+		if errResp != nil {
+			return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
 		}
+		return diag.Errorf("error reading extraction: %s", err)
 	}
 
 	if extraction == nil {
@@ -129,33 +483,35 @@ func resourceReadExtraction(ctx context.Context, d *schema.ResourceData, m inter
 		return nil
 	}
 
-	d.Set("name", extraction["name"])
-	d.Set("description", extraction["description"])
-	d.Set("priority", extraction["priority"])
-	d.Set("attribute", extraction["attribute"])
-	d.Set("condition", extraction["condition"])
-	d.Set("disabled", extraction["disabled"])
-	d.Set("regex", extraction["regex"])
-	d.Set("pre", extraction["pre"])
+	return setExtractionState(d, extraction)
+}
 
-	return nil
+// setExtractionState refreshes every attribute from the backend, missing and null values
+// are normalized to the zero values also used as defaults in the schema
+func setExtractionState(d *schema.ResourceData, extraction *Extraction) diag.Diagnostics {
+	return diag.FromErr(setAttributes(d, map[string]interface{}{
+		"name":                extraction.Name,
+		"description":         extraction.Description,
+		"priority":            extraction.Priority,
+		"attribute":           extraction.Attribute,
+		"condition":           extraction.Condition,
+		"disabled":            extraction.Disabled,
+		"regex":               extraction.Regex,
+		"enriched_attributes": extractionEnrichedAttributes(extraction.Regex),
+		"pre":                 extraction.Pre,
+		"created_at":          extraction.CreatedAt,
+		"created_by":          extraction.CreatedBy,
+		"updated_at":          extraction.UpdatedAt,
+		"updated_by":          extraction.UpdatedBy,
+	}))
 }
 
 func resourceUpdateExtraction(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Client)
+	client := m.(KeepClient)
 
-	extraction := map[string]interface{}{
-		"name":        d.Get("name").(string),
-		"description": d.Get("description").(string),
-		"priority":    d.Get("priority").(int),
-		"attribute":   d.Get("attribute").(string),
-		"condition":   d.Get("condition").(string),
-		"disabled":    d.Get("disabled").(bool),
-		"regex":       d.Get("regex").(string),
-		"pre":         d.Get("pre").(bool),
-	}
+	extraction := extractionPayload(d)
 
-	errResp, err := client.UpdateExtraction(d.Id(), extraction)
+	errResp, err := client.UpdateExtraction(ctx, d.Id(), extraction)
 	if err != nil {
 		if errResp != nil {
 			return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
@@ -167,37 +523,44 @@ func resourceUpdateExtraction(ctx context.Context, d *schema.ResourceData, m int
 }
 
 func resourceDeleteExtraction(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Client)
+	client := m.(KeepClient)
 
 	// First verify the extraction exists
-	extractions, errResp, err := client.GetExtractions()
+	id := d.Id()
+	extraction, errResp, err := getExtraction(ctx, client, id)
 	if err != nil {
 		if errResp != nil {
 			return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
 		}
-		return diag.Errorf("error reading extractions: %s", err)
+		return diag.Errorf("error reading extraction: %s", err)
 	}
 
-	id := d.Id()
-	exists := false
-	for _, e := range extractions {
-		ext := e.(map[string]interface{})
-		if fmt.Sprintf("%v", ext["id"]) == id {
-			exists = true
-			break
-		}
-	}
-
-	if !exists {
+	if extraction == nil {
 		d.SetId("")
 		return nil
 	}
 
-	errResp, err = client.DeleteExtraction(id)
+	errResp, err = client.DeleteExtraction(ctx, id)
 	if err != nil {
 		// If we get a 405, the API might not support DELETE
-		// In this case, we'll just remove it from state
-		if strings.Contains(err.Error(), "405") {
+		// In this case, we'll just remove it from state unless configured otherwise
+		if hasStatus(err, http.StatusMethodNotAllowed) {
+			if d.Get("strict_destroy").(bool) {
+				return diag.Errorf("error deleting extraction: the backend does not support deleting extractions (405)")
+			}
+
+			if d.Get("disable_on_destroy").(bool) {
+				payload := extractionPayload(d)
+				payload.Disabled = true
+				errResp, err := client.UpdateExtraction(ctx, id, payload)
+				if err != nil {
+					if errResp != nil {
+						return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
+					}
+					return diag.Errorf("error disabling extraction: %s", err)
+				}
+			}
+
 			d.SetId("")
 			return nil
 		}
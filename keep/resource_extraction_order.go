@@ -0,0 +1,207 @@
+package keep
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/spf13/cast"
+)
+
+func resourceExtractionOrder() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceApplyExtractionOrder,
+		ReadContext:   resourceReadExtractionOrder,
+		UpdateContext: resourceApplyExtractionOrder,
+		DeleteContext: resourceDeleteExtractionOrder,
+		CustomizeDiff: customizeDiffExtractionOrder,
+		Description: "Assigns consecutive priorities to extractions in the given order. " +
+			"Managed extractions should ignore changes to their `priority` attribute. " +
+			"Destroying the resource does not restore the previous priorities.",
+		Schema: map[string]*schema.Schema{
+			"extraction_ids": {
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the extractions, the first extraction gets the lowest priority",
+			},
+			"start_priority": {
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntAtLeast(0),
+				Optional:     true,
+				Default:      0,
+				Description:  "Priority of the first extraction, every following extraction gets the next priority",
+			},
+			"priorities": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+				Description: "Current priorities of the extractions by id",
+			},
+		},
+	}
+}
+
+func getExtractionOrderIDs(d *schema.ResourceData) []string {
+	list := d.Get("extraction_ids").([]interface{})
+	ids := make([]string, len(list))
+	for i, id := range list {
+		ids[i] = id.(string)
+	}
+	return ids
+}
+
+func resourceApplyExtractionOrder(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(KeepClient)
+	ids := getExtractionOrderIDs(d)
+	start := d.Get("start_priority").(int)
+
+	// Fetch all extractions first, so a missing one fails before any priority is changed
+	extractions := make([]*Extraction, len(ids))
+	seen := make(map[string]bool)
+	for i, id := range ids {
+		if seen[id] {
+			return attributeErrorf(cty.GetAttrPath("extraction_ids").IndexInt(i), "extraction %s is listed more than once", id)
+		}
+		seen[id] = true
+
+		extraction, errResp, err := getExtraction(ctx, client, id)
+		if err != nil {
+			if errResp != nil {
+				return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
+			}
+			return diag.Errorf("error reading extraction %s: %s", id, err)
+		}
+		if extraction == nil {
+			return attributeErrorf(cty.GetAttrPath("extraction_ids").IndexInt(i), "extraction %s not found", id)
+		}
+		extractions[i] = extraction
+	}
+
+	// Update the priorities, restoring the already updated extractions if an update fails
+	updated := make([]int, 0, len(ids))
+	for i, extraction := range extractions {
+		if extraction.Priority == start+i {
+			continue
+		}
+
+		payload := extraction.Payload()
+		payload.Priority = start + i
+
+		errResp, err := client.UpdateExtraction(ctx, ids[i], payload)
+		if err != nil {
+			summary := fmt.Sprintf("error updating priority of extraction %s: %s", ids[i], err)
+			if errResp != nil {
+				summary = fmt.Sprintf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
+			}
+			return restoreExtractionPriorities(ctx, d, m, summary, ids, extractions, updated, start)
+		}
+
+		updated = append(updated, i)
+	}
+
+	d.SetId(strings.Join(ids, ","))
+
+	return resourceReadExtractionOrder(ctx, d, m)
+}
+
+// restoreExtractionPriorities restores the previous priorities of the updated extractions after an update failed.
+// The diagnostic lists every changed extraction. If restoring fails, the priorities left in the backend are recorded
+// in state, so the next plan shows them.
+func restoreExtractionPriorities(ctx context.Context, d *schema.ResourceData, m interface{}, summary string, ids []string, extractions []*Extraction, updated []int, start int) diag.Diagnostics {
+	client := m.(KeepClient)
+
+	lines := make([]string, 0, len(updated))
+	restored := true
+	for _, i := range updated {
+		if _, err := client.UpdateExtraction(ctx, ids[i], extractions[i].Payload()); err != nil {
+			lines = append(lines, fmt.Sprintf("- %s: priority %d changed to %d, restoring failed: %s", ids[i], extractions[i].Priority, start+i, err))
+			restored = false
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("- %s: priority %d changed to %d and restored", ids[i], extractions[i].Priority, start+i))
+	}
+
+	detail := "No priority was changed."
+	if len(lines) > 0 {
+		detail = "Extractions changed before the failure:\n" + strings.Join(lines, "\n")
+	}
+	diags := diag.Diagnostics{{Severity: diag.Error, Summary: summary, Detail: detail}}
+
+	if restored {
+		return diags
+	}
+
+	d.SetId(strings.Join(ids, ","))
+	return append(diags, resourceReadExtractionOrder(ctx, d, m)...)
+}
+
+func resourceReadExtractionOrder(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(KeepClient)
+
+	// Deleted extractions are left out, which shows up as a diff
+	priorities := make(map[string]interface{})
+	for _, id := range getExtractionOrderIDs(d) {
+		extraction, errResp, err := getExtraction(ctx, client, id)
+		if err != nil {
+			if errResp != nil {
+				return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
+			}
+			return diag.Errorf("error reading extraction %s: %s", id, err)
+		}
+
+		if extraction != nil {
+			priorities[id] = extraction.Priority
+		}
+	}
+
+	if err := d.Set("priorities", priorities); err != nil {
+		return diag.Errorf("Failed to set priorities: %s", err.Error())
+	}
+
+	return nil
+}
+
+// customizeDiffExtractionOrder plans an update if the priorities in the backend do not match the configured order,
+// e.g. because they were changed outside of terraform
+func customizeDiffExtractionOrder(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("extraction_ids") || !d.NewValueKnown("start_priority") {
+		return d.SetNewComputed("priorities")
+	}
+
+	list := d.Get("extraction_ids").([]interface{})
+	start := d.Get("start_priority").(int)
+	priorities := d.Get("priorities").(map[string]interface{})
+
+	expected := make(map[string]interface{}, len(list))
+	for i, id := range list {
+		// Ids of extractions which are created in the same apply are not known yet
+		if id == nil || id.(string) == "" {
+			return d.SetNewComputed("priorities")
+		}
+		expected[id.(string)] = start + i
+	}
+
+	if len(priorities) != len(expected) {
+		return d.SetNew("priorities", expected)
+	}
+
+	for id, priority := range expected {
+		if cast.ToInt(priorities[id]) != priority {
+			return d.SetNew("priorities", expected)
+		}
+	}
+
+	return nil
+}
+
+func resourceDeleteExtractionOrder(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// The priorities are kept as they are, only the order stops being managed
+	d.SetId("")
+	return nil
+}
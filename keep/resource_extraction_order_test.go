@@ -0,0 +1,193 @@
+package keep
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccKeepExtractionOrder_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckKeepExtractionDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccKeepExtractionOrderConfig("first", "second"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKeepExtractionPriority("keep_extraction.first", 10),
+					testAccCheckKeepExtractionPriority("keep_extraction.second", 11),
+				),
+			},
+			{
+				Config: testAccKeepExtractionOrderConfig("second", "first"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckKeepExtractionPriority("keep_extraction.second", 10),
+					testAccCheckKeepExtractionPriority("keep_extraction.first", 11),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckKeepExtractionPriority(n string, expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		client := testAccProvider.Meta().(*Client)
+		extraction, _, err := getExtraction(context.Background(), client, rs.Primary.ID)
+		if err != nil {
+			return fmt.Errorf("Error reading extraction: %s", err)
+		}
+		if extraction == nil {
+			return fmt.Errorf("Extraction not found")
+		}
+
+		if extraction.Priority != expected {
+			return fmt.Errorf("expected priority %d, got %d", expected, extraction.Priority)
+		}
+
+		return nil
+	}
+}
+
+func testAccKeepExtractionOrderConfig(first, second string) string {
+	return testAccProviderConfig(os.Getenv("KEEP_BACKEND_URL"), os.Getenv("KEEP_API_KEY")) + fmt.Sprintf(`
+resource "keep_extraction" "first" {
+  name      = "tf-acc-order-first"
+  attribute = "message"
+  regex     = "first: (?P<first>.*)"
+
+  lifecycle {
+    ignore_changes = [priority]
+  }
+}
+
+resource "keep_extraction" "second" {
+  name      = "tf-acc-order-second"
+  attribute = "message"
+  regex     = "second: (?P<second>.*)"
+
+  lifecycle {
+    ignore_changes = [priority]
+  }
+}
+
+resource "keep_extraction_order" "test" {
+  start_priority = 10
+  extraction_ids = [
+    keep_extraction.%s.id,
+    keep_extraction.%s.id,
+  ]
+}`, first, second)
+}
+
+func TestResourceExtractionOrder_MockBackend(t *testing.T) {
+	backend := newMockBackend(t)
+	client := backend.client()
+	r := resourceExtractionOrder()
+
+	for _, priority := range []int{5, 5, 11} {
+		id := backend.newID()
+		backend.extractions[id] = Extraction{ID: apiID(id), Name: "extraction-" + id, Attribute: "message", Regex: "(?P<x>.*)", Priority: priority}
+	}
+
+	config := map[string]interface{}{
+		"extraction_ids": []interface{}{"2", "1", "3"},
+		"start_priority": 10,
+	}
+
+	state, diags := applyMockResource(t, r, nil, config, client)
+	if diags.HasError() {
+		t.Fatalf("unexpected error on create: %v", diags)
+	}
+	for id, expected := range map[string]int{"2": 10, "1": 11, "3": 12} {
+		if backend.extractions[id].Priority != expected {
+			t.Errorf("expected priority %d of extraction %s, got %d", expected, id, backend.extractions[id].Priority)
+		}
+		if state.Attributes["priorities."+id] != fmt.Sprint(expected) {
+			t.Errorf("expected priority %d of extraction %s in state, got %v", expected, id, state.Attributes)
+		}
+	}
+
+	// priorities changed outside of terraform are restored
+	extraction := backend.extractions["1"]
+	extraction.Priority = 0
+	backend.extractions["1"] = extraction
+	if state, diags = refreshMockResource(t, r, state, client); diags.HasError() {
+		t.Fatalf("unexpected error on refresh: %v", diags)
+	}
+	if state, diags = applyMockResource(t, r, state, config, client); diags.HasError() {
+		t.Fatalf("unexpected error on update: %v", diags)
+	}
+	if backend.extractions["1"].Priority != 11 {
+		t.Errorf("expected the priority to be restored, got %d", backend.extractions["1"].Priority)
+	}
+
+	config["extraction_ids"] = []interface{}{"1", "4"}
+	if _, diags := applyMockResource(t, r, state, config, client); !diags.HasError() {
+		t.Error("expected an error for a missing extraction")
+	}
+}
+
+// failingUpdateClient fails the updates of extractions to the given priorities, e.g. "2:11"
+type failingUpdateClient struct {
+	KeepClient
+	failing map[string]bool
+}
+
+func (c *failingUpdateClient) UpdateExtraction(ctx context.Context, id string, extraction Extraction) (*ErrorResponse, error) {
+	if c.failing[fmt.Sprintf("%s:%d", id, extraction.Priority)] {
+		return nil, fmt.Errorf("update of extraction %s failed", id)
+	}
+	return c.KeepClient.UpdateExtraction(ctx, id, extraction)
+}
+
+func TestResourceExtractionOrder_PartialFailure(t *testing.T) {
+	backend := newMockBackend(t)
+	r := resourceExtractionOrder()
+
+	for _, priority := range []int{5, 5, 5} {
+		id := backend.newID()
+		backend.extractions[id] = Extraction{ID: apiID(id), Name: "extraction-" + id, Attribute: "message", Regex: "(?P<x>.*)", Priority: priority}
+	}
+
+	config := map[string]interface{}{
+		"extraction_ids": []interface{}{"1", "2", "3"},
+		"start_priority": 10,
+	}
+
+	// the updated extraction is restored and listed in the diagnostic
+	client := &failingUpdateClient{KeepClient: backend.client(), failing: map[string]bool{"2:11": true}}
+	state, diags := applyMockResource(t, r, nil, config, client)
+	if !diags.HasError() || state != nil {
+		t.Fatalf("expected the failed update to be reported without state, got %v, %v", state, diags)
+	}
+	if !strings.Contains(diags[0].Detail, "- 1: priority 5 changed to 10 and restored") {
+		t.Errorf("expected the restored extraction to be listed, got %q", diags[0].Detail)
+	}
+	if backend.extractions["1"].Priority != 5 {
+		t.Errorf("expected the priority to be restored, got %d", backend.extractions["1"].Priority)
+	}
+
+	// if restoring fails, the priorities left in the backend are recorded in state
+	client.failing = map[string]bool{"2:5": true, "3:12": true}
+	state, diags = applyMockResource(t, r, nil, config, client)
+	if !diags.HasError() || state == nil {
+		t.Fatalf("expected the failed update to be reported with state, got %v, %v", state, diags)
+	}
+	if !strings.Contains(diags[0].Detail, "- 2: priority 5 changed to 11, restoring failed") {
+		t.Errorf("expected the changed extraction to be listed, got %q", diags[0].Detail)
+	}
+	if state.Attributes["priorities.2"] != "11" {
+		t.Errorf("expected the changed priority in state, got %v", state.Attributes)
+	}
+}
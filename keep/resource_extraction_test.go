@@ -1,11 +1,13 @@
 package keep
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"regexp"
 	"testing"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
@@ -19,39 +21,40 @@ func TestAccKeepExtraction_basic(t *testing.T) {
 			{
 				Config: testAccProviderConfig(os.Getenv("KEEP_BACKEND_URL"), os.Getenv("KEEP_API_KEY")) + `
 resource "keep_extraction" "test" {
-  name        = "error-pattern"
+  name        = "tf-acc-error-pattern"
   description = "Extract error patterns from logs"
   priority    = 1
   attribute   = "message"
-  regex       = "error: (.*)"
+  regex       = "error: (?P<error>.*)"
   disabled    = false
   pre         = false
 }`,
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckKeepExtractionExists("keep_extraction.test"),
-					resource.TestCheckResourceAttr("keep_extraction.test", "name", "error-pattern"),
+					resource.TestCheckResourceAttr("keep_extraction.test", "name", "tf-acc-error-pattern"),
 					resource.TestCheckResourceAttr("keep_extraction.test", "description", "Extract error patterns from logs"),
 					resource.TestCheckResourceAttr("keep_extraction.test", "priority", "1"),
 					resource.TestCheckResourceAttr("keep_extraction.test", "attribute", "message"),
-					resource.TestCheckResourceAttr("keep_extraction.test", "regex", "error: (.*)"),
+					resource.TestCheckResourceAttr("keep_extraction.test", "regex", "error: (?P<error>.*)"),
 					resource.TestCheckResourceAttr("keep_extraction.test", "disabled", "false"),
 					resource.TestCheckResourceAttr("keep_extraction.test", "pre", "false"),
+					resource.TestCheckResourceAttrSet("keep_extraction.test", "created_at"),
 				),
 			},
 			{
 				Config: testAccProviderConfig(os.Getenv("KEEP_BACKEND_URL"), os.Getenv("KEEP_API_KEY")) + `
 resource "keep_extraction" "test" {
-  name        = "updated-error-pattern"
+  name        = "tf-acc-updated-error-pattern"
   description = "Updated error pattern extraction"
   priority    = 2
   attribute   = "message"
-  regex       = "error\\[([^\\]]+)\\]"
+  regex       = "error\\[(?P<code>[^\\]]+)\\]"
   disabled    = true
   pre         = false
 }`,
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckKeepExtractionExists("keep_extraction.test"),
-					resource.TestCheckResourceAttr("keep_extraction.test", "name", "updated-error-pattern"),
+					resource.TestCheckResourceAttr("keep_extraction.test", "name", "tf-acc-updated-error-pattern"),
 					resource.TestCheckResourceAttr("keep_extraction.test", "description", "Updated error pattern extraction"),
 					resource.TestCheckResourceAttr("keep_extraction.test", "priority", "2"),
 					resource.TestCheckResourceAttr("keep_extraction.test", "disabled", "true"),
@@ -73,7 +76,7 @@ func testAccCheckKeepExtractionExists(n string) resource.TestCheckFunc {
 		}
 
 		client := testAccProvider.Meta().(*Client)
-		extractions, errResp, err := client.GetExtractions()
+		extractions, errResp, err := client.GetExtractions(context.Background())
 		if err != nil {
 			if errResp != nil {
 				return fmt.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
@@ -81,9 +84,8 @@ func testAccCheckKeepExtractionExists(n string) resource.TestCheckFunc {
 			return fmt.Errorf("Error checking extraction existence: %s", err)
 		}
 
-		for _, e := range extractions {
-			extraction := e.(map[string]interface{})
-			if fmt.Sprintf("%v", extraction["id"]) == rs.Primary.ID {
+		for _, extraction := range extractions {
+			if string(extraction.ID) == rs.Primary.ID {
 				return nil
 			}
 		}
@@ -100,7 +102,7 @@ func testAccCheckKeepExtractionDestroy(s *terraform.State) error {
 			continue
 		}
 
-		extractions, errResp, err := client.GetExtractions()
+		extractions, errResp, err := client.GetExtractions(context.Background())
 		if err != nil {
 			if errResp != nil {
 				// Ignore API errors during destroy check as the resource might be already gone
@@ -109,9 +111,8 @@ func testAccCheckKeepExtractionDestroy(s *terraform.State) error {
 			return nil // Consider any error as the resource being gone
 		}
 
-		for _, e := range extractions {
-			extraction := e.(map[string]interface{})
-			if fmt.Sprintf("%v", extraction["id"]) == rs.Primary.ID {
+		for _, extraction := range extractions {
+			if string(extraction.ID) == rs.Primary.ID {
 				return fmt.Errorf("Extraction still exists")
 			}
 		}
@@ -143,11 +144,11 @@ func TestAccKeepExtraction_import(t *testing.T) {
 			{
 				Config: testAccProviderConfig(os.Getenv("KEEP_BACKEND_URL"), os.Getenv("KEEP_API_KEY")) + `
 resource "keep_extraction" "test" {
-  name        = "error-pattern"
+  name        = "tf-acc-error-pattern"
   description = "Extract error patterns from logs"
   priority    = 1
   attribute   = "message"
-  regex       = "error: (.*)"
+  regex       = "error: (?P<error>.*)"
   disabled    = false
   pre         = false
 }`,
@@ -157,6 +158,12 @@ resource "keep_extraction" "test" {
 				ImportState:       true,
 				ImportStateVerify: true,
 			},
+			{
+				ResourceName:      "keep_extraction.test",
+				ImportState:       true,
+				ImportStateId:     "name=tf-acc-error-pattern",
+				ImportStateVerify: true,
+			},
 		},
 	})
 }
@@ -169,7 +176,7 @@ provider "keep" {
 }
 
 resource "keep_extraction" "test" {
-  name        = "missing_required_test"
+  name        = "tf-acc-missing-required"
   description = "Test extraction with missing required field"
   priority    = 1
   attribute   = "message"
@@ -178,3 +185,289 @@ resource "keep_extraction" "test" {
 }
 `, os.Getenv("KEEP_BACKEND_URL"), os.Getenv("KEEP_API_KEY"))
 }
+
+func TestValidateExtractionRegex(t *testing.T) {
+	cases := []struct {
+		regex    string
+		hasError bool
+	}{
+		{regex: "error: (?P<error>.*)", hasError: false},
+		{regex: "(?P<service>[a-z]+)-(?P<env>prod|dev)", hasError: false},
+		{regex: "error: (.*)", hasError: true},
+		{regex: "error: (?P<error>.*", hasError: true},
+	}
+
+	for _, tc := range cases {
+		diags := validateExtractionRegex(tc.regex, cty.GetAttrPath("regex"))
+		if diags.HasError() != tc.hasError {
+			t.Errorf("regex %q: expected error %t, got %v", tc.regex, tc.hasError, diags)
+		}
+	}
+}
+
+func TestValidateCELCondition(t *testing.T) {
+	cases := []struct {
+		condition string
+		hasError  bool
+	}{
+		{condition: "", hasError: false},
+		{condition: `source == "prometheus"`, hasError: false},
+		{condition: `labels.env == "prod" && severity != "info"`, hasError: false},
+		{condition: `source == `, hasError: true},
+		{condition: `source = "prometheus"`, hasError: true},
+		{condition: `customer == "acme" && service != null`, hasError: false},
+		{condition: `firingCounter > "3"`, hasError: true},
+		{condition: `severity > 3`, hasError: true},
+		{condition: `name`, hasError: true},
+	}
+
+	for _, tc := range cases {
+		diags := validateCELCondition(tc.condition, cty.GetAttrPath("condition"))
+		if diags.HasError() != tc.hasError {
+			t.Errorf("condition %q: expected error %t, got %v", tc.condition, tc.hasError, diags)
+		}
+	}
+}
+
+func TestApplyExtraction(t *testing.T) {
+	sample := `{"source": ["prometheus"], "name": "HighLatency", "labels": {"service": "checkout-prod"}}`
+
+	cases := []struct {
+		name      string
+		attribute string
+		regex     string
+		condition string
+		expected  map[string]interface{}
+	}{
+		{
+			name:      "nested attribute",
+			attribute: "labels.service",
+			regex:     "(?P<service>[a-z]+)-(?P<env>[a-z]+)",
+			expected:  map[string]interface{}{"service": "checkout", "env": "prod"},
+		},
+		{
+			name:      "condition matches",
+			attribute: "name",
+			regex:     "(?P<kind>High|Low)",
+			condition: `name.startsWith("High")`,
+			expected:  map[string]interface{}{"kind": "High"},
+		},
+		{
+			name:      "condition does not match",
+			attribute: "name",
+			regex:     "(?P<kind>High|Low)",
+			condition: `name == "other"`,
+			expected:  map[string]interface{}{},
+		},
+		{
+			name:      "missing attribute",
+			attribute: "labels.team",
+			regex:     "(?P<team>.*)",
+			expected:  map[string]interface{}{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := applyExtraction(sample, tc.attribute, tc.regex, tc.condition)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if len(result) != len(tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, result)
+			}
+			for k, v := range tc.expected {
+				if result[k] != v {
+					t.Errorf("expected %s=%v, got %v", k, v, result[k])
+				}
+			}
+		})
+	}
+}
+
+func TestIsKnownAlertField(t *testing.T) {
+	fields := []string{"name", "source", "labels.priority", "annotations"}
+
+	cases := map[string]bool{
+		"name":                true,
+		"labels.priority":     true,
+		"labels":              true,
+		"annotations.runbook": true,
+		"lables.priority":     false,
+		"message":             false,
+	}
+
+	for attribute, expected := range cases {
+		if actual := isKnownAlertField(attribute, fields); actual != expected {
+			t.Errorf("attribute %q: expected %t, got %t", attribute, expected, actual)
+		}
+	}
+}
+
+func TestSuppressEquivalentCELDiff(t *testing.T) {
+	cases := []struct {
+		old      string
+		new      string
+		suppress bool
+	}{
+		{old: `source == "prometheus"`, new: `source=="prometheus"`, suppress: true},
+		{old: `(source == "prometheus")`, new: `source == "prometheus"`, suppress: true},
+		{old: `source == "prometheus"`, new: `source == "grafana"`, suppress: false},
+		{old: "", new: `source == "grafana"`, suppress: false},
+	}
+
+	for _, tc := range cases {
+		if actual := suppressEquivalentCELDiff("condition", tc.old, tc.new, nil); actual != tc.suppress {
+			t.Errorf("%q -> %q: expected suppress %t, got %t", tc.old, tc.new, tc.suppress, actual)
+		}
+	}
+}
+
+func TestResourceExtraction_MockBackend(t *testing.T) {
+	backend := newMockBackend(t)
+	client := backend.client()
+	r := resourceExtraction()
+
+	config := map[string]interface{}{
+		"name":      "error-pattern",
+		"attribute": "message",
+		"regex":     "error: (?P<error>.*)",
+		"priority":  1,
+	}
+
+	state, diags := applyMockResource(t, r, nil, config, client)
+	if diags.HasError() {
+		t.Fatalf("unexpected error on create: %v", diags)
+	}
+	if extraction := backend.extractions[state.ID]; extraction.Name != "error-pattern" || extraction.Priority != 1 {
+		t.Fatalf("unexpected extraction in the backend: %+v", backend.extractions)
+	}
+	if state.Attributes["created_by"] != "keep" {
+		t.Errorf("expected the state to be read after create, got %v", state.Attributes)
+	}
+	if state.Attributes["enriched_attributes.#"] != "1" {
+		t.Errorf("expected the capture group as enriched attribute, got %v", state.Attributes)
+	}
+
+	config["regex"] = "failed: (?P<error>.*)"
+	if state, diags = applyMockResource(t, r, state, config, client); diags.HasError() {
+		t.Fatalf("unexpected error on update: %v", diags)
+	}
+	if backend.extractions[state.ID].Regex != "failed: (?P<error>.*)" {
+		t.Errorf("expected the regex to be updated, got %+v", backend.extractions[state.ID])
+	}
+
+	// changes outside of terraform are detected
+	extraction := backend.extractions[state.ID]
+	extraction.Disabled = true
+	backend.extractions[state.ID] = extraction
+	if state, diags = refreshMockResource(t, r, state, client); diags.HasError() || state.Attributes["disabled"] != "true" {
+		t.Errorf("expected the refresh to detect the change, got %v, %v", state, diags)
+	}
+
+	config["attribute"] = "unknown"
+	if _, diags := applyMockResource(t, r, state, config, client); !diags.HasError() {
+		t.Error("expected an error for an attribute unknown to the backend")
+	}
+	config["attribute"] = "message"
+
+	if state, diags = applyMockResource(t, r, state, nil, client); diags.HasError() || state != nil {
+		t.Fatalf("unexpected destroy result: %v, %v", state, diags)
+	}
+	if len(backend.extractions) != 0 {
+		t.Errorf("expected the extraction to be deleted, got %+v", backend.extractions)
+	}
+}
+
+func TestResourceExtraction_MockBackendAttributeValidation(t *testing.T) {
+	config := map[string]interface{}{
+		"name":      "error-pattern",
+		"attribute": "labels.custom",
+		"regex":     "error: (?P<error>.*)",
+	}
+
+	backend := newMockBackend(t)
+	if _, diags := applyMockResource(t, resourceExtraction(), nil, config, backend.client()); !diags.HasError() {
+		t.Error("expected an attribute which isn't an alert field to fail the plan")
+	}
+
+	// backends without the alert fields endpoint don't fail the plan
+	backend = newMockBackend(t, "GET /alerts/facets/fields")
+	if _, diags := applyMockResource(t, resourceExtraction(), nil, config, backend.client()); diags.HasError() {
+		t.Errorf("expected the attribute not to be validated, got %v", diags)
+	}
+}
+
+func TestResourceExtraction_MockBackendWithoutDelete(t *testing.T) {
+	backend := newMockBackend(t, "DELETE /extraction/{rule_id}")
+	client := backend.client()
+	r := resourceExtraction()
+
+	config := map[string]interface{}{
+		"name":               "error-pattern",
+		"attribute":          "message",
+		"regex":              "error: (?P<error>.*)",
+		"disable_on_destroy": true,
+	}
+
+	state, diags := applyMockResource(t, r, nil, config, client)
+	if diags.HasError() {
+		t.Fatalf("unexpected error on create: %v", diags)
+	}
+	id := state.ID
+
+	if state, diags = applyMockResource(t, r, state, nil, client); diags.HasError() || state != nil {
+		t.Fatalf("unexpected destroy result: %v, %v", state, diags)
+	}
+	if !backend.extractions[id].Disabled {
+		t.Errorf("expected the extraction to be disabled, got %+v", backend.extractions[id])
+	}
+	if backend.requestCount("GET", "/extraction/"+id) != 0 {
+		t.Error("expected the extraction to be read from the list, since the backend does not list the endpoint")
+	}
+}
+
+// mockExtractionClient serves extractions from memory, methods of other objects are not implemented
+type mockExtractionClient struct {
+	KeepClient
+	extractions map[string]Extraction
+}
+
+func (m *mockExtractionClient) supportsEndpoint(ctx context.Context, method, path string) bool {
+	return true
+}
+
+func (m *mockExtractionClient) rejectEndpoint(method, path string) {}
+
+func (m *mockExtractionClient) GetExtraction(ctx context.Context, id string) (*Extraction, *ErrorResponse, error) {
+	extraction, ok := m.extractions[id]
+	if !ok {
+		errResp, err := mockAPIError(404, "Extraction not found")
+		return nil, errResp, err
+	}
+	return &extraction, nil, nil
+}
+
+func TestResourceReadExtraction(t *testing.T) {
+	client := &mockExtractionClient{extractions: map[string]Extraction{
+		"1": {ID: "1", Name: "error-pattern", Attribute: "message", Regex: "error: (?P<error>.*)", Priority: 2},
+	}}
+
+	d := resourceExtraction().TestResourceData()
+	d.SetId("1")
+	if diags := resourceReadExtraction(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if d.Get("name") != "error-pattern" || d.Get("priority") != 2 {
+		t.Errorf("expected the extraction to be read, got name %v and priority %v", d.Get("name"), d.Get("priority"))
+	}
+
+	delete(client.extractions, "1")
+	if diags := resourceReadExtraction(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if d.Id() != "" {
+		t.Errorf("expected the deleted extraction to be removed from state, got id %q", d.Id())
+	}
+}
@@ -0,0 +1,345 @@
+package keep
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/spf13/cast"
+	"gopkg.in/yaml.v2"
+)
+
+// extractionDefinition is a single extraction of a definitions file
+type extractionDefinition struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Priority    int    `yaml:"priority"`
+	Attribute   string `yaml:"attribute"`
+	Condition   string `yaml:"condition"`
+	Disabled    bool   `yaml:"disabled"`
+	Regex       string `yaml:"regex"`
+	Pre         bool   `yaml:"pre"`
+}
+
+func (e extractionDefinition) payload() Extraction {
+	return Extraction{
+		Name:        e.Name,
+		Description: e.Description,
+		Priority:    e.Priority,
+		Attribute:   e.Attribute,
+		Condition:   e.Condition,
+		Disabled:    e.Disabled,
+		Regex:       e.Regex,
+		Pre:         e.Pre,
+	}
+}
+
+// matches reports whether an extraction read from the backend is configured like the definition. Conditions are
+// compared in their normalized form, like the condition of keep_extraction.
+func (e extractionDefinition) matches(remote extractionDefinition) bool {
+	return e.Name == remote.Name &&
+		e.Description == remote.Description &&
+		e.Priority == remote.Priority &&
+		e.Attribute == remote.Attribute &&
+		e.Disabled == remote.Disabled &&
+		e.Regex == remote.Regex &&
+		e.Pre == remote.Pre &&
+		equivalentCELExpressions(e.Condition, remote.Condition)
+}
+
+// extractionDefinitionFromRemote returns the definition of an extraction read from the backend
+func extractionDefinitionFromRemote(e *Extraction) extractionDefinition {
+	return extractionDefinition{
+		Name:        e.Name,
+		Description: e.Description,
+		Priority:    e.Priority,
+		Attribute:   e.Attribute,
+		Condition:   e.Condition,
+		Disabled:    e.Disabled,
+		Regex:       e.Regex,
+		Pre:         e.Pre,
+	}
+}
+
+func resourceExtractions() *schema.Resource {
+	hasher := &FileHasher{
+		HashField:     "definitions_content_hash",
+		Description:   "Hash of the definitions file content for change detection",
+		UpdateInPlace: true,
+	}
+
+	schemaMap := map[string]*schema.Schema{
+		"definitions_file": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Path of a YAML file listing the extractions under the `extractions` key",
+		},
+		"extraction_ids": {
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "IDs of the managed extractions by name",
+		},
+		"drifted_extractions": {
+			Type:        schema.TypeSet,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "Names of the managed extractions which were changed outside of terraform and are updated on the next apply",
+		},
+	}
+
+	hasher.AddHashFieldToSchema(schemaMap)
+
+	return &schema.Resource{
+		CreateContext: resourceCreateExtractions,
+		ReadContext:   resourceReadExtractions,
+		UpdateContext: resourceUpdateExtractions,
+		DeleteContext: resourceDeleteExtractions,
+		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+			hasher.FilePath = d.Get("definitions_file").(string)
+			if err := hasher.CustomizeDiff(ctx, d); err != nil {
+				return err
+			}
+
+			definitions, err := readExtractionDefinitions(hasher.FilePath)
+			if err != nil {
+				return err
+			}
+
+			// Reconcile if the file changed or managed extractions were deleted or changed outside of terraform
+			ids := d.Get("extraction_ids").(map[string]interface{})
+			reconcile := d.HasChange(hasher.HashField) || len(ids) != len(definitions) || d.Get("drifted_extractions").(*schema.Set).Len() > 0
+
+			for _, e := range definitions {
+				if _, ok := ids[e.Name]; !ok {
+					reconcile = true
+				}
+			}
+
+			if !reconcile {
+				return nil
+			}
+			if err := d.SetNewComputed("drifted_extractions"); err != nil {
+				return err
+			}
+			return d.SetNewComputed("extraction_ids")
+		},
+		Schema: schemaMap,
+	}
+}
+
+// readExtractionDefinitions parses and validates a definitions file
+func readExtractionDefinitions(filePath string) ([]extractionDefinition, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read definitions file: %s", err)
+	}
+
+	var file struct {
+		Extractions []extractionDefinition `yaml:"extractions"`
+	}
+	if err := yaml.UnmarshalStrict(content, &file); err != nil {
+		return nil, fmt.Errorf("invalid definitions file %s: %s", filePath, err)
+	}
+
+	names := make(map[string]bool)
+	for i, e := range file.Extractions {
+		if e.Name == "" || e.Attribute == "" || e.Regex == "" {
+			return nil, fmt.Errorf("extraction %d in %s: name, attribute and regex are required", i, filePath)
+		}
+
+		if names[e.Name] {
+			return nil, fmt.Errorf("extraction %d in %s: duplicate name '%s'", i, filePath, e.Name)
+		}
+		names[e.Name] = true
+
+		if diags := validateExtractionRegex(e.Regex, cty.Path{}); diags.HasError() {
+			return nil, fmt.Errorf("extraction '%s' in %s: %s", e.Name, filePath, diags[0].Detail)
+		}
+
+		if diags := validateCELCondition(e.Condition, cty.Path{}); diags.HasError() {
+			return nil, fmt.Errorf("extraction '%s' in %s: %s", e.Name, filePath, diags[0].Detail)
+		}
+	}
+
+	return file.Extractions, nil
+}
+
+// reconcileExtractions creates or updates every definition and deletes the
+// previously managed extractions which are no longer defined. The result keeps
+// every extraction which still exists, also if an error stopped the reconciliation.
+func reconcileExtractions(ctx context.Context, client KeepClient, definitions []extractionDefinition, ids map[string]interface{}) (map[string]interface{}, diag.Diagnostics) {
+	result := make(map[string]interface{}, len(ids))
+	for name, id := range ids {
+		result[name] = id
+	}
+
+	defined := make(map[string]bool, len(definitions))
+
+	for _, e := range definitions {
+		defined[e.Name] = true
+
+		if id, ok := ids[e.Name]; ok {
+			errResp, err := client.UpdateExtraction(ctx, cast.ToString(id), e.payload())
+			if err != nil {
+				if errResp != nil {
+					return result, diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
+				}
+				return result, diag.Errorf("error updating extraction '%s': %s", e.Name, err)
+			}
+			continue
+		}
+
+		response, errResp, err := client.CreateExtraction(ctx, e.payload())
+		if err != nil {
+			if errResp != nil {
+				return result, diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
+			}
+			return result, diag.Errorf("error creating extraction '%s': %s", e.Name, err)
+		}
+
+		if response.ID == "" {
+			return result, diag.Errorf("no id found in response for extraction '%s'", e.Name)
+		}
+		result[e.Name] = string(response.ID)
+	}
+
+	for name, id := range ids {
+		if defined[name] {
+			continue
+		}
+
+		errResp, err := client.DeleteExtraction(ctx, cast.ToString(id))
+		if err != nil {
+			// The extraction would be left active on the backend while it is no longer managed
+			if hasStatus(err, http.StatusMethodNotAllowed) {
+				return result, diag.Errorf("error deleting extraction '%s': the backend does not support deleting extractions (405)", name)
+			}
+			if errResp != nil {
+				return result, diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
+			}
+			return result, diag.Errorf("error deleting extraction '%s': %s", name, err)
+		}
+		delete(result, name)
+	}
+
+	return result, nil
+}
+
+func resourceCreateExtractions(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(KeepClient)
+	filePath := d.Get("definitions_file").(string)
+
+	definitions, err := readExtractionDefinitions(filePath)
+	if err != nil {
+		return attributeErrorf(cty.GetAttrPath("definitions_file"), "%s", err)
+	}
+
+	hasher := &FileHasher{
+		FilePath:  filePath,
+		HashField: "definitions_content_hash",
+	}
+	if err := hasher.SetFileHash(d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	ids, diags := reconcileExtractions(ctx, client, definitions, map[string]interface{}{})
+	if len(ids) == 0 && diags.HasError() {
+		return diags
+	}
+
+	// Keep the extractions created so far in state, even if a later one failed
+	d.SetId(filePath)
+	if err := d.Set("extraction_ids", ids); err != nil {
+		diags = append(diags, diag.Errorf("Failed to set extraction_ids: %s", err.Error())...)
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	return resourceReadExtractions(ctx, d, m)
+}
+
+func resourceReadExtractions(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(KeepClient)
+
+	// Without a readable definitions file there is nothing to compare to, planning reports the file error
+	definitions := make(map[string]extractionDefinition)
+	if parsed, err := readExtractionDefinitions(d.Get("definitions_file").(string)); err == nil {
+		for _, e := range parsed {
+			definitions[e.Name] = e
+		}
+	}
+
+	ids := make(map[string]interface{})
+	var drifted []interface{}
+	for name, id := range d.Get("extraction_ids").(map[string]interface{}) {
+		extraction, errResp, err := getExtraction(ctx, client, cast.ToString(id))
+		if err != nil {
+			if errResp != nil {
+				return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
+			}
+			return diag.Errorf("error reading extraction '%s': %s", name, err)
+		}
+
+		// Extractions deleted outside of terraform are recreated on the next apply
+		if extraction == nil {
+			continue
+		}
+		ids[name] = id
+
+		if e, ok := definitions[name]; ok && !e.matches(extractionDefinitionFromRemote(extraction)) {
+			drifted = append(drifted, name)
+		}
+	}
+
+	return diag.FromErr(setAttributes(d, map[string]interface{}{
+		"extraction_ids":      ids,
+		"drifted_extractions": drifted,
+	}))
+}
+
+func resourceUpdateExtractions(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(KeepClient)
+	filePath := d.Get("definitions_file").(string)
+
+	definitions, err := readExtractionDefinitions(filePath)
+	if err != nil {
+		return attributeErrorf(cty.GetAttrPath("definitions_file"), "%s", err)
+	}
+
+	hasher := &FileHasher{
+		FilePath:  filePath,
+		HashField: "definitions_content_hash",
+	}
+	if err := hasher.SetFileHash(d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	oldIDs, _ := d.GetChange("extraction_ids")
+	ids, diags := reconcileExtractions(ctx, client, definitions, oldIDs.(map[string]interface{}))
+	if err := d.Set("extraction_ids", ids); err != nil {
+		diags = append(diags, diag.Errorf("Failed to set extraction_ids: %s", err.Error())...)
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	return resourceReadExtractions(ctx, d, m)
+}
+
+func resourceDeleteExtractions(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(KeepClient)
+
+	_, diags := reconcileExtractions(ctx, client, nil, d.Get("extraction_ids").(map[string]interface{}))
+	if diags.HasError() {
+		return diags
+	}
+
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,231 @@
+package keep
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const testExtractionDefinitions = `extractions:
+  - name: tf-acc-error-pattern
+    description: Extract error patterns from logs
+    priority: 1
+    attribute: message
+    regex: "error: (?P<error>.*)"
+  - name: tf-acc-service-env
+    attribute: labels.service
+    regex: "(?P<service>[a-z]+)-(?P<env>[a-z]+)"
+    condition: source == "prometheus"
+    disabled: true
+`
+
+func writeExtractionDefinitions(t *testing.T, content string) string {
+	filePath := filepath.Join(t.TempDir(), "extractions.yml")
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filePath
+}
+
+func TestReadExtractionDefinitions(t *testing.T) {
+	definitions, err := readExtractionDefinitions(writeExtractionDefinitions(t, testExtractionDefinitions))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(definitions) != 2 {
+		t.Fatalf("expected 2 definitions, got %d", len(definitions))
+	}
+
+	if definitions[1].Name != "tf-acc-service-env" || !definitions[1].Disabled || definitions[1].Condition != `source == "prometheus"` {
+		t.Errorf("unexpected definition: %+v", definitions[1])
+	}
+}
+
+func TestReadExtractionDefinitions_invalid(t *testing.T) {
+	cases := map[string]string{
+		"missing regex": `extractions:
+  - name: tf-acc-error-pattern
+    attribute: message
+`,
+		"duplicate name": `extractions:
+  - name: tf-acc-error-pattern
+    attribute: message
+    regex: "(?P<error>.*)"
+  - name: tf-acc-error-pattern
+    attribute: message
+    regex: "(?P<error>.*)"
+`,
+		"no named group": `extractions:
+  - name: tf-acc-error-pattern
+    attribute: message
+    regex: "error: (.*)"
+`,
+		"unknown field": `extractions:
+  - name: tf-acc-error-pattern
+    attribute: message
+    regex: "(?P<error>.*)"
+    prio: 1
+`,
+	}
+
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := readExtractionDefinitions(writeExtractionDefinitions(t, content)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestAccKeepExtractions_basic(t *testing.T) {
+	filePath := writeExtractionDefinitions(t, testExtractionDefinitions)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: testAccProviderFactories,
+		CheckDestroy:      testAccCheckKeepExtractionDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccKeepExtractionsConfig(filePath),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("keep_extractions.test", "extraction_ids.%", "2"),
+					resource.TestCheckResourceAttrSet("keep_extractions.test", "extraction_ids.tf-acc-error-pattern"),
+					resource.TestCheckResourceAttrSet("keep_extractions.test", "extraction_ids.tf-acc-service-env"),
+				),
+			},
+			{
+				PreConfig: func() {
+					content := strings.SplitN(testExtractionDefinitions, "  - name: tf-acc-service-env", 2)[0]
+					if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: testAccKeepExtractionsConfig(filePath),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("keep_extractions.test", "extraction_ids.%", "1"),
+					resource.TestCheckResourceAttrSet("keep_extractions.test", "extraction_ids.tf-acc-error-pattern"),
+				),
+			},
+		},
+	})
+}
+
+func testAccKeepExtractionsConfig(filePath string) string {
+	return testAccProviderConfig(os.Getenv("KEEP_BACKEND_URL"), os.Getenv("KEEP_API_KEY")) + fmt.Sprintf(`
+resource "keep_extractions" "test" {
+  definitions_file = "%s"
+}`, filePath)
+}
+
+func TestResourceExtractions_MockBackend(t *testing.T) {
+	backend := newMockBackend(t)
+	client := backend.client()
+	r := resourceExtractions()
+
+	filePath := writeExtractionDefinitions(t, testExtractionDefinitions)
+	config := map[string]interface{}{"definitions_file": filePath}
+
+	state, diags := applyMockResource(t, r, nil, config, client)
+	if diags.HasError() {
+		t.Fatalf("unexpected error on create: %v", diags)
+	}
+	if len(backend.extractions) != 2 || state.Attributes["extraction_ids.%"] != "2" {
+		t.Fatalf("expected two extractions, got %+v and %v", backend.extractions, state.Attributes)
+	}
+	serviceEnvID := state.Attributes["extraction_ids.tf-acc-service-env"]
+
+	// removed definitions are deleted, changed ones updated in place
+	if err := os.WriteFile(filePath, []byte(`extractions:
+  - name: tf-acc-service-env
+    attribute: labels.service
+    regex: "(?P<service>[a-z]+)"
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if state, diags = applyMockResource(t, r, state, config, client); diags.HasError() {
+		t.Fatalf("unexpected error on update: %v", diags)
+	}
+	if len(backend.extractions) != 1 || backend.extractions[serviceEnvID].Regex != "(?P<service>[a-z]+)" {
+		t.Errorf("expected only the updated extraction to be left, got %+v", backend.extractions)
+	}
+
+	// extractions changed outside of terraform are updated on the next apply
+	changed := backend.extractions[serviceEnvID]
+	changed.Regex = "(?P<service>.*)"
+	backend.extractions[serviceEnvID] = changed
+	if state, diags = refreshMockResource(t, r, state, client); diags.HasError() {
+		t.Fatalf("unexpected error on refresh: %v", diags)
+	}
+	if state, diags = applyMockResource(t, r, state, config, client); diags.HasError() {
+		t.Fatalf("unexpected error on update: %v", diags)
+	}
+	if backend.extractions[serviceEnvID].Regex != "(?P<service>[a-z]+)" {
+		t.Errorf("expected the changed extraction to be restored, got %+v", backend.extractions[serviceEnvID])
+	}
+
+	// extractions deleted outside of terraform are dropped from state and recreated on the next apply
+	delete(backend.extractions, serviceEnvID)
+	if state, diags = refreshMockResource(t, r, state, client); diags.HasError() || state.Attributes["extraction_ids.%"] != "0" {
+		t.Fatalf("expected the deleted extraction to be dropped, got %v, %v", state, diags)
+	}
+
+	if state, diags = applyMockResource(t, r, state, nil, client); diags.HasError() || state != nil {
+		t.Fatalf("unexpected destroy result: %v, %v", state, diags)
+	}
+	if len(backend.extractions) != 0 {
+		t.Errorf("expected all extractions to be deleted, got %+v", backend.extractions)
+	}
+}
+
+func TestReconcileExtractions_partialFailure(t *testing.T) {
+	backend := newMockBackend(t, "PUT /extraction/{rule_id}")
+	client := backend.client()
+	backend.extractions["1"] = Extraction{ID: "1", Name: "tf-acc-error-pattern", Attribute: "message", Regex: "(?P<error>.*)"}
+	backend.extractions["2"] = Extraction{ID: "2", Name: "removed", Attribute: "message", Regex: "(?P<error>.*)"}
+
+	definitions := []extractionDefinition{{Name: "tf-acc-error-pattern", Attribute: "message", Regex: "error: (?P<error>.*)"}}
+	ids, diags := reconcileExtractions(context.Background(), client, definitions, map[string]interface{}{"tf-acc-error-pattern": "1", "removed": "2"})
+	if !diags.HasError() {
+		t.Fatal("expected the failed update to be reported")
+	}
+
+	// the extraction which wasn't deleted yet is still managed
+	if len(ids) != 2 || ids["tf-acc-error-pattern"] != "1" || ids["removed"] != "2" {
+		t.Errorf("expected both extractions to be kept, got %v", ids)
+	}
+}
+
+func TestExtractionDefinitionMatches(t *testing.T) {
+	definition := extractionDefinition{Name: "tf-acc-service-env", Attribute: "labels.service", Regex: "(?P<service>[a-z]+)", Condition: `source == "prometheus"`}
+
+	remote := definition
+	remote.Condition = `(source=="prometheus")`
+	if !definition.matches(remote) {
+		t.Error("expected conditions which only differ in formatting to match")
+	}
+
+	remote.Priority = 2
+	if definition.matches(remote) {
+		t.Error("expected a changed priority to be detected")
+	}
+}
+
+func TestReconcileExtractions_deleteNotSupported(t *testing.T) {
+	backend := newMockBackend(t, "DELETE /extraction/{rule_id}")
+	client := backend.client()
+	backend.extractions["1"] = Extraction{ID: "1", Name: "removed", Attribute: "message", Regex: "(?P<error>.*)"}
+
+	ids, diags := reconcileExtractions(context.Background(), client, nil, map[string]interface{}{"removed": "1"})
+	if !diags.HasError() {
+		t.Fatal("expected the unsupported delete to be reported")
+	}
+	if ids["removed"] != "1" {
+		t.Errorf("expected the extraction to be kept, got %v", ids)
+	}
+}
@@ -1,9 +1,8 @@
 package keep
 
 import (
+	"bytes"
 	"context"
-	"encoding/csv"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
@@ -11,10 +10,12 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/spf13/cast"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 // validateMatchersAgainstCSV validates that all matcher columns exist in the CSV data
@@ -62,43 +63,54 @@ func getKeysFromMap(m map[string]bool) []string {
 	return keys
 }
 
+// matcherDiagnostics validates the matchers against the CSV rows, errors point at the invalid matcher
+func matcherDiagnostics(matchers []string, rows []map[string]string) diag.Diagnostics {
+	if len(rows) == 0 {
+		return attributeErrorf(cty.GetAttrPath("mapping_file_path"), "Invalid matchers: CSV file is empty")
+	}
+
+	var diags diag.Diagnostics
+	for _, matcher := range matchers {
+		if err := validateMatchersAgainstCSV([]string{matcher}, rows); err != nil {
+			diags = append(diags, attributeErrorf(cty.GetAttrPath("matchers").Index(cty.StringVal(matcher)), "Invalid matchers: %s", err)...)
+		}
+	}
+	return diags
+}
+
 // formatMatchers converts matcher strings to arrays as required by the API
-func formatMatchers(matcherStrings []string) [][]string {
-	formatted := make([][]string, len(matcherStrings))
+func formatMatchers(matcherStrings []string) []MappingMatcher {
+	formatted := make([]MappingMatcher, len(matcherStrings))
 	for i, matcher := range matcherStrings {
-		parts := strings.Split(matcher, " && ")
-		formatted[i] = parts
+		formatted[i] = strings.Split(matcher, " && ")
 	}
 	return formatted
 }
 
 // formatMatchersStringForState converts matcher arrays back to strings for state
-func formatMatchersStringForState(matcherArrays interface{}) []string {
-	switch v := matcherArrays.(type) {
-	case []string:
-		return v
-	case []interface{}:
-		formatted := make([]string, len(v))
-		for i, matcher := range v {
-			switch m := matcher.(type) {
-			case []interface{}:
-				parts := make([]string, len(m))
-				for j, part := range m {
-					if str, ok := part.(string); ok {
-						parts[j] = str
-					}
-				}
-				formatted[i] = strings.Join(parts, " && ")
-			case string:
-				formatted[i] = m
-			default:
-				formatted[i] = ""
-			}
-		}
-		return formatted
-	default:
-		return []string{}
+func formatMatchersStringForState(matchers []MappingMatcher) []string {
+	formatted := make([]string, len(matchers))
+	for i, matcher := range matchers {
+		formatted[i] = matcher.String()
 	}
+	return formatted
+}
+
+// mappingPayload builds the API payload of a mapping from the resource data and the rows of the CSV file
+func mappingPayload(d *schema.ResourceData, fileName string, rows []map[string]string, matcherStrings []string) Mapping {
+	mapping := Mapping{
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+		Matchers:    formatMatchers(matcherStrings),
+		Priority:    d.Get("priority").(int),
+		Rows:        rows,
+		FileName:    fileName,
+	}
+	if override, ok := d.GetOkExists("override"); ok {
+		value := override.(bool)
+		mapping.Override = &value
+	}
+	return mapping
 }
 
 func resourceMapping() *schema.Resource {
@@ -113,21 +125,54 @@ func resourceMapping() *schema.Resource {
 		UpdateContext: resourceUpdateMapping,
 		DeleteContext: resourceDeleteMapping,
 		Importer: &schema.ResourceImporter{
-			StateContext: func(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
-				return []*schema.ResourceData{d}, nil
+			StateContext: resourceImportMapping,
+		},
+		Identity: idIdentity(),
+		// updates replace the mapping, which gets a new id
+		ResourceBehavior: schema.ResourceBehavior{MutableIdentity: true},
+		// large mapping files take longer to upload than the request timeout of the provider
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		SchemaVersion: 2,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Type:    resourceMappingV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceMappingStateUpgradeV0,
+				Version: 0,
+			},
+			{
+				Type:    resourceMappingV1().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceMappingStateUpgradeV1,
+				Version: 1,
 			},
 		},
 		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
-			mappingFilePath := filepath.Clean(d.Get("mapping_file_path").(string))
-			hasher.FilePath = mappingFilePath
-			return hasher.CustomizeDiff(ctx, d)
+			hasher.FilePath, hasher.Content = "", nil
+			if content, ok := d.GetOk("csv_content"); ok {
+				hasher.Content = []byte(content.(string))
+			} else if path := d.Get("mapping_file_path").(string); path != "" {
+				hasher.FilePath = filepath.Clean(path)
+			}
+			if err := hasher.CustomizeDiff(ctx, d); err != nil {
+				return err
+			}
+
+			content := hasher.Content
+			if content == nil && hasher.FilePath != "" {
+				content, _ = os.ReadFile(hasher.FilePath)
+			}
+			return customizeDiffMappingEnrichedAttributes(d, content)
 		},
 
 		Schema: map[string]*schema.Schema{
 			"name": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Name of the mapping",
+				Type:         schema.TypeString,
+				ValidateFunc: validation.All(validation.StringIsNotWhiteSpace, validation.StringLenBetween(1, 255)),
+				Required:     true,
+				Description:  "Name of the mapping",
 			},
 
 			"description": {
@@ -138,20 +183,28 @@ func resourceMapping() *schema.Resource {
 			"matchers": {
 				Type:        schema.TypeSet,
 				Required:    true,
-				Elem:        &schema.Schema{Type: schema.TypeString},
+				Elem:        &schema.Schema{Type: schema.TypeString, ValidateDiagFunc: validateMatcher},
 				Set:         schema.HashString,
-				Description: "List of matchers",
+				Description: "List of matchers, each one or more CSV columns joined by ` && `, e.g. `service && environment`",
 			},
 			"priority": {
-				Type:        schema.TypeInt,
+				Type:         schema.TypeInt,
+				ValidateFunc: validation.IntAtLeast(0),
+				Optional:     true,
+				Description:  "Priority of the mapping",
+				Default:      0,
+			},
+			"override": {
+				Type:        schema.TypeBool,
 				Optional:    true,
-				Description: "Priority of the mapping",
-				Default:     0,
+				Computed:    true,
+				Description: "Whether the enrichment overrides existing alert fields. Uses the backend default if not set",
 			},
 			"mapping_file_path": {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Path of the mapping file",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"csv_content", "mapping_file_path"},
+				Description:  "Path of the mapping file",
 				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 					// Get the base filename from both paths
 					oldBase := filepath.Base(old)
@@ -159,19 +212,120 @@ func resourceMapping() *schema.Resource {
 					return oldBase == newBase
 				},
 			},
+			"csv_content": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"csv_content", "mapping_file_path"},
+				Description: "CSV content of the mapping instead of a file. Imports set it to the rows of the backend if the " +
+					"mapping file is not in the working directory, so terraform plan -generate-config-out generates a configuration which keeps the rows",
+			},
 			"csv_content_hash": {
 				Type:        schema.TypeString,
 				Computed:    true,
 				ForceNew:    true,
 				Description: "Hash of the CSV file content for change detection",
 			},
+			"enriched_attributes": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "Attributes the mapping adds to alerts, the columns which are not part of any matcher. Pass them to enrichment_attributes of keep_workflow to validate the references of workflows",
+			},
+			"deletion_protection": deletionProtectionSchema("mapping"),
+		},
+	}
+}
+
+// resourceMappingV0 is the schema of keep_mapping before the composite "id:hash" ID was dropped
+func resourceMappingV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"matchers": {
+				Type:     schema.TypeSet,
+				Required: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+			},
+			"priority": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Default:  0,
+			},
+			"mapping_file_path": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"csv_content_hash": {
+				Type:     schema.TypeString,
+				Computed: true,
+				ForceNew: true,
+			},
 		},
 	}
 }
 
+// resourceMappingV1 is the schema of keep_mapping before reads stopped setting mapping_file_path to an absolute path
+func resourceMappingV1() *schema.Resource {
+	v1 := resourceMappingV0()
+	v1.Schema["override"] = &schema.Schema{
+		Type:     schema.TypeBool,
+		Optional: true,
+		Computed: true,
+	}
+	return v1
+}
+
+// resourceMappingStateUpgradeV0 strips the content hash from composite "id:hash" IDs,
+// the hash itself is already kept in csv_content_hash
+func resourceMappingStateUpgradeV0(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	if rawState == nil {
+		return rawState, nil
+	}
+
+	if id, ok := rawState["id"].(string); ok {
+		if idx := strings.Index(id, ":"); idx >= 0 {
+			rawState["id"] = id[:idx]
+		}
+	}
+
+	return rawState, nil
+}
+
+// resourceMappingStateUpgradeV1 rewrites the absolute mapping_file_path, which reads set to the file name in
+// the working directory, relative to the working directory, so the state doesn't depend on the checkout location
+func resourceMappingStateUpgradeV1(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	if rawState == nil {
+		return rawState, nil
+	}
+
+	path, ok := rawState["mapping_file_path"].(string)
+	if !ok || !filepath.IsAbs(path) {
+		return rawState, nil
+	}
+
+	currentDir, err := os.Getwd()
+	if err != nil {
+		return rawState, nil
+	}
+	if relative, err := filepath.Rel(currentDir, path); err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		rawState["mapping_file_path"] = relative
+	}
+
+	return rawState, nil
+}
+
 // Add function to check for duplicate names
-func checkDuplicateName(client *Client, name string, currentID string) error {
-	mappings, errResp, err := client.GetMappings()
+func checkDuplicateName(ctx context.Context, client KeepClient, name string, currentID string) error {
+	mappings, errResp, err := client.GetMappingsByName(ctx, name)
 	if err != nil {
 		if errResp != nil {
 			return fmt.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
@@ -179,10 +333,9 @@ func checkDuplicateName(client *Client, name string, currentID string) error {
 		return fmt.Errorf("error getting mappings: %s", err)
 	}
 
-	for _, m := range mappings {
-		mapping := m.(map[string]interface{})
-		if mapping["name"] == name {
-			if id := cast.ToString(mapping["id"]); id != currentID {
+	for _, mapping := range mappings {
+		if mapping.Name == name {
+			if id := string(mapping.ID); id != currentID {
 				return fmt.Errorf("mapping with name '%s' already exists", name)
 			}
 		}
@@ -191,87 +344,207 @@ func checkDuplicateName(client *Client, name string, currentID string) error {
 	return nil
 }
 
-// Add helper function to clean up duplicate mappings
-func cleanupDuplicateMappings(client *Client, currentID, name string) error {
-	mappings, errResp, err := client.GetMappings()
+// findMappingIDsByName returns the ids of all mappings with the given name
+func findMappingIDsByName(ctx context.Context, client KeepClient, name string) ([]string, error) {
+	mappings, errResp, err := client.GetMappingsByName(ctx, name)
 	if err != nil {
 		if errResp != nil {
-			return fmt.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
+			return nil, fmt.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
 		}
-		return fmt.Errorf("error getting mappings: %s", err)
+		return nil, fmt.Errorf("error getting mappings: %s", err)
 	}
 
-	for _, m := range mappings {
-		mapping := m.(map[string]interface{})
-		if mapping["name"] == name {
-			if id := cast.ToString(mapping["id"]); id != currentID {
-				errResp, err := client.DeleteMapping(id)
-				if err != nil {
-					if errResp != nil {
-						return fmt.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
-					}
-					return fmt.Errorf("error deleting mapping %s: %s", id, err)
-				}
-			}
+	ids := make([]string, 0)
+	for _, mapping := range mappings {
+		if mapping.Name == name {
+			ids = append(ids, string(mapping.ID))
 		}
 	}
 
-	return nil
+	return ids, nil
 }
 
-func resourceCreateMapping(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Client)
-	name := d.Get("name").(string)
+// resourceImportMapping supports importing by ID or by name using the "name=<mapping-name>" syntax
+func resourceImportMapping(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	importID, err := importIDFromIdentity(d)
+	if err != nil {
+		return nil, err
+	}
 
-	// Check for duplicate names before creating
-	if err := checkDuplicateName(client, name, ""); err != nil {
-		return diag.FromErr(err)
+	id, err := resolveImportID(importID, "mapping", func(name string) ([]string, error) {
+		return findMappingIDsByName(ctx, m.(KeepClient), name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	d.SetId(id)
+
+	// The mapping file is expected in the working directory under its uploaded name. Without the file, the rows
+	// of the backend become the CSV content. The hash is only taken on import, so reads don't hide changes of
+	// the file from the next plan.
+	mapping, errResp, err := getMapping(ctx, m.(KeepClient), id)
+	if err != nil {
+		if errResp != nil {
+			return nil, fmt.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
+		}
+		return nil, fmt.Errorf("error getting mappings: %s", err)
+	}
+	if mapping == nil {
+		return []*schema.ResourceData{d}, nil
+	}
+
+	values := map[string]interface{}{}
+	if hash, err := calculateFileHash(mapping.FileName); mapping.FileName != "" && err == nil {
+		values["mapping_file_path"] = mapping.FileName
+		values["csv_content_hash"] = hash
+	} else if len(mapping.Rows) > 0 {
+		content, err := formatCSVRows(mapping.Rows)
+		if err != nil {
+			return nil, fmt.Errorf("error formatting rows of mapping: %s", err)
+		}
+		values["csv_content"] = content
+		values["csv_content_hash"] = calculateContentHash([]byte(content))
+	} else if mapping.FileName != "" {
+		values["mapping_file_path"] = mapping.FileName
+	}
+	if err := setAttributes(d, values); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// readMappingRows returns the file name and rows of csv_content or the mapping file
+func readMappingRows(d *schema.ResourceData) (string, []map[string]string, diag.Diagnostics) {
+	if content, ok := d.GetOk("csv_content"); ok {
+		rows, err := parseCSVRows(strings.NewReader(content.(string)), ',')
+		if err != nil {
+			return "", nil, attributeErrorf(cty.GetAttrPath("csv_content"), "Error reading CSV content: %s", err)
+		}
+		return d.Get("name").(string) + ".csv", rows, nil
 	}
 
 	mappingFilePath := d.Get("mapping_file_path").(string)
 	normalizedPath := filepath.Clean(mappingFilePath)
-	d.Set("mapping_file_path", normalizedPath)
-
-	// read file from mappingFilePath it should be a file path and csv file
 
 	fInfo, err := os.Stat(normalizedPath)
 	if err != nil {
-		return diag.Errorf("mapping file not found: %s", mappingFilePath)
+		return "", nil, attributeErrorf(cty.GetAttrPath("mapping_file_path"), "mapping file not found: %s", mappingFilePath)
 	} else if fInfo.IsDir() {
-		return diag.Errorf("mapping file is a directory: %s", mappingFilePath)
+		return "", nil, attributeErrorf(cty.GetAttrPath("mapping_file_path"), "mapping file is a directory: %s", mappingFilePath)
 	}
 
 	file, err := os.OpenFile(normalizedPath, os.O_RDONLY, 0644)
 	if err != nil {
-		return diag.Errorf("cannot open file: %s", mappingFilePath)
+		return "", nil, attributeErrorf(cty.GetAttrPath("mapping_file_path"), "cannot open file: %s", mappingFilePath)
 	}
 	defer file.Close()
 
-	hasher := &FileHasher{
-		FilePath:  normalizedPath,
-		HashField: "csv_content_hash",
+	rows, err := parseCSVRows(file, ',')
+	if err != nil {
+		return "", nil, attributeErrorf(cty.GetAttrPath("mapping_file_path"), "Error reading CSV file: %s", err)
 	}
-	if err := hasher.SetFileHash(d); err != nil {
-		return diag.FromErr(err)
+	return fInfo.Name(), rows, nil
+}
+
+// setMappingHash sets csv_content_hash to the hash of csv_content or the mapping file
+func setMappingHash(d *schema.ResourceData) error {
+	hasher := &FileHasher{HashField: "csv_content_hash"}
+	if content, ok := d.GetOk("csv_content"); ok {
+		hasher.Content = []byte(content.(string))
+	} else {
+		hasher.FilePath = filepath.Clean(d.Get("mapping_file_path").(string))
 	}
+	return hasher.SetFileHash(d)
+}
 
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
+// customizeDiffMappingEnrichedAttributes plans enriched_attributes from the columns of the CSV content on create and
+// on changes of the content or the matchers, otherwise reads keep the attributes returned by the backend
+func customizeDiffMappingEnrichedAttributes(d *schema.ResourceDiff, content []byte) error {
+	if d.Id() != "" && !d.HasChange("csv_content_hash") && !d.HasChange("matchers") {
+		return nil
+	}
+	if content == nil || !d.NewValueKnown("matchers") {
+		return d.SetNewComputed("enriched_attributes")
+	}
 
+	// invalid CSV content is reported by the apply
+	columns, err := parseCSVHeader(bytes.NewReader(content), ',')
 	if err != nil {
-		return diag.Errorf("Error reading CSV file: %s", err)
+		return d.SetNewComputed("enriched_attributes")
 	}
+	matchers := make([]string, 0)
+	for _, matcher := range d.Get("matchers").(*schema.Set).List() {
+		matchers = append(matchers, matcher.(string))
+	}
+	return d.SetNew("enriched_attributes", mappingEnrichedAttributes(columns, matchers))
+}
 
-	headers := records[0]
-	records = records[1:]
+// setMappingState sets the attributes of the mapping returned by the backend. mapping_file_path and
+// csv_content_hash are left unchanged, they describe the uploaded file, which the backend doesn't return.
+func setMappingState(d *schema.ResourceData, mapping *Mapping) diag.Diagnostics {
+	values := map[string]interface{}{
+		"name":        mapping.Name,
+		"description": mapping.Description,
+		"priority":    mapping.Priority,
+	}
+	if mapping.Override != nil {
+		values["override"] = *mapping.Override
+	}
+	// Convert matcher arrays back to strings for state
+	if mapping.Matchers != nil {
+		values["matchers"] = formatMatchersStringForState(mapping.Matchers)
+	}
+	if mapping.Attributes != nil {
+		values["enriched_attributes"] = mapping.Attributes
+	}
+	return diag.FromErr(setAttributes(d, values))
+}
 
-	rows := make([]map[string]string, len(records))
-	for i, record := range records {
-		row := make(map[string]string)
-		for j, cell := range record {
-			row[headers[j]] = cell
+// Add helper function to clean up duplicate mappings
+func cleanupDuplicateMappings(ctx context.Context, client KeepClient, currentID, name string) error {
+	mappings, errResp, err := client.GetMappingsByName(ctx, name)
+	if err != nil {
+		if errResp != nil {
+			return fmt.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
 		}
-		rows[i] = row
+		return fmt.Errorf("error getting mappings: %s", err)
+	}
+
+	for _, mapping := range mappings {
+		if mapping.Name == name {
+			if id := string(mapping.ID); id != currentID {
+				errResp, err := client.DeleteMapping(ctx, id)
+				if err != nil {
+					if errResp != nil {
+						return fmt.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
+					}
+					return fmt.Errorf("error deleting mapping %s: %s", id, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func resourceCreateMapping(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(KeepClient)
+	name := d.Get("name").(string)
+
+	// Check for duplicate names before creating
+	if err := checkDuplicateName(ctx, client, name, ""); err != nil {
+		return attributeErrorf(cty.GetAttrPath("name"), "%s", err)
+	}
+
+	if err := setMappingHash(d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	fileName, rows, diags := readMappingRows(d)
+	if diags.HasError() {
+		return diags
 	}
 
 	matchersSet := d.Get("matchers").(*schema.Set)
@@ -281,23 +554,11 @@ func resourceCreateMapping(ctx context.Context, d *schema.ResourceData, m interf
 	}
 
 	// Validate matchers against CSV content
-	if err := validateMatchersAgainstCSV(matcherStrings, rows); err != nil {
-		return diag.Errorf("Invalid matchers: %s", err)
-	}
-
-	// Format matchers as arrays for the API
-	formattedMatchers := formatMatchers(matcherStrings)
-
-	body := map[string]interface{}{
-		"name":        d.Get("name").(string),
-		"description": d.Get("description").(string),
-		"matchers":    formattedMatchers,
-		"priority":    d.Get("priority").(int),
-		"rows":        rows,
-		"file_name":   fInfo.Name(),
+	if diags := matcherDiagnostics(matcherStrings, rows); diags.HasError() {
+		return diags
 	}
 
-	response, errResp, err := client.CreateMapping(body)
+	response, errResp, err := client.CreateMapping(ctx, mappingPayload(d, fileName, rows, matcherStrings))
 	if err != nil {
 		if errResp != nil {
 			return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
@@ -305,24 +566,19 @@ func resourceCreateMapping(ctx context.Context, d *schema.ResourceData, m interf
 		return diag.Errorf("error creating mapping: %s", err)
 	}
 
-	// Get the hash value and set composite ID
-	contentHash := d.Get("csv_content_hash").(string)
-	compositeID := fmt.Sprintf("%v:%s", response["id"], contentHash)
-	d.SetId(compositeID)
-
-	d.Set("name", response["name"])
-	d.Set("description", response["description"])
-	d.Set("priority", response["priority"])
-
-	// Convert matcher arrays back to strings for state if needed
-	if matcherArrays, ok := response["matchers"].([]interface{}); ok {
-		d.Set("matchers", formatMatchersStringForState(matcherArrays))
-	} else {
-		d.Set("matchers", matcherStrings)
+	d.SetId(string(response.ID))
+	if err := waitForMapping(ctx, client, d.Id()); err != nil {
+		return diag.FromErr(err)
+	}
+	if diags := setIdentity(d, nil); diags.HasError() {
+		return diags
+	}
+	if diags := setMappingState(d, response); diags.HasError() {
+		return diags
 	}
 
 	// After successful creation, clean up any duplicates
-	if err := cleanupDuplicateMappings(client, fmt.Sprintf("%v", response["id"]), response["name"].(string)); err != nil {
+	if err := cleanupDuplicateMappings(ctx, client, string(response.ID), response.Name); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -330,23 +586,53 @@ func resourceCreateMapping(ctx context.Context, d *schema.ResourceData, m interf
 
 }
 
-func resourceReadMapping(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Client)
-	id := d.Id()
+// waitForMapping waits until the backend returns the created mapping, see waitUntilVisible
+func waitForMapping(ctx context.Context, client KeepClient, id string) error {
+	return waitUntilVisible(ctx, client, "mapping", id, func() (bool, error) {
+		mapping, _, err := getMapping(ctx, client, id)
+		return mapping != nil, err
+	})
+}
 
-	// Handle both composite and simple IDs
-	var mappingID string
-	if strings.Contains(id, ":") {
-		parts := strings.Split(id, ":")
-		if len(parts) != 2 {
-			return diag.Errorf("invalid resource ID format")
+// getMapping fetches a single mapping by id. Backends without the single-mapping endpoint answer
+// with 405, in which case the full list is scanned instead, also for later reads.
+// A nil mapping without error means the mapping does not exist.
+func getMapping(ctx context.Context, client KeepClient, id string) (*Mapping, *ErrorResponse, error) {
+	if client.supportsEndpoint(ctx, "GET", "/mapping/{rule_id}") {
+		mapping, errResp, err := client.GetMapping(ctx, id)
+		if err == nil {
+			return mapping, nil, nil
 		}
-		mappingID = parts[0]
-	} else {
-		mappingID = id
+
+		if isNotFound(err) {
+			return nil, nil, nil
+		}
+
+		if !hasStatus(err, http.StatusMethodNotAllowed) {
+			return nil, errResp, err
+		}
+		client.rejectEndpoint("GET", "/mapping/{rule_id}")
+	}
+
+	mappings, errResp, err := client.GetMappings(ctx)
+	if err != nil {
+		return nil, errResp, err
 	}
 
-	mappings, errResp, err := client.GetMappings()
+	for i := range mappings {
+		if string(mappings[i].ID) == id {
+			return &mappings[i], nil, nil
+		}
+	}
+
+	return nil, nil, nil
+}
+
+func resourceReadMapping(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(KeepClient)
+	mappingID := d.Id()
+
+	mapping, errResp, err := getMapping(ctx, client, mappingID)
 	if err != nil {
 		if errResp != nil {
 			return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
@@ -354,94 +640,38 @@ func resourceReadMapping(ctx context.Context, d *schema.ResourceData, m interfac
 		return diag.Errorf("error getting mappings: %s", err)
 	}
 
-	idInt := cast.ToInt(mappingID)
-	for _, m := range mappings {
-		mapping := m.(map[string]interface{})
-		if cast.ToInt(mapping["id"]) == idInt {
-			currentDir, _ := os.Getwd()
-			filePath := filepath.Join(currentDir, mapping["file_name"].(string))
-
-			// Only set csv_content_hash if we have access to the file
-			if path := d.Get("mapping_file_path").(string); path != "" {
-				if hash, err := calculateFileHash(path); err == nil {
-					d.Set("csv_content_hash", hash)
-				}
-			}
-
-			d.Set("name", mapping["name"])
-			d.Set("description", mapping["description"])
-			d.Set("priority", mapping["priority"])
-			d.Set("mapping_file_path", filePath)
-
-			// Handle matchers conversion
-			var matcherSet *schema.Set
-			if matchers, ok := mapping["matchers"].([]interface{}); ok {
-				matcherStrings := make([]interface{}, len(matchers))
-				for i, matcher := range matchers {
-					switch m := matcher.(type) {
-					case []interface{}:
-						parts := make([]string, len(m))
-						for j, part := range m {
-							if str, ok := part.(string); ok {
-								parts[j] = str
-							}
-						}
-						matcherStrings[i] = strings.Join(parts, " && ")
-					case string:
-						matcherStrings[i] = m
-					}
-				}
-				matcherSet = schema.NewSet(schema.HashString, matcherStrings)
-				d.Set("matchers", matcherSet)
-			}
+	if mapping == nil {
+		d.SetId("")
+		return nil
+	}
 
-			return nil
-		}
+	if diags := setMappingState(d, mapping); diags.HasError() {
+		return diags
 	}
 
-	// If we reach here, the resource was not found
-	d.SetId("")
-	return nil
+	return setIdentity(d, nil)
 }
 
 func resourceUpdateMapping(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Client)
+	client := m.(KeepClient)
 	id := d.Id()
 
 	// Only check for duplicates if name is being changed
 	if d.HasChange("name") {
 		name := d.Get("name").(string)
-		if err := checkDuplicateName(client, name, id); err != nil {
-			return diag.FromErr(err)
+		if err := checkDuplicateName(ctx, client, name, id); err != nil {
+			return attributeErrorf(cty.GetAttrPath("name"), "%s", err)
 		}
 	}
 
-	// Extract mapping ID from composite ID if present
-	var mappingID string
-	if strings.Contains(id, ":") {
-		parts := strings.Split(id, ":")
-		if len(parts) != 2 {
-			return diag.Errorf("invalid resource ID format")
-		}
-		mappingID = parts[0]
-	} else {
-		mappingID = id
-	}
-
 	// If this is a ForceNew update (CSV content changed), ensure old mapping is deleted
 	if d.HasChange("csv_content_hash") {
-		ruleID, err := strconv.Atoi(mappingID)
-		if err != nil {
+		if _, err := strconv.Atoi(id); err != nil {
 			return diag.Errorf("invalid rule ID format: %s", err)
 		}
 
 		// Delete the old mapping
-		deleteReq, err := http.NewRequest("DELETE", fmt.Sprintf("%s/mapping/%d", client.HostURL, ruleID), nil)
-		if err != nil {
-			return diag.Errorf("cannot create delete request: %s", err)
-		}
-
-		_, errResp, err := client.doReq(deleteReq)
+		errResp, err := client.DeleteMapping(ctx, id)
 		if err != nil {
 			if errResp != nil {
 				return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
@@ -450,39 +680,9 @@ func resourceUpdateMapping(ctx context.Context, d *schema.ResourceData, m interf
 		}
 	}
 
-	mappingFilePath := d.Get("mapping_file_path").(string)
-	normalizedPath := filepath.Clean(mappingFilePath)
-
-	// Rest of the update logic
-	fInfo, err := os.Stat(normalizedPath)
-	if err != nil {
-		return diag.Errorf("mapping file not found: %s", mappingFilePath)
-	} else if fInfo.IsDir() {
-		return diag.Errorf("mapping file is a directory: %s", mappingFilePath)
-	}
-
-	file, err := os.OpenFile(normalizedPath, os.O_RDONLY, 0644)
-	if err != nil {
-		return diag.Errorf("cannot open file: %s", mappingFilePath)
-	}
-	defer file.Close()
-
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
-	if err != nil {
-		return diag.Errorf("Error reading CSV file: %s", err)
-	}
-
-	headers := records[0]
-	records = records[1:]
-
-	rows := make([]map[string]string, len(records))
-	for i, record := range records {
-		row := make(map[string]string)
-		for j, cell := range record {
-			row[headers[j]] = cell
-		}
-		rows[i] = row
+	fileName, rows, diags := readMappingRows(d)
+	if diags.HasError() {
+		return diags
 	}
 
 	matchersSet := d.Get("matchers").(*schema.Set)
@@ -492,33 +692,11 @@ func resourceUpdateMapping(ctx context.Context, d *schema.ResourceData, m interf
 	}
 
 	// Validate matchers against CSV content
-	if err := validateMatchersAgainstCSV(matcherStrings, rows); err != nil {
-		return diag.Errorf("Invalid matchers: %s", err)
-	}
-
-	// Format matchers as arrays for the API
-	formattedMatchers := formatMatchers(matcherStrings)
-
-	reqBody := map[string]interface{}{
-		"name":        d.Get("name").(string),
-		"description": d.Get("description").(string),
-		"matchers":    formattedMatchers,
-		"priority":    d.Get("priority").(int),
-		"rows":        rows,
-		"file_name":   fInfo.Name(),
-	}
-
-	bodyBytes, err := json.Marshal(reqBody)
-	if err != nil {
-		return diag.Errorf("cannot marshal request body: %s", err)
-	}
-
-	updateReq, err := http.NewRequest("POST", client.HostURL+"/mapping", strings.NewReader(string(bodyBytes)))
-	if err != nil {
-		return diag.Errorf("cannot create request: %s", err)
+	if diags := matcherDiagnostics(matcherStrings, rows); diags.HasError() {
+		return diags
 	}
 
-	respBody, errResp, err := client.doReq(updateReq)
+	mapping, errResp, err := client.CreateMapping(ctx, mappingPayload(d, fileName, rows, matcherStrings))
 	if err != nil {
 		if errResp != nil {
 			return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
@@ -526,41 +704,20 @@ func resourceUpdateMapping(ctx context.Context, d *schema.ResourceData, m interf
 		return diag.Errorf("cannot send request: %s", err)
 	}
 
-	var mappingResponse struct {
-		ID          int      `json:"id"`
-		Name        string   `json:"name"`
-		Description string   `json:"description"`
-		Priority    int      `json:"priority"`
-		Matchers    []string `json:"matchers"`
-	}
-
-	err = json.Unmarshal(respBody, &mappingResponse)
-	if err != nil {
-		return diag.Errorf("cannot unmarshal response: %s", err)
+	if err := setMappingHash(d); err != nil {
+		return diag.FromErr(err)
 	}
 
-	hasher := &FileHasher{
-		FilePath:  normalizedPath,
-		HashField: "csv_content_hash",
+	d.SetId(string(mapping.ID))
+	if diags := setIdentity(d, nil); diags.HasError() {
+		return diags
 	}
-	if err := hasher.SetFileHash(d); err != nil {
-		return diag.FromErr(err)
+	if diags := setMappingState(d, mapping); diags.HasError() {
+		return diags
 	}
 
-	// Get the hash value after setting it
-	contentHash := d.Get("csv_content_hash").(string)
-	compositeID := fmt.Sprintf("%d:%s", mappingResponse.ID, contentHash)
-	d.SetId(compositeID)
-	d.Set("csv_content_hash", contentHash)
-	d.Set("name", mappingResponse.Name)
-	d.Set("description", mappingResponse.Description)
-	d.Set("priority", mappingResponse.Priority)
-
-	// Convert matcher arrays back to strings for state
-	d.Set("matchers", formatMatchersStringForState(mappingResponse.Matchers))
-
 	// After successful update, clean up any duplicates
-	if err := cleanupDuplicateMappings(client, cast.ToString(mappingResponse.ID), mappingResponse.Name); err != nil {
+	if err := cleanupDuplicateMappings(ctx, client, string(mapping.ID), mapping.Name); err != nil {
 		return diag.FromErr(err)
 	}
 
@@ -569,24 +726,15 @@ func resourceUpdateMapping(ctx context.Context, d *schema.ResourceData, m interf
 }
 
 func resourceDeleteMapping(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Client)
-	id := d.Id()
-
-	// Extract mapping ID from composite ID if present
-	var mappingID string
-	if strings.Contains(id, ":") {
-		parts := strings.Split(id, ":")
-		if len(parts) != 2 {
-			return diag.Errorf("invalid resource ID format")
-		}
-		mappingID = parts[0]
-	} else {
-		mappingID = id
+	if diags := checkDeletionProtection(d, "mapping"); diags.HasError() {
+		return diags
 	}
 
-	// Convert ID to integer to ensure valid format
-	errResp, err := client.DeleteMapping(mappingID)
-	if err != nil {
+	client := m.(KeepClient)
+
+	errResp, err := client.DeleteMapping(ctx, d.Id())
+	// mappings deleted outside of terraform are already gone
+	if err != nil && !isNotFound(err) {
 		if errResp != nil {
 			return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
 		}
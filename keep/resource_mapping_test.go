@@ -1,12 +1,14 @@
 package keep
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
@@ -41,7 +43,7 @@ api_latency,warning,backend,notify`
 func testAccMappingConfig(mappingPath string) string {
 	return fmt.Sprintf(`
 resource "keep_mapping" "test" {
-  name              = "alerts-mapping"
+  name              = "tf-acc-alerts-mapping"
   description       = "Mapping for alert rules"
   mapping_file_path = "%s"
   matchers = [
@@ -55,7 +57,7 @@ resource "keep_mapping" "test" {
 func cleanupExistingMappings() error {
 	client := initTestClient()
 
-	mappings, errResp, err := client.GetMappings()
+	mappings, errResp, err := client.GetMappings(context.Background())
 	if err != nil {
 		if errResp != nil {
 			return fmt.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
@@ -63,14 +65,13 @@ func cleanupExistingMappings() error {
 		return fmt.Errorf("error getting mappings: %s", err)
 	}
 
-	for _, m := range mappings {
-		mapping := m.(map[string]interface{})
-		errResp, err := client.DeleteMapping(fmt.Sprintf("%v", mapping["id"]))
+	for _, mapping := range mappings {
+		errResp, err := client.DeleteMapping(context.Background(), string(mapping.ID))
 		if err != nil {
 			if errResp != nil {
 				return fmt.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
 			}
-			return fmt.Errorf("error deleting mapping %v: %s", mapping["id"], err)
+			return fmt.Errorf("error deleting mapping %s: %s", mapping.ID, err)
 		}
 	}
 
@@ -99,7 +100,7 @@ func TestAccResourceMapping_basic(t *testing.T) {
 					testAccMappingConfig(mappingPath),
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckResourceExists(resourceName),
-					resource.TestCheckResourceAttr(resourceName, "name", "alerts-mapping"),
+					resource.TestCheckResourceAttr(resourceName, "name", "tf-acc-alerts-mapping"),
 					resource.TestCheckResourceAttr(resourceName, "description", "Mapping for alert rules"),
 					resource.TestCheckResourceAttr(resourceName, "priority", "1"),
 				),
@@ -108,7 +109,7 @@ func TestAccResourceMapping_basic(t *testing.T) {
 				ResourceName:            resourceName,
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"csv_content_hash"},
+				ImportStateVerifyIgnore: []string{"csv_content", "csv_content_hash", "mapping_file_path"},
 			},
 		},
 	})
@@ -146,7 +147,7 @@ prometheus,low,P5,low,#7e7e7e`
 			{
 				Config: testAccProviderConfig(os.Getenv("KEEP_BACKEND_URL"), os.Getenv("KEEP_API_KEY")) + fmt.Sprintf(`
 resource "keep_mapping" "prometheus_priority" {
-    name        = "prometheus-priority"
+    name        = "tf-acc-prometheus-priority"
     description = "Prometheus priority mapping"
     priority    = 1
     matchers    = ["source && labels.priority"]
@@ -155,7 +156,7 @@ resource "keep_mapping" "prometheus_priority" {
 `, tmpfilePath),
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckResourceExists("keep_mapping.prometheus_priority"),
-					resource.TestCheckResourceAttr("keep_mapping.prometheus_priority", "name", "prometheus-priority"),
+					resource.TestCheckResourceAttr("keep_mapping.prometheus_priority", "name", "tf-acc-prometheus-priority"),
 					resource.TestCheckResourceAttr("keep_mapping.prometheus_priority", "description", "Prometheus priority mapping"),
 				),
 			},
@@ -168,7 +169,7 @@ func testAccCheckMappingCount(expected int) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		client := testAccProvider.Meta().(*Client)
 
-		mappings, errResp, err := client.GetMappings()
+		mappings, errResp, err := client.GetMappings(context.Background())
 		if err != nil {
 			if errResp != nil {
 				return fmt.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
@@ -239,21 +240,8 @@ func testAccCheckResourceDisappears(resourceName string) resource.TestCheckFunc
 			return fmt.Errorf("resource ID is not set")
 		}
 
-		// Extract mapping ID from composite ID if present
-		id := rs.Primary.ID
-		var mappingID string
-		if strings.Contains(id, ":") {
-			parts := strings.Split(id, ":")
-			if len(parts) != 2 {
-				return fmt.Errorf("invalid resource ID format")
-			}
-			mappingID = parts[0]
-		} else {
-			mappingID = id
-		}
-
 		client := testAccProvider.Meta().(*Client)
-		errResp, err := client.DeleteMapping(mappingID)
+		errResp, err := client.DeleteMapping(context.Background(), rs.Primary.ID)
 		if err != nil {
 			if errResp != nil {
 				return fmt.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
@@ -297,7 +285,7 @@ network_issues,warning,network,notify`
 					testAccMappingConfig(mappingPath),
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckResourceExists(resourceName),
-					resource.TestCheckResourceAttr(resourceName, "name", "alerts-mapping"),
+					resource.TestCheckResourceAttr(resourceName, "name", "tf-acc-alerts-mapping"),
 				),
 			},
 			{
@@ -310,9 +298,352 @@ network_issues,warning,network,notify`
 					testAccMappingConfig(mappingPath),
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckResourceExists(resourceName),
-					resource.TestCheckResourceAttr(resourceName, "name", "alerts-mapping"),
+					resource.TestCheckResourceAttr(resourceName, "name", "tf-acc-alerts-mapping"),
 				),
 			},
 		},
 	})
 }
+
+func TestResourceMappingStateUpgradeV0(t *testing.T) {
+	cases := map[string]string{
+		"42:0a1b2c3d": "42",
+		"42":          "42",
+	}
+
+	for id, expected := range cases {
+		rawState := map[string]interface{}{
+			"id":               id,
+			"csv_content_hash": "0a1b2c3d",
+		}
+
+		actual, err := resourceMappingStateUpgradeV0(context.Background(), rawState, nil)
+		if err != nil {
+			t.Fatalf("error upgrading state: %s", err)
+		}
+
+		if actual["id"] != expected {
+			t.Errorf("expected id %q, got %q", expected, actual["id"])
+		}
+
+		if actual["csv_content_hash"] != "0a1b2c3d" {
+			t.Errorf("expected csv_content_hash to be kept, got %q", actual["csv_content_hash"])
+		}
+	}
+}
+
+func TestResourceMapping_MockBackend(t *testing.T) {
+	backend := newMockBackend(t)
+	client := backend.client()
+	r := resourceMapping()
+
+	// reads set the path of the mapping file relative to the working directory
+	t.Chdir(t.TempDir())
+	mappingPath := "alerts.csv"
+	if err := os.WriteFile(mappingPath, []byte("alert_name,severity,team\nhigh_error_rate,critical,platform\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := map[string]interface{}{
+		"name":              "alerts-mapping",
+		"description":       "Mapping for alert rules",
+		"mapping_file_path": mappingPath,
+		"matchers":          []interface{}{"alert_name && severity"},
+		"priority":          1,
+	}
+
+	state, diags := applyMockResource(t, r, nil, config, client)
+	if diags.HasError() {
+		t.Fatalf("unexpected error on create: %v", diags)
+	}
+	mapping, ok := backend.mappings[state.ID]
+	if !ok || mapping.Name != "alerts-mapping" || mapping.Matchers[0].String() != "alert_name && severity" || len(mapping.Rows) != 1 {
+		t.Fatalf("unexpected mapping in the backend: %+v", backend.mappings)
+	}
+
+	if state, diags = refreshMockResource(t, r, state, client); diags.HasError() || state == nil {
+		t.Fatalf("unexpected refresh result: %v, %v", state, diags)
+	}
+	if state.Attributes["description"] != "Mapping for alert rules" || state.Attributes["priority"] != "1" {
+		t.Errorf("unexpected state after refresh: %v", state.Attributes)
+	}
+
+	// updates create the new mapping before removing the previous one
+	config["description"] = "Updated"
+	previousID := state.ID
+	if state, diags = applyMockResource(t, r, state, config, client); diags.HasError() {
+		t.Fatalf("unexpected error on update: %v", diags)
+	}
+	if state.ID == previousID || len(backend.mappings) != 1 || backend.mappings[state.ID].Description != "Updated" {
+		t.Errorf("expected the mapping to be replaced, got %+v", backend.mappings)
+	}
+	if state.Identity["id"] != state.ID {
+		t.Errorf("expected the identity to follow the id of the new mapping, got %v", state.Identity)
+	}
+
+	// a second mapping with the same name is rejected
+	if _, diags := applyMockResource(t, r, nil, config, client); !diags.HasError() {
+		t.Error("expected an error for a duplicate name")
+	}
+
+	if state, diags = applyMockResource(t, r, state, nil, client); diags.HasError() || state != nil {
+		t.Fatalf("unexpected destroy result: %v, %v", state, diags)
+	}
+	if len(backend.mappings) != 0 {
+		t.Errorf("expected the mapping to be deleted, got %+v", backend.mappings)
+	}
+}
+
+func TestResourceMapping_MockBackendDisappears(t *testing.T) {
+	backend := newMockBackend(t, "GET /mapping/{rule_id}")
+	client := backend.client()
+	r := resourceMapping()
+
+	mappingPath := filepath.Join(t.TempDir(), "alerts.csv")
+	if err := os.WriteFile(mappingPath, []byte("alert_name,team\nhigh_error_rate,platform\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	state, diags := applyMockResource(t, r, nil, map[string]interface{}{
+		"name":              "alerts-mapping",
+		"mapping_file_path": mappingPath,
+		"matchers":          []interface{}{"alert_name"},
+	}, client)
+	if diags.HasError() {
+		t.Fatalf("unexpected error on create: %v", diags)
+	}
+
+	id := state.ID
+	delete(backend.mappings, id)
+	if state, diags = refreshMockResource(t, r, state, client); diags.HasError() || state != nil {
+		t.Errorf("expected the mapping to be removed from state, got %v, %v", state, diags)
+	}
+	if backend.requestCount("GET", "/mapping/"+id) != 0 {
+		t.Error("expected the mapping to be read from the list, since the backend does not list the endpoint")
+	}
+}
+
+func TestResourceMapping_MockBackendRefreshOnly(t *testing.T) {
+	backend := newMockBackend(t)
+	client := backend.client()
+	r := resourceMapping()
+
+	mappingPath := filepath.Join(t.TempDir(), "alerts.csv")
+	if err := os.WriteFile(mappingPath, []byte("alert_name,team\nhigh_error_rate,platform\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := map[string]interface{}{
+		"name":              "alerts-mapping",
+		"mapping_file_path": mappingPath,
+		"matchers":          []interface{}{"alert_name"},
+	}
+
+	state, diags := applyMockResource(t, r, nil, config, client)
+	if diags.HasError() {
+		t.Fatalf("unexpected error on create: %v", diags)
+	}
+	uploadedHash := state.Attributes["csv_content_hash"]
+
+	// a refresh keeps the hash of the uploaded file, so the changed file is still uploaded by the next apply
+	if err := os.WriteFile(mappingPath, []byte("alert_name,team\nhigh_error_rate,payments\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if state, diags = refreshMockResource(t, r, state, client); diags.HasError() || state == nil {
+		t.Fatalf("unexpected refresh result: %v, %v", state, diags)
+	}
+	if state.Attributes["csv_content_hash"] != uploadedHash || state.Attributes["mapping_file_path"] != mappingPath {
+		t.Errorf("expected the refresh to keep the uploaded file, got %v", state.Attributes)
+	}
+
+	if state, diags = applyMockResource(t, r, state, config, client); diags.HasError() {
+		t.Fatalf("unexpected error on update: %v", diags)
+	}
+	if backend.mappings[state.ID].Rows[0]["team"] != "payments" {
+		t.Errorf("expected the changed file to be uploaded, got %+v", backend.mappings[state.ID])
+	}
+}
+
+func TestMatcherDiagnostics(t *testing.T) {
+	rows := []map[string]string{{"service": "checkout", "team": "payments"}}
+
+	diags := matcherDiagnostics([]string{"service", "environment && team"}, rows)
+	if len(diags) != 1 {
+		t.Fatalf("expected a single error, got %v", diags)
+	}
+	if expected := cty.GetAttrPath("matchers").Index(cty.StringVal("environment && team")); !diags[0].AttributePath.Equals(expected) {
+		t.Errorf("expected the error to point at the invalid matcher, got %#v", diags[0].AttributePath)
+	}
+
+	diags = matcherDiagnostics([]string{"service"}, nil)
+	if len(diags) != 1 || !diags[0].AttributePath.Equals(cty.GetAttrPath("mapping_file_path")) {
+		t.Errorf("expected an empty file to point at mapping_file_path, got %v", diags)
+	}
+}
+
+func TestValidateMatcher(t *testing.T) {
+	cases := []struct {
+		matcher  string
+		hasError bool
+	}{
+		{matcher: "service", hasError: false},
+		{matcher: "service && team", hasError: false},
+		{matcher: "", hasError: true},
+		{matcher: "service &&", hasError: true},
+		{matcher: "service && && team", hasError: true},
+		{matcher: " service", hasError: true},
+	}
+
+	for _, tc := range cases {
+		diags := validateMatcher(tc.matcher, cty.GetAttrPath("matchers"))
+		if diags.HasError() != tc.hasError {
+			t.Errorf("matcher %q: expected error %t, got %v", tc.matcher, tc.hasError, diags)
+		}
+	}
+}
+
+func TestResourceMappingStateUpgradeV1(t *testing.T) {
+	currentDir := t.TempDir()
+	t.Chdir(currentDir)
+
+	cases := map[string]string{
+		filepath.Join(currentDir, "alerts.csv"):               "alerts.csv",
+		filepath.Join(currentDir, "mappings", "alerts.csv"):   filepath.Join("mappings", "alerts.csv"),
+		filepath.Join(filepath.Dir(currentDir), "alerts.csv"): filepath.Join(filepath.Dir(currentDir), "alerts.csv"),
+		"alerts.csv": "alerts.csv",
+	}
+
+	for path, expected := range cases {
+		actual, err := resourceMappingStateUpgradeV1(context.Background(), map[string]interface{}{"id": "42", "mapping_file_path": path}, nil)
+		if err != nil {
+			t.Fatalf("error upgrading state: %s", err)
+		}
+		if actual["mapping_file_path"] != expected {
+			t.Errorf("expected %q to be upgraded to %q, got %q", path, expected, actual["mapping_file_path"])
+		}
+	}
+}
+
+func TestResourceImportMapping(t *testing.T) {
+	backend := newMockBackend(t)
+	client := backend.client()
+	backend.mappings["1"] = Mapping{ID: "1", Name: "unique", FileName: "unique.csv"}
+	backend.mappings["2"] = Mapping{ID: "2", Name: "duplicate"}
+	backend.mappings["3"] = Mapping{ID: "3", Name: "duplicate"}
+	backend.mappings["4"] = Mapping{ID: "4", Name: "remote", FileName: "missing.csv", Rows: []map[string]string{
+		{"service": "api", "team": "platform"},
+		{"service": "web, frontend", "team": "web"},
+	}}
+
+	for importID, expectedID := range map[string]string{"1": "1", "name=unique": "1"} {
+		d := resourceMapping().Data(nil)
+		d.SetId(importID)
+		result, err := resourceImportMapping(context.Background(), d, client)
+		if err != nil || result[0].Id() != expectedID || result[0].Get("mapping_file_path") != "unique.csv" {
+			t.Errorf("unexpected import result of %s: %v, %v", importID, result, err)
+		}
+	}
+
+	for _, importID := range []string{"name=duplicate", "name=unknown"} {
+		d := resourceMapping().Data(nil)
+		d.SetId(importID)
+		if _, err := resourceImportMapping(context.Background(), d, client); err == nil {
+			t.Errorf("expected error importing %s", importID)
+		}
+	}
+
+	// import blocks with an identity instead of an id
+	d := resourceMapping().Data(&terraform.InstanceState{Identity: map[string]string{"id": "1"}})
+	if result, err := resourceImportMapping(context.Background(), d, client); err != nil || result[0].Id() != "1" {
+		t.Errorf("unexpected import result of the identity: %v, %v", result, err)
+	}
+
+	// without the mapping file, the rows of the backend become the csv content
+	d = resourceMapping().Data(nil)
+	d.SetId("4")
+	result, err := resourceImportMapping(context.Background(), d, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "service,team\napi,platform\n\"web, frontend\",web\n"
+	if result[0].Get("csv_content") != expected || result[0].Get("mapping_file_path") != "" ||
+		result[0].Get("csv_content_hash") != calculateContentHash([]byte(expected)) {
+		t.Errorf("expected the rows as csv content, got %q", result[0].Get("csv_content"))
+	}
+}
+
+func TestResourceMapping_MockBackendContent(t *testing.T) {
+	backend := newMockBackend(t)
+	client := backend.client()
+	r := resourceMapping()
+
+	config := map[string]interface{}{
+		"name":        "inline",
+		"csv_content": "alert_name,team\nhigh_error_rate,platform\n",
+		"matchers":    []interface{}{"alert_name"},
+	}
+	state, diags := applyMockResource(t, r, nil, config, client)
+	if diags.HasError() {
+		t.Fatalf("unexpected error on create: %v", diags)
+	}
+	mapping := backend.mappings[state.ID]
+	if mapping.FileName != "inline.csv" || len(mapping.Rows) != 1 || mapping.Rows[0]["team"] != "platform" {
+		t.Errorf("expected the rows of the csv content, got %+v", mapping)
+	}
+	if state.Attributes["enriched_attributes.#"] != "1" {
+		t.Errorf("expected the columns which are not matched as enriched attributes, got %v", state.Attributes)
+	}
+
+	// changes of the content replace the mapping
+	config["csv_content"] = "alert_name,team\nhigh_error_rate,web\n"
+	if state, diags = applyMockResource(t, r, state, config, client); diags.HasError() {
+		t.Fatalf("unexpected error on update: %v", diags)
+	}
+	if len(backend.mappings) != 1 || backend.mappings[state.ID].Rows[0]["team"] != "web" {
+		t.Errorf("expected the mapping to be replaced, got %v", sortedMockKeys(backend.mappings))
+	}
+}
+
+// deadlineClient records the time left until the deadline of the requests creating mappings
+type deadlineClient struct {
+	KeepClient
+	remaining time.Duration
+}
+
+func (c *deadlineClient) CreateMapping(ctx context.Context, mapping Mapping) (*Mapping, *ErrorResponse, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		c.remaining = time.Until(deadline)
+	}
+	return c.KeepClient.CreateMapping(ctx, mapping)
+}
+
+func TestResourceMapping_Timeouts(t *testing.T) {
+	backend := newMockBackend(t)
+	client := &deadlineClient{KeepClient: backend.client()}
+	r := resourceMapping()
+
+	mappingPath := filepath.Join(t.TempDir(), "alerts.csv")
+	if err := os.WriteFile(mappingPath, []byte("alert_name,team\nhigh_error_rate,platform\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := map[string]interface{}{
+		"name":              "alerts-mapping",
+		"mapping_file_path": mappingPath,
+		"matchers":          []interface{}{"alert_name"},
+		"timeouts":          map[string]interface{}{"create": "45m"},
+	}
+
+	if _, diags := applyMockResource(t, r, nil, config, client); diags.HasError() {
+		t.Fatalf("unexpected error on create: %v", diags)
+	}
+	if client.remaining <= 40*time.Minute || client.remaining > 45*time.Minute {
+		t.Errorf("expected the upload to be bounded by the create timeout, got %s", client.remaining)
+	}
+
+	config["name"] = "default-timeout"
+	delete(config, "timeouts")
+	if _, diags := applyMockResource(t, r, nil, config, client); diags.HasError() {
+		t.Fatalf("unexpected error on create: %v", diags)
+	}
+	if client.remaining <= 9*time.Minute || client.remaining > 10*time.Minute {
+		t.Errorf("expected the upload to be bounded by the default create timeout, got %s", client.remaining)
+	}
+}
@@ -2,20 +2,48 @@ package keep
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// webhook_status of providers with install_webhook
+const (
+	webhookStatusInstalled = "installed"
+	webhookStatusFailed    = "failed"
 )
 
 func resourceProvider() *schema.Resource {
-	return &schema.Resource{
+	r := &schema.Resource{
 		CreateContext: resourceCreateProvider,
 		ReadContext:   resourceReadProvider,
 		UpdateContext: resourceUpdateProvider,
 		DeleteContext: resourceDeleteProvider,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceImportProvider,
+		},
+		Identity: &schema.ResourceIdentity{
+			SchemaFunc: providerIdentitySchema,
+		},
+		CustomizeDiff: customdiff.All(
+			customizeDiffProviderAuthConfig,
+			customizeDiffProviderWebhook,
+		),
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(10 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
 		},
 		Schema: map[string]*schema.Schema{
 			"type": {
@@ -25,37 +53,398 @@ func resourceProvider() *schema.Resource {
 				ForceNew:    true,
 			},
 			"name": {
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Required:     true,
+				Description:  "Name of the keep provider",
+			},
+			"tenant_id": {
 				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Name of the keep provider",
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Tenant to install the provider into, uses the tenant_id of the provider block or the tenant of the API key if not set",
 			},
 			"auth_config": {
-				Type:        schema.TypeMap,
-				Required:    true,
-				Sensitive:   true,
-				Description: "Configuration of the keep provider authentication",
+				Type:             schema.TypeMap,
+				Optional:         true,
+				Sensitive:        true,
+				ExactlyOneOf:     providerAuthConfigFields(),
+				ValidateDiagFunc: validateAuthConfigURLs,
+				Description: "Configuration of the keep provider authentication. " +
+					"Values can reference secrets as `env://<VAR>` or `file://<path>`, which are resolved when applying",
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
 			},
+			"auth_config_wo": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				WriteOnly:    true,
+				Sensitive:    true,
+				ExactlyOneOf: providerAuthConfigFields(),
+				ValidateFunc: validation.StringIsJSON,
+				RequiredWith: []string{"auth_config_wo_version"},
+				Description: "Write-only configuration of the keep provider authentication as JSON object, e.g. `jsonencode({...})`. " +
+					"It is never stored in state, change auth_config_wo_version to apply a new value",
+			},
+			"auth_config_wo_version": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+				RequiredWith: []string{"auth_config_wo"},
+				Description:  "Version of auth_config_wo, changing it updates the provider with the current auth_config_wo",
+			},
+			"deletion_protection": deletionProtectionSchema("provider"),
 			"install_webhook": {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Default:     false,
-				Description: "Install webhook for the provider (default: false)",
+				Description: "Install webhook for the provider, the webhook is uninstalled when disabled or on destroy if the backend supports it. Keep doesn't offer uninstalling webhooks yet, they have to be removed from the source system manually (default: false)",
+			},
+			"check_workflow_references": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Fail the deletion of the provider if workflows reference it as `providers.<name>` (default: false)",
+			},
+			"reinstall_on_drift": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Plan an update with the declared auth config if the masked secrets in the backend no longer match it, e.g. because a token was rotated in the UI. " +
+					"Fully masked secrets can't be compared, use auth_config_wo and auth_config_wo_version to apply them again (default: false)",
+			},
+			"webhook_events": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Webhook integrations or event types to install for providers which support multiple, e.g. `alerting`. Installs all if not set, only used if install_webhook is true",
+			},
+			"mode": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ValidateFunc:  validation.StringInSlice([]string{"push", "pull", "both"}, false),
+				ConflictsWith: []string{"pulling_enabled"},
+				Description: "How the provider receives alerts, one of `push` (webhook only), `pull` or `both`. " +
+					"With `push` pulling is disabled and the credentials required for pulling are not validated",
+			},
+			"pulling_enabled": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Pull alerts from the provider, if the provider supports pulling (default: true)",
+			},
+			"pulling_interval": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "Interval in seconds in which alerts are pulled from the provider. Uses the backend default if not set",
+			},
+			"validate_connection": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Test the connection of the provider after install and on changes, the apply fails if the provider can't reach its target (default: false)",
+			},
+			"required_scopes": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Scopes the provider must be granted, the apply fails if any of them could not be validated",
+			},
+			"validated_scopes": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Result of the scope validation by scope, either `true` or the reason the scope is missing",
+			},
+			"scopes": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Scopes of the provider type reported by the backend and whether they are granted",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the scope",
+						},
+						"description": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Description of the scope",
+						},
+						"mandatory": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the scope is required to install the provider",
+						},
+						"mandatory_for_webhook": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the scope is required to install the webhook",
+						},
+						"granted": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the scope was validated as granted",
+						},
+					},
+				},
+			},
+			"last_alert_received": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Time the last alert of the provider was received, empty if no alert was received yet",
+			},
+			"installed_by": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "User who installed the provider",
+			},
+			"installation_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Time the provider was installed",
+			},
+			"last_pull_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Time alerts were last pulled from the provider, empty if they were never pulled",
+			},
+			"webhook_status": {
+				Type:     schema.TypeString,
+				Computed: true,
+				Description: "Status of the webhook, `installed` or `failed` if the installation failed, e.g. because of missing permissions in the source system. " +
+					"Failed installations are retried on the next apply. Empty if install_webhook is false.",
+			},
+			"webhook_url": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "URL the source system sends alerts to, set if install_webhook is true or mode is push or both",
+			},
+			"webhook_api_key": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "API key the source system authenticates with when sending alerts, set if install_webhook is true or mode is push or both",
 			},
 		},
 	}
+
+	addProviderTypeConfigsToSchema(r.Schema)
+
+	return r
 }
 
-func resourceCreateProvider(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(KeepClient)
+// providerIdentitySchema identifies providers by their id and the tenant they are installed into
+func providerIdentitySchema() map[string]*schema.Schema {
+	identity := idIdentitySchema()
+	identity["tenant_id"] = &schema.Schema{
+		Type:              schema.TypeString,
+		OptionalForImport: true,
+		Description:       "Tenant the provider is installed into, the tenant of the API key if not set",
+	}
+	return identity
+}
+
+// customizeDiffProviderAuthConfig validates auth_config against the config schema of the provider type,
+// so missing or unknown keys fail during plan instead of during the installation
+func customizeDiffProviderAuthConfig(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.HasChanges(providerAuthConfigChanges("type")...) || !d.NewValueKnown("type") || !d.NewValueKnown("auth_config") {
+		return nil
+	}
+
 	providerType := d.Get("type").(string)
-	providerName := d.Get("name").(string)
 	authConfig := d.Get("auth_config").(map[string]interface{})
+	if blockType, block := typedProviderConfig(d.Get); blockType != "" {
+		if blockType != providerType {
+			return fmt.Errorf("the %s block can only be used with type '%s', not '%s'", blockType, blockType, providerType)
+		}
+		if !d.NewValueKnown(blockType) {
+			return nil
+		}
+		authConfig = typedProviderAuthConfig(blockType, block)
+	} else if rawConfig := d.GetRawConfig(); !rawConfig.IsNull() && rawConfig.IsKnown() && !rawConfig.GetAttr("auth_config_wo").IsNull() {
+		wo := rawConfig.GetAttr("auth_config_wo")
+		if !wo.IsKnown() {
+			return nil
+		}
+		var err error
+		if authConfig, err = decodeWriteOnlyAuthConfig(wo.AsString()); err != nil {
+			return err
+		}
+	}
+
+	client := m.(KeepClient).WithTenant(d.Get("tenant_id").(string))
+	providers, errResp, err := client.GetAvailableProviders(ctx)
+	if err != nil {
+		if errResp != nil {
+			return fmt.Errorf("Failed to get available providers: %s. Details: %s", errResp.Error, errResp.Details)
+		}
+		return fmt.Errorf("Failed to get available providers: %s", err.Error())
+	}
+
+	return validateProviderAuthConfig(providers, providerType, authConfig, d.Get("mode").(string) == "push")
+}
+
+// customizeDiffProviderWebhook plans to install the webhook again if its installation failed
+func customizeDiffProviderWebhook(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.HasChanges("install_webhook", "webhook_events") || (d.Get("install_webhook").(bool) && d.Get("webhook_status").(string) == webhookStatusFailed) {
+		return d.SetNewComputed("webhook_status")
+	}
+	return nil
+}
+
+// validateProviderAuthConfig checks that the auth config contains all required and no unknown keys
+// of the config schema of the provider type. Provider types without a config schema are not validated.
+// Providers which only receive alerts via webhook don't need the required keys.
+func validateProviderAuthConfig(providers []KeepProvider, providerType string, authConfig map[string]interface{}, pushOnly bool) error {
+	var config map[string]ProviderConfigField
+	availableTypes := make([]string, 0)
+	found := false
+	for _, provider := range providers {
+		availableTypes = append(availableTypes, provider.Type)
+		if provider.Type == providerType {
+			found = true
+			config = provider.Config
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("Provider type '%s' not found. Available provider types: %v", providerType, availableTypes)
+	}
+
+	if len(config) == 0 {
+		return nil
+	}
+
+	missing := make([]string, 0)
+	for key, field := range config {
+		if field.Required && !pushOnly {
+			if _, exists := authConfig[key]; !exists {
+				missing = append(missing, key)
+			}
+		}
+	}
+
+	unknown := make([]string, 0)
+	for key := range authConfig {
+		if _, exists := config[key]; !exists {
+			unknown = append(unknown, key)
+		}
+	}
+
+	if len(missing) == 0 && len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(missing)
+	sort.Strings(unknown)
+	known := make([]string, 0, len(config))
+	for key := range config {
+		known = append(known, key)
+	}
+	sort.Strings(known)
+
+	return fmt.Errorf("invalid auth_config for provider type '%s': missing required keys %v, unknown keys %v. Available keys: %v",
+		providerType, missing, unknown, known)
+}
+
+// decodeWriteOnlyAuthConfig decodes the JSON object of auth_config_wo, values are converted to strings like in auth_config
+func decodeWriteOnlyAuthConfig(value string) (map[string]interface{}, error) {
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		return nil, fmt.Errorf("invalid auth_config_wo: must be a JSON object: %s", err)
+	}
+
+	authConfig := make(map[string]interface{}, len(decoded))
+	for k, v := range decoded {
+		if _, ok := v.(string); !ok {
+			encoded, _ := json.Marshal(v)
+			v = string(encoded)
+		}
+		authConfig[k] = v
+	}
+	return authConfig, nil
+}
+
+// providerAuthConfig returns the typed configuration block, auth_config or the decoded auth_config_wo.
+// The write-only value is only available in the configuration during create and update.
+func providerAuthConfig(d *schema.ResourceData) (map[string]interface{}, error) {
+	if blockType, block := typedProviderConfig(d.Get); blockType != "" {
+		return typedProviderAuthConfig(blockType, block), nil
+	}
+	if rawConfig := d.GetRawConfig(); !rawConfig.IsNull() && rawConfig.IsKnown() {
+		if wo := rawConfig.GetAttr("auth_config_wo"); !wo.IsNull() && wo.IsKnown() {
+			return decodeWriteOnlyAuthConfig(wo.AsString())
+		}
+	}
+	return d.Get("auth_config").(map[string]interface{}), nil
+}
+
+// providerPayload builds the provider configuration sent on install and update
+func providerPayload(d *schema.ResourceData) (map[string]interface{}, error) {
+	payload := map[string]interface{}{
+		"provider_name":   d.Get("name").(string),
+		"pulling_enabled": d.Get("pulling_enabled").(bool),
+	}
+	if mode, ok := d.GetOk("mode"); ok {
+		payload["pulling_enabled"] = mode.(string) != "push"
+	}
+	if pullingInterval, ok := d.GetOk("pulling_interval"); ok {
+		payload["pulling_interval"] = pullingInterval.(int)
+	}
+	authConfig, err := providerAuthConfig(d)
+	if err != nil {
+		return nil, err
+	}
+	if authConfig, err = resolveAuthConfig(authConfig); err != nil {
+		return nil, err
+	}
+	for k, v := range authConfig {
+		payload[k] = v
+	}
+	return payload, nil
+}
+
+// installProvider installs a provider, retrying transient failures which the client doesn't retry for POST requests.
+// An attempt failing with a transient error may still have installed the provider, so a conflict on a retry adopts
+// the installed provider with the same name.
+func installProvider(ctx context.Context, client KeepClient, installPayload map[string]interface{}) (*KeepProvider, *ErrorResponse, error) {
+	var response *KeepProvider
+	var errResp *ErrorResponse
+	attempts := 0
+	err := retryTransient(ctx, func() (err error) {
+		attempts++
+		response, errResp, err = client.InstallProvider(ctx, installPayload)
+		if err == nil || attempts == 1 || !errors.Is(err, ErrConflict) {
+			return err
+		}
+
+		providers, _, listErr := client.GetInstalledProviders(ctx)
+		if listErr != nil {
+			return err
+		}
+		for _, provider := range providers {
+			if provider.Type == installPayload["provider_id"] && provider.Details.Name == installPayload["provider_name"] {
+				response, errResp = &KeepProvider{ID: provider.ID}, nil
+				return nil
+			}
+		}
+		return err
+	})
+	return response, errResp, err
+}
+
+func resourceCreateProvider(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(KeepClient).WithTenant(d.Get("tenant_id").(string))
+	providerType := d.Get("type").(string)
 
 	// First validate if the provider type exists
-	providers, errResp, err := client.GetAvailableProviders()
+	providers, errResp, err := client.GetAvailableProviders(ctx)
 	if err != nil {
 		if errResp != nil {
 			return diag.Errorf("Failed to get available providers: %s. Details: %s", errResp.Error, errResp.Details)
@@ -66,32 +455,26 @@ func resourceCreateProvider(ctx context.Context, d *schema.ResourceData, m inter
 	found := false
 	availableTypes := make([]string, 0)
 	for _, provider := range providers {
-		if p, ok := provider.(map[string]interface{}); ok {
-			if pType, exists := p["type"].(string); exists {
-				availableTypes = append(availableTypes, pType)
-				if pType == providerType {
-					found = true
-					break
-				}
-			}
+		availableTypes = append(availableTypes, provider.Type)
+		if provider.Type == providerType {
+			found = true
+			break
 		}
 	}
 
 	if !found {
-		return diag.Errorf("Provider type '%s' not found. Available provider types: %v", providerType, availableTypes)
+		return attributeErrorf(cty.GetAttrPath("type"), "Provider type '%s' not found. Available provider types: %v", providerType, availableTypes)
 	}
 
 	// Prepare installation payload
-	installPayload := map[string]interface{}{
-		"provider_id":   providerType,
-		"provider_name": providerName,
-	}
-	for k, v := range authConfig {
-		installPayload[k] = v
+	installPayload, err := providerPayload(d)
+	if err != nil {
+		return diag.FromErr(err)
 	}
+	installPayload["provider_id"] = providerType
 
 	// Install provider
-	response, errResp, err := client.InstallProvider(installPayload)
+	response, errResp, err := installProvider(ctx, client, installPayload)
 	if err != nil {
 		if errResp != nil {
 			if strings.Contains(errResp.Details, "Missing required scopes") {
@@ -106,37 +489,213 @@ func resourceCreateProvider(ctx context.Context, d *schema.ResourceData, m inter
 		return diag.Errorf("Provider installation failed: received empty response. Payload: %v", installPayload)
 	}
 
-	if response["id"] == nil {
-		return diag.Errorf("Provider installation failed: no ID returned in response. Response: %v, Payload: %v", response, installPayload)
+	if response.ID == "" {
+		return diag.Errorf("Provider installation failed: no ID returned in response. Response: %+v, Payload: %v", *response, installPayload)
 	}
 
-	id := response["id"].(string)
-	d.SetId(id)
+	d.SetId(string(response.ID))
+
+	// the backend is eventually consistent, reads right after the install can miss the provider
+	if err := waitUntilVisible(ctx, client, "provider", d.Id(), func() (bool, error) {
+		provider, _, err := client.GetInstalledProvider(ctx, d.Id())
+		return provider != nil, err
+	}); err != nil {
+		return diag.FromErr(err)
+	}
 
-	// Install webhook if requested
+	// Install webhook if requested, a failed installation is retried on the next apply
+	var diags diag.Diagnostics
 	if d.Get("install_webhook").(bool) {
-		errResp, err := client.InstallProviderWebhook(providerType, id)
-		if err != nil {
-			if errResp != nil {
-				if strings.Contains(errResp.Details, "Missing required scopes") {
-					return diag.Errorf("Failed to install webhook: insufficient permissions. %s", errResp.Details)
-				}
-				return diag.Errorf("Failed to install webhook: %s. Details: %s", errResp.Error, errResp.Details)
+		diags = installProviderWebhook(ctx, d, client)
+	}
+
+	if diags := validateProviderScopes(ctx, d, client); diags.HasError() {
+		return diags
+	}
+
+	if d.Get("validate_connection").(bool) {
+		if diags := testProviderConnection(ctx, d, client); diags.HasError() {
+			return diags
+		}
+	}
+
+	return append(diags, resourceReadProvider(ctx, d, m)...)
+}
+
+// testProviderConnection lets the backend fetch alerts with the provider config and fails if that is not possible
+func testProviderConnection(ctx context.Context, d *schema.ResourceData, client KeepClient) diag.Diagnostics {
+	testPayload, err := providerPayload(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	delete(testPayload, "pulling_enabled")
+	delete(testPayload, "pulling_interval")
+	testPayload["provider_id"] = d.Id()
+	testPayload["provider_type"] = d.Get("type").(string)
+
+	errResp, err := client.TestProvider(ctx, testPayload)
+	if err != nil {
+		if errResp != nil {
+			return diag.Errorf("Provider connection test failed: %s. Details: %s", errResp.Error, errResp.Details)
+		}
+		return diag.Errorf("Provider connection test failed: %s", err.Error())
+	}
+
+	return nil
+}
+
+// validateProviderScopes validates the scopes of the installed provider and fails if a required scope is missing
+func validateProviderScopes(ctx context.Context, d *schema.ResourceData, client KeepClient) diag.Diagnostics {
+	scopes, errResp, err := client.ValidateProviderScopes(ctx, d.Id())
+	if err != nil {
+		if errResp != nil {
+			return diag.Errorf("Failed to validate provider scopes: %s. Details: %s", errResp.Error, errResp.Details)
+		}
+		return diag.Errorf("Failed to validate provider scopes: %s", err.Error())
+	}
+
+	validated := make(map[string]interface{}, len(scopes))
+	for scope, result := range scopes {
+		validated[scope] = fmt.Sprintf("%v", result)
+	}
+
+	if err := d.Set("validated_scopes", validated); err != nil {
+		return diag.Errorf("Failed to set validated_scopes: %s", err.Error())
+	}
+
+	missing := make([]string, 0)
+	for _, scope := range d.Get("required_scopes").([]interface{}) {
+		if result, ok := validated[scope.(string)]; !ok || result != "true" {
+			missing = append(missing, fmt.Sprintf("%s (%v)", scope, result))
+		}
+	}
+
+	if len(missing) > 0 {
+		return attributeErrorf(cty.GetAttrPath("required_scopes"), "Provider is missing required scopes: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// installProviderWebhook installs the webhook of the provider with the selected webhook_events. A failed
+// installation is only a warning, so the installed provider is kept in state instead of being tainted:
+// webhook_status is failed then and the next apply installs the webhook again.
+func installProviderWebhook(ctx context.Context, d *schema.ResourceData, client KeepClient) diag.Diagnostics {
+	events := make([]string, 0)
+	for _, event := range d.Get("webhook_events").(*schema.Set).List() {
+		events = append(events, event.(string))
+	}
+	sort.Strings(events)
+
+	errResp, err := client.InstallProviderWebhook(ctx, d.Get("type").(string), d.Id(), events)
+	if err != nil {
+		detail := err.Error()
+		if errResp != nil {
+			if strings.Contains(errResp.Details, "Missing required scopes") {
+				detail = fmt.Sprintf("insufficient permissions. %s", errResp.Details)
+			} else {
+				detail = fmt.Sprintf("%s. Details: %s", errResp.Error, errResp.Details)
 			}
-			return diag.Errorf("Failed to install webhook: %s", err.Error())
 		}
+		if err := d.Set("webhook_status", webhookStatusFailed); err != nil {
+			return diag.Errorf("Failed to set webhook_status: %s", err.Error())
+		}
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  "Failed to install webhook",
+			Detail:   fmt.Sprintf("Failed to install the webhook of provider %s: %s. The installation is retried on the next apply.", d.Id(), detail),
+		}}
 	}
 
-	return resourceReadProvider(ctx, d, m)
+	if err := d.Set("webhook_status", webhookStatusInstalled); err != nil {
+		return diag.Errorf("Failed to set webhook_status: %s", err.Error())
+	}
+	return nil
+}
+
+// isProviderWebhookInstalled reports whether the webhook of the provider was installed, states written before
+// webhook_status was added have no status
+func isProviderWebhookInstalled(installWebhook bool, status string) bool {
+	return installWebhook && status != webhookStatusFailed
+}
+
+// uninstallProviderWebhook removes the webhook of the provider from the source system. Backends which don't
+// list an endpoint for uninstalling webhooks only produce a warning, the webhook has to be removed manually then.
+func uninstallProviderWebhook(ctx context.Context, d *schema.ResourceData, client KeepClient) diag.Diagnostics {
+	errResp, err := client.UninstallProviderWebhook(ctx, d.Get("type").(string), d.Id())
+	if err != nil {
+		if errors.Is(err, errWebhookUninstallUnsupported) {
+			return diag.Diagnostics{{
+				Severity: diag.Warning,
+				Summary:  "Webhook not uninstalled",
+				Detail:   fmt.Sprintf("The backend does not support uninstalling webhooks, remove the webhook of provider %s from the source system manually", d.Id()),
+			}}
+		}
+		if errResp != nil {
+			if strings.Contains(errResp.Details, "Missing required scopes") {
+				return diag.Errorf("Failed to uninstall webhook: insufficient permissions. %s", errResp.Details)
+			}
+			return diag.Errorf("Failed to uninstall webhook: %s. Details: %s", errResp.Error, errResp.Details)
+		}
+		return diag.Errorf("Failed to uninstall webhook: %s", err.Error())
+	}
+
+	return nil
+}
+
+// checkProviderWorkflowReferences fails if the raw definition of a workflow references the provider by name
+func checkProviderWorkflowReferences(ctx context.Context, d *schema.ResourceData, client KeepClient) diag.Diagnostics {
+	workflows, errResp, err := client.ListWorkflows(ctx)
+	if err != nil {
+		if errResp != nil {
+			return diag.Errorf("Failed to list workflows: %s. Details: %s", errResp.Error, errResp.Details)
+		}
+		return diag.Errorf("Failed to list workflows: %s", err.Error())
+	}
+
+	name := d.Get("name").(string)
+	reference := regexp.MustCompile(`providers\.` + regexp.QuoteMeta(name) + `($|[^\w-])`)
+
+	dependents := make([]string, 0)
+	for _, workflow := range workflows {
+		if reference.MatchString(workflow.WorkflowRaw) {
+			dependents = append(dependents, fmt.Sprintf("%s (%s)", workflow.Name, workflow.ID))
+		}
+	}
+
+	if len(dependents) > 0 {
+		sort.Strings(dependents)
+		return diag.Errorf("Provider '%s' is referenced by %d workflows: %s", name, len(dependents), strings.Join(dependents, ", "))
+	}
+
+	return nil
 }
 
 func resourceDeleteProvider(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Client)
+	if diags := checkDeletionProtection(d, "provider"); diags.HasError() {
+		return diags
+	}
+
+	client := m.(KeepClient).WithTenant(d.Get("tenant_id").(string))
 
 	id := d.Id()
 	providerType := d.Get("type").(string)
 
-	errResp, err := client.DeleteProvider(providerType, id)
+	if d.Get("check_workflow_references").(bool) {
+		if diags := checkProviderWorkflowReferences(ctx, d, client); diags.HasError() {
+			return diags
+		}
+	}
+
+	var diags diag.Diagnostics
+	if isProviderWebhookInstalled(d.Get("install_webhook").(bool), d.Get("webhook_status").(string)) {
+		diags = uninstallProviderWebhook(ctx, d, client)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	errResp, err := client.DeleteProvider(ctx, providerType, id)
 	if err != nil {
 		if errResp != nil {
 			if strings.Contains(errResp.Details, "Missing required scopes") {
@@ -147,14 +706,14 @@ func resourceDeleteProvider(ctx context.Context, d *schema.ResourceData, m inter
 		return diag.Errorf("Failed to delete provider: %s", err.Error())
 	}
 
-	return nil
+	return diags
 }
 
 func resourceReadProvider(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(KeepClient)
+	client := m.(KeepClient).WithTenant(d.Get("tenant_id").(string))
 	id := d.Id()
 
-	providers, errResp, err := client.GetInstalledProviders()
+	p, errResp, err := client.GetInstalledProvider(ctx, id)
 	if err != nil {
 		if errResp != nil {
 			if strings.Contains(errResp.Details, "Missing required scopes") {
@@ -165,105 +724,400 @@ func resourceReadProvider(ctx context.Context, d *schema.ResourceData, m interfa
 		return diag.Errorf("Failed to get installed providers: %s", err.Error())
 	}
 
-	for _, provider := range providers {
-		p := provider.(map[string]interface{})
-		if p["id"] == id {
-			if err := d.Set("type", p["type"]); err != nil {
-				return diag.Errorf("Failed to set type: %s", err.Error())
+	if p == nil {
+		d.SetId("")
+		return nil
+	}
+
+	if err := d.Set("type", p.Type); err != nil {
+		return diag.Errorf("Failed to set type: %s", err.Error())
+	}
+
+	if p.PullingEnabled != nil {
+		if diags := setProviderPulling(d, *p.PullingEnabled); diags.HasError() {
+			return diags
+		}
+	}
+
+	if p.PullingInterval != nil {
+		if err := d.Set("pulling_interval", *p.PullingInterval); err != nil {
+			return diag.Errorf("Failed to set pulling_interval: %s", err.Error())
+		}
+	}
+
+	// a provider renamed in the UI is planned to be renamed back
+	if err := d.Set("name", p.Details.Name); err != nil {
+		return diag.Errorf("Failed to set name: %s", err.Error())
+	}
+
+	if auth := p.Details.Authentication; auth != nil {
+		// With auth_config_wo the credentials are kept out of state
+		if blockType, block := typedProviderConfig(d.Get); blockType != "" {
+			if err := d.Set(blockType, []interface{}{typedProviderConfigFromRemote(blockType, block, auth, d.Get("reinstall_on_drift").(bool))}); err != nil {
+				return diag.Errorf("Failed to set %s: %s", blockType, err.Error())
+			}
+		} else if d.Get("auth_config_wo_version").(int) == 0 {
+			authConfig := mergeMaskedAuthConfig(d.Get("auth_config").(map[string]interface{}), auth, d.Get("reinstall_on_drift").(bool))
+			if err := d.Set("auth_config", authConfig); err != nil {
+				return diag.Errorf("Failed to set auth_config: %s", err.Error())
 			}
+		}
+	}
 
-			if details, ok := p["details"].(map[string]interface{}); ok {
-				if name, exists := details["name"].(string); exists {
-					if err := d.Set("name", name); err != nil {
-						return diag.Errorf("Failed to set name: %s", err.Error())
-					}
-				}
+	if err := d.Set("scopes", flattenProviderScopes(*p)); err != nil {
+		return diag.Errorf("Failed to set scopes: %s", err.Error())
+	}
+
+	if diags := setProviderHealth(d, *p); diags.HasError() {
+		return diags
+	}
+
+	// states written before webhook_status was added only exist if the webhook was installed
+	if d.Get("install_webhook").(bool) && d.Get("webhook_status").(string) == "" {
+		if err := d.Set("webhook_status", webhookStatusInstalled); err != nil {
+			return diag.Errorf("Failed to set webhook_status: %s", err.Error())
+		}
+	}
+
+	if diags := setIdentity(d, map[string]string{"tenant_id": d.Get("tenant_id").(string)}); diags.HasError() {
+		return diags
+	}
+
+	return setProviderWebhookSettings(ctx, d, client)
+}
+
+// resourceImportProvider supports importing by "<id>", "<type>/<id>", by name using the "name=<provider-name>" syntax
+// or by identity. Type, name and the non-sensitive auth config are taken from the installed provider, secrets have to
+// be added to the configuration and are sent on the next apply.
+func resourceImportProvider(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	importID, err := importIDFromIdentity(d)
+	if err != nil {
+		return nil, err
+	}
+	if d.Id() == "" {
+		identity, err := d.Identity()
+		if err != nil {
+			return nil, fmt.Errorf("error getting identity: %s", err)
+		}
+		if err := d.Set("tenant_id", identity.Get("tenant_id")); err != nil {
+			return nil, err
+		}
+	}
+
+	client := m.(KeepClient).WithTenant(d.Get("tenant_id").(string))
+
+	// These attributes only exist in the configuration, use their defaults
+	if err := setAttributes(d, map[string]interface{}{
+		"install_webhook":           false,
+		"validate_connection":       false,
+		"reinstall_on_drift":        false,
+		"check_workflow_references": false,
+	}); err != nil {
+		return nil, err
+	}
+
+	providers, errResp, err := client.GetInstalledProviders(ctx)
+	if err != nil {
+		if errResp != nil {
+			return nil, fmt.Errorf("Failed to get installed providers: %s. Details: %s", errResp.Error, errResp.Details)
+		}
+		return nil, fmt.Errorf("Failed to get installed providers: %s", err.Error())
+	}
+
+	provider, err := findInstalledProvider(providers, importID)
+	if err != nil {
+		return nil, err
+	}
 
-				if auth, exists := details["authentication"].(map[string]interface{}); exists {
-					authConfig := make(map[string]interface{})
-					for key, value := range auth {
-						authConfig[key] = value
-					}
-					if err := d.Set("auth_config", authConfig); err != nil {
-						return diag.Errorf("Failed to set auth_config: %s", err.Error())
-					}
+	providerType := provider.Type
+	d.SetId(string(provider.ID))
+	if err := d.Set("type", providerType); err != nil {
+		return nil, err
+	}
+
+	if provider.Details.Name != "" {
+		if err := d.Set("name", provider.Details.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	auth := provider.Details.Authentication
+	if len(auth) == 0 {
+		return []*schema.ResourceData{d}, nil
+	}
+
+	available, errResp, err := client.GetAvailableProviders(ctx)
+	if err != nil {
+		if errResp != nil {
+			return nil, fmt.Errorf("Failed to get available providers: %s. Details: %s", errResp.Error, errResp.Details)
+		}
+		return nil, fmt.Errorf("Failed to get available providers: %s", err.Error())
+	}
+
+	if err := d.Set("auth_config", nonSensitiveAuthConfig(available, providerType, auth)); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// findInstalledProvider finds an installed provider by "<id>", "<type>/<id>" or "name=<provider-name>"
+func findInstalledProvider(providers []KeepProvider, importID string) (*KeepProvider, error) {
+	name, byName := strings.CutPrefix(importID, "name=")
+	providerType, id, byType := strings.Cut(importID, "/")
+	if !byType {
+		id = importID
+	}
+
+	matches := make([]*KeepProvider, 0)
+	ids := make([]string, 0)
+	for i := range providers {
+		p := &providers[i]
+		if byName {
+			if p.Details.Name != name {
+				continue
+			}
+		} else if string(p.ID) != id || (byType && p.Type != providerType) {
+			continue
+		}
+
+		matches = append(matches, p)
+		ids = append(ids, string(p.ID))
+	}
+
+	switch {
+	case len(matches) == 1:
+		return matches[0], nil
+	case len(matches) > 1:
+		return nil, fmt.Errorf("multiple providers with name '%s' found (ids: %v), import by id instead", name, ids)
+	case byName:
+		return nil, fmt.Errorf("provider with name '%s' not found", name)
+	default:
+		return nil, fmt.Errorf("provider '%s' not found", importID)
+	}
+}
+
+// nonSensitiveAuthConfig returns the auth config values which are neither masked nor marked as sensitive
+// in the config schema of the provider type
+func nonSensitiveAuthConfig(providers []KeepProvider, providerType string, auth map[string]interface{}) map[string]interface{} {
+	var config map[string]ProviderConfigField
+	for _, provider := range providers {
+		if provider.Type == providerType {
+			config = provider.Config
+			break
+		}
+	}
+
+	authConfig := make(map[string]interface{}, len(auth))
+	for key, value := range auth {
+		if config[key].Sensitive {
+			continue
+		}
+		str := fmt.Sprintf("%v", value)
+		if isMaskedValue(str) {
+			continue
+		}
+		authConfig[key] = str
+	}
+	return authConfig
+}
+
+// setProviderPulling sets pulling_enabled or, if the mode is configured, a mode matching the pulling state of the backend
+func setProviderPulling(d *schema.ResourceData, pullingEnabled bool) diag.Diagnostics {
+	mode := d.Get("mode").(string)
+	if mode == "" {
+		if err := d.Set("pulling_enabled", pullingEnabled); err != nil {
+			return diag.Errorf("Failed to set pulling_enabled: %s", err.Error())
+		}
+		return nil
+	}
+
+	switch {
+	case mode == "push" && pullingEnabled:
+		mode = "both"
+	case mode != "push" && !pullingEnabled:
+		mode = "push"
+	}
+	if err := d.Set("mode", mode); err != nil {
+		return diag.Errorf("Failed to set mode: %s", err.Error())
+	}
+	return nil
+}
+
+// isMaskedValue reports whether a value was masked by the backend, e.g. "********" or "sk-1***"
+func isMaskedValue(value string) bool {
+	return strings.Contains(value, "***") || (value != "" && strings.Trim(value, "*") == "")
+}
+
+// maskedValueMatches reports whether the characters left visible by the mask, e.g. "sk-1" of "sk-1***",
+// match a known value. Credential references are compared by the value they resolve to. Fully masked values
+// and references which can't be resolved are unknown, comparable is false for them.
+func maskedValueMatches(masked string, known string) (matches bool, comparable bool) {
+	if isCredentialReference(known) {
+		resolved, err := resolveCredentialReference(known)
+		if err != nil {
+			return false, false
+		}
+		known = resolved
+	}
+
+	first := strings.Index(masked, "*")
+	last := strings.LastIndex(masked, "*")
+	prefix, suffix := masked[:first], masked[last+1:]
+	if prefix == "" && suffix == "" {
+		return false, false
+	}
+	return len(known) >= len(prefix)+len(suffix) && strings.HasPrefix(known, prefix) && strings.HasSuffix(known, suffix), true
+}
+
+// mergeMaskedAuthConfig takes the auth config returned by the backend and replaces masked values with the
+// known values from state, so secrets don't show up as changed on every plan. Masked values which are not
+// known are left out, e.g. after an import. Credential references are kept as long as they match the backend
+// value. Added or removed keys and unmasked values are still taken from the backend. With detectDrift, masked
+// values whose visible characters don't match the known value are taken from the backend, so the change shows up.
+// Masked values which can't be compared keep the known value, only auth_config_wo_version applies them again.
+func mergeMaskedAuthConfig(current map[string]interface{}, remote map[string]interface{}, detectDrift bool) map[string]interface{} {
+	authConfig := make(map[string]interface{}, len(remote))
+	for key, value := range remote {
+		str := fmt.Sprintf("%v", value)
+		if isMaskedValue(str) {
+			if known, ok := current[key]; ok {
+				authConfig[key] = known
+				if matches, comparable := maskedValueMatches(str, fmt.Sprintf("%v", known)); detectDrift && comparable && !matches {
+					authConfig[key] = str
 				}
 			}
+			continue
+		}
 
-			return nil
+		// Keep credential references which resolve to the backend value or can't be resolved here
+		if known, ok := current[key].(string); ok && isCredentialReference(known) {
+			if resolved, err := resolveCredentialReference(known); err != nil || resolved == str {
+				authConfig[key] = known
+				continue
+			}
+		}
+		authConfig[key] = str
+	}
+	return authConfig
+}
+
+// flattenProviderScopes returns the scopes of an installed provider with the result of their last validation
+func flattenProviderScopes(provider KeepProvider) []interface{} {
+	scopes := make([]interface{}, 0, len(provider.Scopes))
+	for _, scope := range provider.Scopes {
+		scopes = append(scopes, map[string]interface{}{
+			"name":                  scope.Name,
+			"description":           scope.Description,
+			"mandatory":             scope.Mandatory,
+			"mandatory_for_webhook": scope.MandatoryForWebhook,
+			"granted":               provider.ValidatedScopes[scope.Name] == true,
+		})
+	}
+	return scopes
+}
+
+// setProviderHealth sets the installation state and the last received alert of an installed provider
+func setProviderHealth(d *schema.ResourceData, provider KeepProvider) diag.Diagnostics {
+	for key, value := range map[string]string{
+		"last_alert_received": provider.LastAlertReceived,
+		"installed_by":        provider.InstalledBy,
+		"installation_time":   provider.InstallationTime,
+		"last_pull_time":      provider.LastPullTime,
+	} {
+		if err := d.Set(key, value); err != nil {
+			return diag.Errorf("Failed to set %s: %s", key, err.Error())
 		}
 	}
 
-	d.SetId("")
 	return nil
 }
 
-func resourceUpdateProvider(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(KeepClient)
-	id := d.Id()
-	providerType := d.Get("type").(string)
+// setProviderWebhookSettings sets the webhook URL and API key of the provider if the webhook is installed
+// or the provider receives alerts by push
+func setProviderWebhookSettings(ctx context.Context, d *schema.ResourceData, client KeepClient) diag.Diagnostics {
+	webhookURL, webhookAPIKey := "", ""
 
-	if d.HasChanges("name", "auth_config", "install_webhook") {
-		// Since updates are not supported, we need to delete and recreate
-		// First delete the existing provider
-		errResp, err := client.DeleteProvider(providerType, id)
+	mode := d.Get("mode").(string)
+	if d.Get("install_webhook").(bool) || mode == "push" || mode == "both" {
+		settings, errResp, err := client.GetWebhookSettings(ctx)
 		if err != nil {
 			if errResp != nil {
-				return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
+				return diag.Errorf("Failed to get webhook settings: %s. Details: %s", errResp.Error, errResp.Details)
 			}
-			return diag.FromErr(err)
+			return diag.Errorf("Failed to get webhook settings: %s", err.Error())
 		}
 
-		// Then create a new one with updated configuration
-		createPayload := map[string]interface{}{
-			"provider_id":   providerType,
-			"provider_name": d.Get("name").(string),
+		if settings.WebhookAPI != "" {
+			webhookURL = fmt.Sprintf("%s/%s?provider_id=%s", strings.TrimSuffix(settings.WebhookAPI, "/"), d.Get("type").(string), d.Id())
 		}
+		webhookAPIKey = settings.APIKey
+	}
 
-		// Add auth config
-		for k, v := range d.Get("auth_config").(map[string]interface{}) {
-			createPayload[k] = v
+	if err := d.Set("webhook_url", webhookURL); err != nil {
+		return diag.Errorf("Failed to set webhook_url: %s", err.Error())
+	}
+	if err := d.Set("webhook_api_key", webhookAPIKey); err != nil {
+		return diag.Errorf("Failed to set webhook_api_key: %s", err.Error())
+	}
+
+	return nil
+}
+
+func resourceUpdateProvider(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(KeepClient).WithTenant(d.Get("tenant_id").(string))
+	id := d.Id()
+
+	if d.HasChanges(providerAuthConfigChanges("name", "mode", "pulling_enabled", "pulling_interval")...) {
+		updatePayload, err := providerPayload(d)
+		if err != nil {
+			return diag.FromErr(err)
 		}
 
-		// Create new provider
-		response, errResp, err := client.InstallProvider(createPayload)
+		errResp, err := client.UpdateProvider(ctx, id, updatePayload)
 		if err != nil {
 			if errResp != nil {
 				if strings.Contains(errResp.Details, "Missing required scopes") {
-					return diag.Errorf("Failed to install provider: insufficient permissions. %s", errResp.Details)
+					return diag.Errorf("Failed to update provider: insufficient permissions. %s", errResp.Details)
 				}
-				return diag.Errorf("Failed to install provider: %s. Details: %s. Payload: %v", errResp.Error, errResp.Details, createPayload)
+				return diag.Errorf("Failed to update provider: %s. Details: %s", errResp.Error, errResp.Details)
 			}
-			return diag.Errorf("Failed to install provider: %s. Payload: %v", err.Error(), createPayload)
+			return diag.Errorf("Failed to update provider: %s", err.Error())
 		}
+	}
 
-		if response == nil {
-			return diag.Errorf("Provider installation failed: received empty response. Payload: %v", createPayload)
-		}
+	// Install the webhook if it was enabled, other events were selected or the last installation failed
+	var diags diag.Diagnostics
+	if d.HasChanges("install_webhook", "webhook_events", "webhook_status") && d.Get("install_webhook").(bool) {
+		diags = installProviderWebhook(ctx, d, client)
+	}
 
-		if response["id"] == nil {
-			return diag.Errorf("Provider installation failed: no ID returned in response. Response: %v, Payload: %v", response, createPayload)
+	// Uninstall the webhook if it was disabled, so it doesn't keep delivering alerts
+	oldInstallWebhook, _ := d.GetChange("install_webhook")
+	oldStatus, _ := d.GetChange("webhook_status")
+	if d.HasChange("install_webhook") && !d.Get("install_webhook").(bool) {
+		if isProviderWebhookInstalled(oldInstallWebhook.(bool), oldStatus.(string)) {
+			diags = uninstallProviderWebhook(ctx, d, client)
+			if diags.HasError() {
+				return diags
+			}
 		}
+		if err := d.Set("webhook_status", ""); err != nil {
+			return diag.Errorf("Failed to set webhook_status: %s", err.Error())
+		}
+	}
 
-		// Set new ID
-		newID := response["id"].(string)
-		d.SetId(newID)
-
-		// Handle webhook if needed
-		if d.Get("install_webhook").(bool) {
-			errResp, err := client.InstallProviderWebhook(providerType, newID)
-			if err != nil {
-				if errResp != nil {
-					if strings.Contains(errResp.Details, "Missing required scopes") {
-						return diag.Errorf("Failed to install webhook: insufficient permissions. %s", errResp.Details)
-					}
-					return diag.Errorf("Failed to install webhook: %s. Details: %s", errResp.Error, errResp.Details)
-				}
-				return diag.Errorf("Failed to install webhook: %s", err.Error())
-			}
+	if d.HasChanges(providerAuthConfigChanges("name", "required_scopes")...) {
+		if diags := validateProviderScopes(ctx, d, client); diags.HasError() {
+			return diags
 		}
+	}
 
+	if d.Get("validate_connection").(bool) && d.HasChanges(providerAuthConfigChanges("validate_connection")...) {
+		if diags := testProviderConnection(ctx, d, client); diags.HasError() {
+			return diags
+		}
 	}
 
-	return resourceReadProvider(ctx, d, m)
+	return append(diags, resourceReadProvider(ctx, d, m)...)
 }
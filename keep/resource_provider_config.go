@@ -0,0 +1,267 @@
+package keep
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/spf13/cast"
+)
+
+// providerTypeConfigs are the typed configuration blocks of keep_provider by provider type.
+// The block name is the provider type, the attributes are the auth config keys of the provider.
+var providerTypeConfigs = map[string]map[string]*schema.Schema{
+	"grafana": {
+		"host": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+			Description:  "URL of the grafana instance",
+		},
+		"token": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Sensitive:   true,
+			Description: "Service account token of grafana",
+		},
+	},
+	"prometheus": {
+		"url": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+			Description:  "URL of the prometheus API",
+		},
+		"username": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Username for basic authentication",
+		},
+		"password": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Sensitive:   true,
+			Description: "Password for basic authentication",
+		},
+		"verify": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Verify the SSL certificate of prometheus (default: true)",
+		},
+	},
+	"pagerduty": {
+		"routing_key": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Sensitive:    true,
+			AtLeastOneOf: []string{"pagerduty.0.routing_key", "pagerduty.0.api_key"},
+			Description:  "Routing key of a pagerduty events integration",
+		},
+		"api_key": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Sensitive:    true,
+			AtLeastOneOf: []string{"pagerduty.0.routing_key", "pagerduty.0.api_key"},
+			Description:  "REST API key of pagerduty",
+		},
+		"service_id": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "ID of the pagerduty service",
+		},
+	},
+	"slack": {
+		"webhook_url": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Sensitive:    true,
+			ValidateFunc: validation.IsURLWithHTTPS,
+			AtLeastOneOf: []string{"slack.0.webhook_url", "slack.0.access_token"},
+			Description:  "URL of a slack incoming webhook",
+		},
+		"access_token": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Sensitive:    true,
+			AtLeastOneOf: []string{"slack.0.webhook_url", "slack.0.access_token"},
+			Description:  "OAuth access token of a slack app",
+		},
+		"channel": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Channel to send messages to when using access_token",
+		},
+	},
+	"cloudwatch": {
+		"region": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "AWS region of the cloudwatch alarms",
+		},
+		"access_key": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "AWS access key id, uses the default credentials of the backend if not set",
+		},
+		"access_key_secret": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Sensitive:    true,
+			RequiredWith: []string{"cloudwatch.0.access_key"},
+			Description:  "AWS secret access key",
+		},
+		"session_token": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Sensitive:   true,
+			Description: "AWS session token for temporary credentials",
+		},
+		"cloudwatch_sns_topic": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "ARN or name of the SNS topic the webhook subscribes to",
+		},
+	},
+}
+
+// providerAuthConfigFields are all attributes which configure the provider authentication
+func providerAuthConfigFields() []string {
+	return append([]string{"auth_config", "auth_config_wo"}, providerTypeConfigNames()...)
+}
+
+// providerAuthConfigChanges are the attributes whose changes require the provider to be updated with a new auth config
+func providerAuthConfigChanges(fields ...string) []string {
+	fields = append(fields, "auth_config", "auth_config_wo_version")
+	return append(fields, providerTypeConfigNames()...)
+}
+
+// providerTypeConfigNames returns the provider types with a typed configuration block in a stable order
+func providerTypeConfigNames() []string {
+	names := make([]string, 0, len(providerTypeConfigs))
+	for name := range providerTypeConfigs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// addProviderTypeConfigsToSchema adds a typed configuration block for every provider type in providerTypeConfigs
+func addProviderTypeConfigsToSchema(schemaMap map[string]*schema.Schema) {
+	fields := providerAuthConfigFields()
+	for providerType, config := range providerTypeConfigs {
+		schemaMap[providerType] = &schema.Schema{
+			Type:         schema.TypeList,
+			Optional:     true,
+			MaxItems:     1,
+			ExactlyOneOf: fields,
+			Description:  fmt.Sprintf("Configuration of a %s provider, can be used instead of auth_config if type is %s", providerType, providerType),
+			Elem:         &schema.Resource{Schema: config},
+		}
+	}
+}
+
+// typedProviderConfig returns the provider type and configuration block which is set, if any
+func typedProviderConfig(get func(string) interface{}) (string, map[string]interface{}) {
+	for _, providerType := range providerTypeConfigNames() {
+		if blocks, ok := get(providerType).([]interface{}); ok && len(blocks) > 0 {
+			block, _ := blocks[0].(map[string]interface{})
+			if block == nil {
+				block = map[string]interface{}{}
+			}
+			return providerType, block
+		}
+	}
+	return "", nil
+}
+
+// typedProviderAuthConfig converts a configuration block to auth config keys, empty optional values are left out
+func typedProviderAuthConfig(providerType string, block map[string]interface{}) map[string]interface{} {
+	authConfig := make(map[string]interface{}, len(block))
+	for key, field := range providerTypeConfigs[providerType] {
+		value, ok := block[key]
+		if !ok {
+			continue
+		}
+		if field.Type == schema.TypeString && value == "" {
+			continue
+		}
+		authConfig[key] = value
+	}
+	return authConfig
+}
+
+// typedProviderConfigFromRemote builds the configuration block from the auth config returned by the backend,
+// keeping the values from state for masked secrets like mergeMaskedAuthConfig
+func typedProviderConfigFromRemote(providerType string, current map[string]interface{}, remote map[string]interface{}, detectDrift bool) map[string]interface{} {
+	currentStrings := make(map[string]interface{}, len(current))
+	for key, value := range current {
+		currentStrings[key] = fmt.Sprintf("%v", value)
+	}
+	merged := mergeMaskedAuthConfig(currentStrings, remote, detectDrift)
+
+	block := make(map[string]interface{})
+	for key, field := range providerTypeConfigs[providerType] {
+		value, ok := merged[key]
+		if !ok {
+			block[key] = current[key]
+			continue
+		}
+		switch field.Type {
+		case schema.TypeBool:
+			block[key] = cast.ToBool(value)
+		default:
+			block[key] = value
+		}
+	}
+	return block
+}
+
+// resolveCredentialReference resolves "env://<VAR>" and "file://<path>" references of auth config values,
+// so secrets can be injected from the environment terraform runs in. Other values are returned as they are.
+func resolveCredentialReference(value string) (string, error) {
+	if name, ok := strings.CutPrefix(value, "env://"); ok {
+		resolved, exists := os.LookupEnv(name)
+		if !exists {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return resolved, nil
+	}
+
+	if path, ok := strings.CutPrefix(value, "file://"); ok {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("cannot read file: %s", err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+
+	return value, nil
+}
+
+// resolveAuthConfig resolves the credential references of all string values of an auth config
+func resolveAuthConfig(authConfig map[string]interface{}) (map[string]interface{}, error) {
+	resolved := make(map[string]interface{}, len(authConfig))
+	for key, value := range authConfig {
+		str, ok := value.(string)
+		if !ok {
+			resolved[key] = value
+			continue
+		}
+
+		resolvedValue, err := resolveCredentialReference(str)
+		if err != nil {
+			return nil, fmt.Errorf("cannot resolve auth config key %s: %s", key, err)
+		}
+		resolved[key] = resolvedValue
+	}
+	return resolved, nil
+}
+
+// isCredentialReference reports whether an auth config value is an "env://" or "file://" reference
+func isCredentialReference(value string) bool {
+	return strings.HasPrefix(value, "env://") || strings.HasPrefix(value, "file://")
+}
@@ -3,14 +3,18 @@ package keep
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
@@ -28,7 +32,7 @@ func TestAccResourceProvider(t *testing.T) {
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckResourceProviderExists("keep_provider.test"),
 					resource.TestCheckResourceAttr("keep_provider.test", "type", "aks"),
-					resource.TestCheckResourceAttr("keep_provider.test", "name", "test-aks"),
+					resource.TestCheckResourceAttr("keep_provider.test", "name", "tf-acc-aks"),
 				),
 			},
 		},
@@ -48,9 +52,8 @@ func testAccCheckResourceProviderExists(n string) resource.TestCheckFunc {
 		}
 
 		client := testAccProvider.Meta().(*Client)
-		time.Sleep(2 * time.Second) // Wait for provider creation
 
-		providers, errResp, err := client.GetInstalledProviders()
+		providers, errResp, err := client.GetInstalledProviders(context.Background())
 		if err != nil {
 			if errResp != nil {
 				return fmt.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
@@ -59,8 +62,7 @@ func testAccCheckResourceProviderExists(n string) resource.TestCheckFunc {
 		}
 
 		for _, provider := range providers {
-			p := provider.(map[string]interface{})
-			if p["id"] == rs.Primary.ID {
+			if string(provider.ID) == rs.Primary.ID {
 				return nil
 			}
 		}
@@ -77,7 +79,7 @@ func testAccCheckResourceProviderDestroy(s *terraform.State) error {
 			continue
 		}
 
-		providers, errResp, err := client.GetInstalledProviders()
+		providers, errResp, err := client.GetInstalledProviders(context.Background())
 		if err != nil {
 			if errResp != nil {
 				// Ignore API errors during destroy check as the resource might be already gone
@@ -87,8 +89,7 @@ func testAccCheckResourceProviderDestroy(s *terraform.State) error {
 		}
 
 		for _, provider := range providers {
-			p := provider.(map[string]interface{})
-			if p["id"] == rs.Primary.ID {
+			if string(provider.ID) == rs.Primary.ID {
 				return fmt.Errorf("provider still exists")
 			}
 		}
@@ -103,7 +104,7 @@ func testAccResourceProviderBasicConfig() string {
 
 resource "keep_provider" "test" {
   type = "aks"
-  name = "test-aks"
+  name = "tf-acc-aks"
   auth_config = {
     subscription_id     = "%s"
     client_id          = "%s"
@@ -161,7 +162,7 @@ func testAccResourceProviderWebhookConfig() string {
 
 resource "keep_provider" "test_webhook" {
   type = "grafana"
-  name = "test-grafana-webhook"
+  name = "tf-acc-grafana-webhook"
   auth_config = {
     host  = "https://grafana.example.com"
     token = "invalid-token"
@@ -177,7 +178,7 @@ func testAccResourceProviderUpdateConfig() string {
 
 resource "keep_provider" "test" {
   type = "grafana"
-  name = "test-grafana-update"
+  name = "tf-acc-grafana-update"
   auth_config = {
     host  = "https://grafana.example.com"
     token = "invalid-token"
@@ -213,7 +214,7 @@ func testAccResourceProviderUpdateWithWebhookConfig() string {
 
 resource "keep_provider" "test" {
   type = "grafana"
-  name = "test-grafana-webhook"
+  name = "tf-acc-grafana-webhook"
   auth_config = {
     host  = "https://grafana.example.com"
     token = "invalid-token"
@@ -315,66 +316,971 @@ func TestResourceProvider_MockErrors(t *testing.T) {
 	}
 }
 
-// Mock client for unit tests
+func TestResourceProvider_MockUpdateError(t *testing.T) {
+	client := &mockClient{
+		response:   []byte(`{"detail":"invalid token"}`),
+		statusCode: 400,
+	}
+
+	r := resourceProvider()
+	state := &terraform.InstanceState{
+		ID: "provider-id",
+		Attributes: map[string]string{
+			"type":            "test",
+			"name":            "test",
+			"auth_config.%":   "1",
+			"auth_config.key": "value",
+		},
+	}
+	diff := &terraform.InstanceDiff{
+		Attributes: map[string]*terraform.ResourceAttrDiff{
+			"auth_config.key": {Old: "value", New: "rotated"},
+		},
+	}
+
+	d, err := schema.InternalMap(r.Schema).Data(state, diff)
+	if err != nil {
+		t.Fatalf("error creating resource data: %s", err)
+	}
+
+	diags := resourceUpdateProvider(context.Background(), d, client)
+	if diags == nil {
+		t.Fatal("expected error diagnostics")
+	}
+
+	if !strings.Contains(diags[0].Summary, "Failed to update provider") {
+		t.Errorf("expected update error, got %q", diags[0].Summary)
+	}
+
+	if d.Id() != "provider-id" {
+		t.Errorf("expected provider id to be kept, got %q", d.Id())
+	}
+}
+
+func TestResourceProvider_MockRequiredScopes(t *testing.T) {
+	client := &mockClient{
+		response:   []byte(`{"id": "provider-id"}`),
+		statusCode: 200,
+		scopes: map[string]interface{}{
+			"alerts:read":  true,
+			"alerts:write": "Permission denied",
+		},
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceProvider().Schema, map[string]interface{}{
+		"type":            "test",
+		"name":            "test",
+		"auth_config":     map[string]interface{}{"key": "value"},
+		"required_scopes": []interface{}{"alerts:read", "alerts:write"},
+	})
+
+	diags := resourceCreateProvider(context.Background(), d, client)
+	if diags == nil {
+		t.Fatal("expected error diagnostics")
+	}
+
+	if !strings.Contains(diags[0].Summary, "missing required scopes: alerts:write (Permission denied)") {
+		t.Errorf("expected missing scope error, got %q", diags[0].Summary)
+	}
+	if !diags[0].AttributePath.Equals(cty.GetAttrPath("required_scopes")) {
+		t.Errorf("expected the error to point at required_scopes, got %#v", diags[0].AttributePath)
+	}
+
+	if v := d.Get("validated_scopes.alerts:read"); v != "true" {
+		t.Errorf("expected validated scope alerts:read to be true, got %v", v)
+	}
+}
+
+func TestResourceProvider_MockValidateConnection(t *testing.T) {
+	client := &mockClient{
+		response:   []byte(`{"id": "provider-id"}`),
+		statusCode: 200,
+		testError:  "Failed to connect to https://grafana.example.com",
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceProvider().Schema, map[string]interface{}{
+		"type":                "test",
+		"name":                "test",
+		"auth_config":         map[string]interface{}{"key": "value"},
+		"validate_connection": true,
+	})
+
+	diags := resourceCreateProvider(context.Background(), d, client)
+	if diags == nil {
+		t.Fatal("expected error diagnostics")
+	}
+
+	if !strings.Contains(diags[0].Summary, "Provider connection test failed") || !strings.Contains(diags[0].Summary, client.testError) {
+		t.Errorf("expected connection test error, got %q", diags[0].Summary)
+	}
+}
+
+func TestResourceProvider_MockDeleteUninstallsWebhook(t *testing.T) {
+	newResourceData := func() *schema.ResourceData {
+		d := schema.TestResourceDataRaw(t, resourceProvider().Schema, map[string]interface{}{
+			"type":            "test",
+			"name":            "test",
+			"auth_config":     map[string]interface{}{"key": "value"},
+			"install_webhook": true,
+		})
+		d.SetId("provider-id")
+		return d
+	}
+
+	client := &mockClient{
+		statusCode:           200,
+		uninstallUnsupported: true,
+	}
+	diags := resourceDeleteProvider(context.Background(), newResourceData(), client)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if len(diags) != 1 || diags[0].Severity != diag.Warning {
+		t.Errorf("expected a warning for the unsupported uninstall, got %v", diags)
+	}
+
+	// a backend listing the endpoint has to uninstall the webhook
+	client = &mockClient{
+		statusCode:          200,
+		uninstallStatusCode: 405,
+	}
+	if diags := resourceDeleteProvider(context.Background(), newResourceData(), client); !diags.HasError() {
+		t.Errorf("expected the failed uninstall to be an error, got %v", diags)
+	}
+	if client.webhookUninstalls != 1 {
+		t.Errorf("expected the webhook to be uninstalled once, got %d", client.webhookUninstalls)
+	}
+}
+
+func TestResourceProvider_MockImport(t *testing.T) {
+	client := &mockClient{
+		statusCode: 200,
+		installed: []KeepProvider{
+			{
+				ID:   "provider-id",
+				Type: "test",
+				Details: ProviderDetails{
+					Name: "ui-installed",
+					Authentication: map[string]interface{}{
+						"host":   "https://test.example.com",
+						"token":  "tok_secret",
+						"secret": "********",
+					},
+				},
+			},
+		},
+	}
+
+	for _, importID := range []string{"provider-id", "test/provider-id", "name=ui-installed"} {
+		t.Run(importID, func(t *testing.T) {
+			d := resourceProvider().Data(nil)
+			d.SetId(importID)
+
+			result, err := resourceImportProvider(context.Background(), d, client)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			imported := result[0]
+			if imported.Id() != "provider-id" || imported.Get("type") != "test" || imported.Get("name") != "ui-installed" {
+				t.Errorf("unexpected import result: id=%s type=%v name=%v", imported.Id(), imported.Get("type"), imported.Get("name"))
+			}
+
+			authConfig := imported.Get("auth_config").(map[string]interface{})
+			if len(authConfig) != 1 || authConfig["host"] != "https://test.example.com" {
+				t.Errorf("expected only the non-sensitive auth config, got %v", authConfig)
+			}
+		})
+	}
+
+	for _, importID := range []string{"other/provider-id", "unknown-id", "name=unknown"} {
+		d := resourceProvider().Data(nil)
+		d.SetId(importID)
+		if _, err := resourceImportProvider(context.Background(), d, client); err == nil {
+			t.Errorf("expected error importing %s", importID)
+		}
+	}
+	// import blocks with an identity look the provider up in the tenant of the identity
+	d := resourceProvider().Data(&terraform.InstanceState{Identity: map[string]string{"id": "provider-id", "tenant_id": "team-a"}})
+	result, err := resourceImportProvider(context.Background(), d, client)
+	if err != nil || result[0].Id() != "provider-id" || result[0].Get("tenant_id") != "team-a" || client.tenantID != "team-a" {
+		t.Errorf("unexpected import result of the identity: %v, %v", result, err)
+	}
+}
+
+func TestInstallProviderRetry(t *testing.T) {
+	transientRetryTimeout = 10 * time.Second
+	defer func() { transientRetryTimeout = 2 * time.Minute }()
+
+	payload := map[string]interface{}{"provider_id": "test", "provider_name": "test"}
+
+	client := &mockClient{
+		response:        []byte(`{"id": "provider-id"}`),
+		statusCode:      200,
+		installFailures: []int{502, 504},
+	}
+	response, _, err := installProvider(context.Background(), client, payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if response.ID != "provider-id" || client.installs != 3 {
+		t.Errorf("expected provider-id after 3 installs, got %v after %d", response.ID, client.installs)
+	}
+
+	// The first attempt installed the provider before the gateway timed out
+	client = &mockClient{
+		statusCode:      200,
+		installFailures: []int{504, 409},
+		installed: []KeepProvider{
+			{
+				ID:      "installed-id",
+				Type:    "test",
+				Details: ProviderDetails{Name: "test"},
+			},
+		},
+	}
+	response, _, err = installProvider(context.Background(), client, payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if response.ID != "installed-id" {
+		t.Errorf("expected the installed provider to be adopted, got %v", response.ID)
+	}
+
+	client = &mockClient{
+		statusCode:      200,
+		installFailures: []int{412},
+	}
+	if _, _, err := installProvider(context.Background(), client, payload); err == nil || client.installs != 1 {
+		t.Errorf("expected a single failed install, got %v after %d", err, client.installs)
+	}
+}
+
+func TestResourceProvider_MockReadHealth(t *testing.T) {
+	client := &mockClient{
+		statusCode: 200,
+		installed: []KeepProvider{
+			{
+				ID:                "provider-id",
+				Type:              "test",
+				LastAlertReceived: "2024-05-01T12:00:00",
+				InstalledBy:       "admin@example.com",
+				InstallationTime:  "2024-04-01T08:00:00",
+				Scopes: []ProviderScope{
+					{Name: "alerts:read", Mandatory: true},
+					{Name: "webhook:write", MandatoryForWebhook: true},
+				},
+				ValidatedScopes: map[string]interface{}{
+					"alerts:read":   true,
+					"webhook:write": "Permission denied",
+				},
+			},
+		},
+	}
+
+	d := resourceProvider().Data(nil)
+	d.SetId("provider-id")
+
+	if diags := resourceReadProvider(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if d.Get("last_alert_received") != "2024-05-01T12:00:00" ||
+		d.Get("installed_by") != "admin@example.com" || d.Get("installation_time") != "2024-04-01T08:00:00" || d.Get("last_pull_time") != "" {
+		t.Errorf("unexpected health attributes: last_alert_received=%v installed_by=%v installation_time=%v last_pull_time=%v",
+			d.Get("last_alert_received"), d.Get("installed_by"), d.Get("installation_time"), d.Get("last_pull_time"))
+	}
+
+	if d.Get("scopes.#") != 2 || d.Get("scopes.0.name") != "alerts:read" || d.Get("scopes.0.granted") != true ||
+		d.Get("scopes.1.mandatory_for_webhook") != true || d.Get("scopes.1.granted") != false {
+		t.Errorf("unexpected scopes: %v", d.Get("scopes"))
+	}
+}
+
+func TestResourceProvider_MockWebhookEvents(t *testing.T) {
+	client := &mockClient{
+		response:   []byte(`{"id": "provider-id"}`),
+		statusCode: 200,
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceProvider().Schema, map[string]interface{}{
+		"type":            "test",
+		"name":            "test",
+		"auth_config":     map[string]interface{}{"key": "value"},
+		"install_webhook": true,
+		"webhook_events":  []interface{}{"annotations", "alerting"},
+	})
+
+	resourceCreateProvider(context.Background(), d, client)
+
+	if strings.Join(client.webhookEvents, ",") != "alerting,annotations" {
+		t.Errorf("expected the selected webhook events to be installed, got %v", client.webhookEvents)
+	}
+}
+
+func TestSetProviderPulling(t *testing.T) {
+	cases := []struct {
+		mode           string
+		pullingEnabled bool
+		expectedMode   string
+	}{
+		{mode: "push", pullingEnabled: false, expectedMode: "push"},
+		{mode: "push", pullingEnabled: true, expectedMode: "both"},
+		{mode: "pull", pullingEnabled: true, expectedMode: "pull"},
+		{mode: "both", pullingEnabled: false, expectedMode: "push"},
+	}
+
+	for _, tc := range cases {
+		d := schema.TestResourceDataRaw(t, resourceProvider().Schema, map[string]interface{}{
+			"type":        "test",
+			"name":        "test",
+			"auth_config": map[string]interface{}{"key": "value"},
+			"mode":        tc.mode,
+		})
+
+		if diags := setProviderPulling(d, tc.pullingEnabled); diags.HasError() {
+			t.Fatalf("unexpected error: %v", diags)
+		}
+		if d.Get("mode") != tc.expectedMode {
+			t.Errorf("mode %s with pulling %t: expected %s, got %v", tc.mode, tc.pullingEnabled, tc.expectedMode, d.Get("mode"))
+		}
+	}
+
+	providers := []KeepProvider{
+		{
+			Type: "cloudwatch",
+			Config: map[string]ProviderConfigField{
+				"region":     {Required: true},
+				"access_key": {Required: true},
+			},
+		},
+	}
+	if err := validateProviderAuthConfig(providers, "cloudwatch", map[string]interface{}{"region": "eu-west-1"}, true); err != nil {
+		t.Errorf("expected no missing keys in push mode, got %s", err)
+	}
+}
+
+func TestResourceProvider_MockWorkflowReferences(t *testing.T) {
+	client := &mockClient{
+		statusCode: 200,
+		workflows: []Workflow{
+			{ID: "1", Name: "notify", WorkflowRaw: `config: "{{ providers.slack-prod }}"`},
+			{ID: "2", Name: "other", WorkflowRaw: `config: "{{ providers.slack-prod-2 }}"`},
+		},
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceProvider().Schema, map[string]interface{}{
+		"type":                      "slack",
+		"name":                      "slack-prod",
+		"auth_config":               map[string]interface{}{"webhook_url": "https://hooks.slack.com/x"},
+		"check_workflow_references": true,
+	})
+	d.SetId("provider-id")
+
+	diags := resourceDeleteProvider(context.Background(), d, client)
+	if !diags.HasError() {
+		t.Fatal("expected error diagnostics")
+	}
+	if diags[0].Summary != "Provider 'slack-prod' is referenced by 1 workflows: notify (1)" {
+		t.Errorf("unexpected error: %q", diags[0].Summary)
+	}
+
+	client.workflows = client.workflows[1:]
+	if diags := resourceDeleteProvider(context.Background(), d, client); diags.HasError() {
+		t.Errorf("unexpected error: %v", diags)
+	}
+}
+
+func TestValidateAuthConfigURLs(t *testing.T) {
+	diags := validateAuthConfigURLs(map[string]interface{}{
+		"host_url":    "https://grafana.example.com",
+		"webhook_url": "env://WEBHOOK_URL",
+		"url":         "",
+		"token":       "not a url",
+	}, cty.GetAttrPath("auth_config"))
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	diags = validateAuthConfigURLs(map[string]interface{}{
+		"host_url": "grafana.example.com",
+		"url":      "https://",
+	}, cty.GetAttrPath("auth_config"))
+	if len(diags) != 2 {
+		t.Fatalf("expected an error per invalid url, got %v", diags)
+	}
+	if expected := cty.GetAttrPath("auth_config").IndexString("host_url"); !diags[0].AttributePath.Equals(expected) {
+		t.Errorf("expected the error to point at host_url, got %#v", diags[0].AttributePath)
+	}
+}
+
+func TestValidateProviderAuthConfig(t *testing.T) {
+	providers := []KeepProvider{
+		{
+			Type: "grafana",
+			Config: map[string]ProviderConfigField{
+				"host":  {Required: true},
+				"token": {Required: true, Sensitive: true},
+				"org":   {Required: false},
+			},
+		},
+		{
+			Type: "console",
+		},
+	}
+
+	cases := []struct {
+		name          string
+		providerType  string
+		authConfig    map[string]interface{}
+		expectedError string
+	}{
+		{
+			name:         "valid",
+			providerType: "grafana",
+			authConfig:   map[string]interface{}{"host": "https://grafana.example.com", "token": "token"},
+		},
+		{
+			name:          "missing required key",
+			providerType:  "grafana",
+			authConfig:    map[string]interface{}{"host": "https://grafana.example.com"},
+			expectedError: "missing required keys [token]",
+		},
+		{
+			name:          "unknown key",
+			providerType:  "grafana",
+			authConfig:    map[string]interface{}{"host": "https://grafana.example.com", "token": "token", "tokn": "token"},
+			expectedError: "unknown keys [tokn]",
+		},
+		{
+			name:         "no config schema",
+			providerType: "console",
+			authConfig:   map[string]interface{}{"anything": "value"},
+		},
+		{
+			name:          "unknown type",
+			providerType:  "unknown",
+			authConfig:    map[string]interface{}{},
+			expectedError: "Provider type 'unknown' not found",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateProviderAuthConfig(providers, tc.providerType, tc.authConfig, false)
+			if tc.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Errorf("expected error containing %q, got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestMergeMaskedAuthConfig(t *testing.T) {
+	current := map[string]interface{}{
+		"host":    "https://grafana.example.com",
+		"token":   "glsa_secret",
+		"api_key": "sk-secret",
+	}
+	remote := map[string]interface{}{
+		"host":    "https://grafana-new.example.com",
+		"token":   "**********",
+		"api_key": "sk-***",
+		"org":     "main",
+		"secret":  "********",
+	}
+
+	expected := map[string]interface{}{
+		"host":    "https://grafana-new.example.com",
+		"token":   "glsa_secret",
+		"api_key": "sk-secret",
+		"org":     "main",
+	}
+
+	actual := mergeMaskedAuthConfig(current, remote, false)
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+	for key, value := range expected {
+		if actual[key] != value {
+			t.Errorf("expected %s=%v, got %v", key, value, actual[key])
+		}
+	}
+}
+
+func TestDecodeWriteOnlyAuthConfig(t *testing.T) {
+	authConfig, err := decodeWriteOnlyAuthConfig(`{"host": "https://grafana.example.com", "token": "glsa_secret", "port": 3000, "verify": true}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"host":   "https://grafana.example.com",
+		"token":  "glsa_secret",
+		"port":   "3000",
+		"verify": "true",
+	}
+	if len(authConfig) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, authConfig)
+	}
+	for key, value := range expected {
+		if authConfig[key] != value {
+			t.Errorf("expected %s=%v, got %v", key, value, authConfig[key])
+		}
+	}
+
+	if _, err := decodeWriteOnlyAuthConfig(`["token"]`); err == nil {
+		t.Error("expected error for a JSON array")
+	}
+}
+
+func TestTypedProviderConfig(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceProvider().Schema, map[string]interface{}{
+		"type": "prometheus",
+		"name": "test",
+		"prometheus": []interface{}{
+			map[string]interface{}{
+				"url":      "https://prometheus.example.com",
+				"password": "secret",
+			},
+		},
+	})
+
+	authConfig, err := providerAuthConfig(d)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"url":      "https://prometheus.example.com",
+		"password": "secret",
+		"verify":   true,
+	}
+	if len(authConfig) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, authConfig)
+	}
+	for key, value := range expected {
+		if authConfig[key] != value {
+			t.Errorf("expected %s=%v, got %v", key, value, authConfig[key])
+		}
+	}
+
+	block := typedProviderConfigFromRemote("prometheus", d.Get("prometheus.0").(map[string]interface{}), map[string]interface{}{
+		"url":      "https://prometheus-new.example.com",
+		"password": "******",
+		"verify":   "false",
+	}, false)
+	if block["url"] != "https://prometheus-new.example.com" || block["password"] != "secret" || block["verify"] != false || block["username"] != "" {
+		t.Errorf("unexpected block from remote: %v", block)
+	}
+}
+
+func TestResolveAuthConfig(t *testing.T) {
+	t.Setenv("KEEP_TEST_TOKEN", "env-secret")
+
+	file := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(file, []byte("file-secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	resolved, err := resolveAuthConfig(map[string]interface{}{
+		"host":     "https://grafana.example.com",
+		"token":    "env://KEEP_TEST_TOKEN",
+		"password": "file://" + file,
+		"verify":   true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"host":     "https://grafana.example.com",
+		"token":    "env-secret",
+		"password": "file-secret",
+		"verify":   true,
+	}
+	for key, value := range expected {
+		if resolved[key] != value {
+			t.Errorf("expected %s=%v, got %v", key, value, resolved[key])
+		}
+	}
+
+	if _, err := resolveAuthConfig(map[string]interface{}{"token": "env://KEEP_TEST_UNSET"}); err == nil {
+		t.Error("expected error for an unset environment variable")
+	}
+
+	merged := mergeMaskedAuthConfig(
+		map[string]interface{}{"token": "env://KEEP_TEST_TOKEN", "password": "env://KEEP_TEST_UNSET", "key": "env://KEEP_TEST_TOKEN"},
+		map[string]interface{}{"token": "env-secret", "password": "unknown", "key": "rotated"},
+		false,
+	)
+	if merged["token"] != "env://KEEP_TEST_TOKEN" || merged["password"] != "env://KEEP_TEST_UNSET" || merged["key"] != "rotated" {
+		t.Errorf("unexpected merge of credential references: %v", merged)
+	}
+}
+
+func TestMergeMaskedAuthConfigDrift(t *testing.T) {
+	current := map[string]interface{}{
+		"token":   "glsa_secret",
+		"api_key": "sk-1secret",
+		"old_key": "sk-1secret",
+	}
+	remote := map[string]interface{}{
+		"token":   "**********",
+		"api_key": "sk-1***",
+		"old_key": "sk-2***",
+	}
+
+	actual := mergeMaskedAuthConfig(current, remote, true)
+	expected := map[string]interface{}{
+		"token":   "glsa_secret",
+		"api_key": "sk-1secret",
+		"old_key": "sk-2***",
+	}
+	for key, value := range expected {
+		if actual[key] != value {
+			t.Errorf("expected %s=%v, got %v", key, value, actual[key])
+		}
+	}
+
+	if actual := mergeMaskedAuthConfig(current, remote, false); actual["old_key"] != "sk-1secret" {
+		t.Errorf("expected drift to be ignored without detectDrift, got %v", actual["old_key"])
+	}
+}
+
+func TestMaskedValueMatches(t *testing.T) {
+	cases := []struct {
+		masked     string
+		known      string
+		matches    bool
+		comparable bool
+	}{
+		{masked: "sk-1***", known: "sk-1secret", matches: true, comparable: true},
+		{masked: "sk-1***", known: "sk-2secret", comparable: true},
+		{masked: "***cret", known: "sk-1secret", matches: true, comparable: true},
+		{masked: "sk-1***cret", known: "sk-1", comparable: true},
+		// fully masked values are unknown instead of matching anything
+		{masked: "**********", known: "glsa_secret"},
+		{masked: "********", known: "env://TF_ACC_KEEP_UNSET_SECRET"},
+	}
+
+	for _, c := range cases {
+		matches, comparable := maskedValueMatches(c.masked, c.known)
+		if matches != c.matches || comparable != c.comparable {
+			t.Errorf("maskedValueMatches(%q, %q) = %v, %v, expected %v, %v", c.masked, c.known, matches, comparable, c.matches, c.comparable)
+		}
+	}
+}
+
+// Mock client for unit tests of keep_provider, methods of other objects are not implemented
 type mockClient struct {
+	KeepClient
+
 	response   []byte
 	statusCode int
+	scopes     map[string]interface{}
+	testError  string
+
+	uninstallStatusCode int
+	// uninstallUnsupported mocks a backend which doesn't list the endpoint for uninstalling webhooks
+	uninstallUnsupported bool
+	webhookUninstalls    int
+	installed            []KeepProvider
+	installFailures      []int
+	installs             int
+	webhookEvents        []string
+	workflows            []Workflow
+	tenantID             string
 }
 
-func (m *mockClient) GetAvailableProviders() ([]interface{}, *ErrorResponse, error) {
-	return []interface{}{
-		map[string]interface{}{
-			"type": "test",
+// mockAPIError returns the error of a request failing with the status code like the client does
+func mockAPIError(statusCode int, details string) (*ErrorResponse, error) {
+	apiErr := &APIError{
+		StatusCode: statusCode,
+		Response: ErrorResponse{
+			Error:   fmt.Sprintf("request failed with status %d", statusCode),
+			Details: details,
+		},
+		message: fmt.Sprintf("API request failed with status %d", statusCode),
+	}
+	return &apiErr.Response, apiErr
+}
+
+func (m *mockClient) GetAvailableProviders(ctx context.Context) ([]KeepProvider, *ErrorResponse, error) {
+	return []KeepProvider{
+		{
+			Type: "test",
+			Config: map[string]ProviderConfigField{
+				"host":  {Required: true},
+				"token": {Required: true, Sensitive: true},
+			},
 		},
 	}, nil, nil
 }
 
-func (m *mockClient) GetInstalledProviders() ([]interface{}, *ErrorResponse, error) {
+func (m *mockClient) GetInstalledProviders(ctx context.Context) ([]KeepProvider, *ErrorResponse, error) {
 	if m.statusCode != http.StatusOK {
-		return nil, &ErrorResponse{
-			Error:   fmt.Sprintf("request failed with status %d", m.statusCode),
-			Details: string(m.response),
-		}, fmt.Errorf("API request failed with status %d", m.statusCode)
+		errResp, err := mockAPIError(m.statusCode, string(m.response))
+		return nil, errResp, err
+	}
+	return append([]KeepProvider{}, m.installed...), nil, nil
+}
+
+func (m *mockClient) GetInstalledProvider(ctx context.Context, providerID string) (*KeepProvider, *ErrorResponse, error) {
+	providers, errResp, err := m.GetInstalledProviders(ctx)
+	if err != nil {
+		return nil, errResp, err
+	}
+	for i := range providers {
+		if string(providers[i].ID) == providerID {
+			return &providers[i], nil, nil
+		}
 	}
-	return []interface{}{}, nil, nil
+	return nil, nil, nil
 }
 
-func (m *mockClient) InstallProvider(providerConfig map[string]interface{}) (map[string]interface{}, *ErrorResponse, error) {
+func (m *mockClient) InstallProvider(ctx context.Context, providerConfig map[string]interface{}) (*KeepProvider, *ErrorResponse, error) {
+	m.installs++
+	if len(m.installFailures) > 0 {
+		statusCode := m.installFailures[0]
+		m.installFailures = m.installFailures[1:]
+		errResp, err := mockAPIError(statusCode, "")
+		return nil, errResp, fmt.Errorf("failed to install provider: %w", err)
+	}
+
 	if m.statusCode != http.StatusOK && m.statusCode != http.StatusCreated {
-		return nil, &ErrorResponse{
-			Error:   fmt.Sprintf("request failed with status %d", m.statusCode),
-			Details: string(m.response),
-		}, fmt.Errorf("API request failed with status %d", m.statusCode)
+		errResp, err := mockAPIError(m.statusCode, string(m.response))
+		return nil, errResp, err
 	}
 
 	if len(m.response) == 0 || string(m.response) == "{}" {
-		return map[string]interface{}{}, nil, nil
+		return &KeepProvider{}, nil, nil
 	}
 
-	var response map[string]interface{}
+	var response KeepProvider
 	if err := json.Unmarshal(m.response, &response); err != nil {
 		return nil, nil, fmt.Errorf("failed to parse response: %v", err)
 	}
+	// installed providers are returned by the backend like after a real install
+	if installed, _, _ := m.GetInstalledProvider(ctx, string(response.ID)); installed == nil {
+		m.installed = append(m.installed, response)
+	}
+
+	return &response, nil, nil
+}
+
+func (m *mockClient) UpdateProvider(ctx context.Context, providerID string, providerConfig map[string]interface{}) (*ErrorResponse, error) {
+	if m.statusCode != http.StatusOK {
+		return mockAPIError(m.statusCode, string(m.response))
+	}
+	return nil, nil
+}
+
+func (m *mockClient) GetWebhookSettings(ctx context.Context) (*WebhookSettings, *ErrorResponse, error) {
+	return &WebhookSettings{
+		WebhookAPI: "http://localhost:8080/alerts/event",
+		APIKey:     "webhook-api-key",
+	}, nil, nil
+}
 
-	return response, nil, nil
+func (m *mockClient) ValidateProviderScopes(ctx context.Context, providerID string) (map[string]interface{}, *ErrorResponse, error) {
+	return m.scopes, nil, nil
 }
 
-func (m *mockClient) DeleteProvider(providerType, providerID string) (*ErrorResponse, error) {
+func (m *mockClient) ListWorkflows(ctx context.Context) ([]Workflow, *ErrorResponse, error) {
+	return append([]Workflow{}, m.workflows...), nil, nil
+}
+
+func (m *mockClient) WithTenant(tenantID string) KeepClient {
+	m.tenantID = tenantID
+	return m
+}
+
+func (m *mockClient) TestProvider(ctx context.Context, providerConfig map[string]interface{}) (*ErrorResponse, error) {
+	if m.testError != "" {
+		return mockAPIError(http.StatusBadRequest, m.testError)
+	}
+	return nil, nil
+}
+
+func (m *mockClient) DeleteProvider(ctx context.Context, providerType, providerID string) (*ErrorResponse, error) {
 	if m.statusCode != http.StatusOK {
-		return &ErrorResponse{
-			Error:   fmt.Sprintf("request failed with status %d", m.statusCode),
-			Details: string(m.response),
-		}, fmt.Errorf("API request failed with status %d", m.statusCode)
+		return mockAPIError(m.statusCode, string(m.response))
 	}
 	return nil, nil
 }
 
-func (m *mockClient) InstallProviderWebhook(providerType, providerID string) (*ErrorResponse, error) {
+func (m *mockClient) InstallProviderWebhook(ctx context.Context, providerType, providerID string, events []string) (*ErrorResponse, error) {
+	m.webhookEvents = events
 	if m.statusCode != http.StatusOK {
-		return &ErrorResponse{
-			Error:   fmt.Sprintf("request failed with status %d", m.statusCode),
-			Details: string(m.response),
-		}, fmt.Errorf("API request failed with status %d", m.statusCode)
+		return mockAPIError(m.statusCode, string(m.response))
 	}
 	return nil, nil
 }
+
+func (m *mockClient) UninstallProviderWebhook(ctx context.Context, providerType, providerID string) (*ErrorResponse, error) {
+	if m.uninstallUnsupported {
+		return nil, errWebhookUninstallUnsupported
+	}
+	m.webhookUninstalls++
+	if m.uninstallStatusCode != 0 && m.uninstallStatusCode != http.StatusOK {
+		return mockAPIError(m.uninstallStatusCode, string(m.response))
+	}
+	return nil, nil
+}
+
+func TestResourceProvider_MockBackend(t *testing.T) {
+	backend := newMockBackend(t)
+	client := backend.client()
+	r := resourceProvider()
+
+	config := map[string]interface{}{
+		"type":            "test",
+		"name":            "test-provider",
+		"auth_config":     map[string]interface{}{"host": "https://example.com", "token": "secret"},
+		"install_webhook": true,
+	}
+
+	state, diags := applyMockResource(t, r, nil, config, client)
+	if diags.HasError() {
+		t.Fatalf("unexpected error on create: %v", diags)
+	}
+	provider, ok := backend.providers[state.ID]
+	if !ok || provider.Details.Name != "test-provider" || provider.Details.Authentication["host"] != "https://example.com" {
+		t.Fatalf("unexpected provider in the backend: %+v", backend.providers)
+	}
+	if !backend.webhooks[state.ID] {
+		t.Error("expected the webhook to be installed")
+	}
+
+	// the masked secret of the backend doesn't replace the configured one
+	if state, diags = refreshMockResource(t, r, state, client); diags.HasError() || state == nil {
+		t.Fatalf("unexpected refresh result: %v, %v", state, diags)
+	}
+	if state.Attributes["auth_config.token"] != "secret" || state.Attributes["webhook_api_key"] != "webhook-api-key" {
+		t.Errorf("unexpected state after refresh: %v", state.Attributes)
+	}
+
+	config["auth_config"] = map[string]interface{}{"host": "https://example.org", "token": "secret"}
+	if state, diags = applyMockResource(t, r, state, config, client); diags.HasError() {
+		t.Fatalf("unexpected error on update: %v", diags)
+	}
+	if backend.providers[state.ID].Details.Authentication["host"] != "https://example.org" {
+		t.Errorf("expected the auth config to be updated, got %+v", backend.providers[state.ID])
+	}
+
+	id := state.ID
+	if state, diags = applyMockResource(t, r, state, nil, client); diags.HasError() || state != nil {
+		t.Fatalf("unexpected destroy result: %v, %v", state, diags)
+	}
+	if len(backend.providers) != 0 || backend.webhooks[id] {
+		t.Errorf("expected the provider and its webhook to be deleted, got %+v", backend.providers)
+	}
+}
+
+func TestResourceProvider_MockWebhookRetry(t *testing.T) {
+	installWebhook := "POST /providers/install/webhook/{provider_type}/{provider_id}"
+	backend := newMockBackend(t, installWebhook)
+	client := backend.client()
+	r := resourceProvider()
+
+	config := map[string]interface{}{
+		"type":            "test",
+		"name":            "test-provider",
+		"auth_config":     map[string]interface{}{"host": "https://example.com", "token": "secret"},
+		"install_webhook": true,
+	}
+
+	// the installed provider is kept in state, so it isn't replaced on the next apply
+	state, diags := applyMockResource(t, r, nil, config, client)
+	if diags.HasError() || state == nil {
+		t.Fatalf("unexpected create result: %v, %v", state, diags)
+	}
+	if len(diags) != 1 || diags[0].Severity != diag.Warning || !strings.Contains(diags[0].Detail, "retried on the next apply") {
+		t.Errorf("expected a warning for the failed webhook installation, got %v", diags)
+	}
+	if state.Attributes["webhook_status"] != webhookStatusFailed {
+		t.Errorf("expected webhook_status failed, got %q", state.Attributes["webhook_status"])
+	}
+
+	delete(backend.disabled, installWebhook)
+	id := state.ID
+	if state, diags = applyMockResource(t, r, state, config, client); diags.HasError() {
+		t.Fatalf("unexpected error on update: %v", diags)
+	}
+	if state.ID != id || !backend.webhooks[id] || state.Attributes["webhook_status"] != webhookStatusInstalled {
+		t.Errorf("expected the webhook to be installed on the next apply, got %v", state.Attributes)
+	}
+
+	// an installed webhook plans no changes
+	if _, diags = applyMockResource(t, r, state, config, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if count := backend.requestCount("POST", "/providers/install/webhook/test/"+id); count != 2 {
+		t.Errorf("expected only the failed installation and its retry, got %d installations", count)
+	}
+}
+
+func TestWaitUntilVisible(t *testing.T) {
+	ctx := context.Background()
+
+	calls := 0
+	err := waitUntilVisible(ctx, &mockClient{}, "provider", "provider-id", func() (bool, error) {
+		calls++
+		if calls == 1 {
+			return false, fmt.Errorf("gateway: %w", ErrTransient)
+		}
+		return calls == 3, nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("expected to poll until the provider is visible, got %d calls and error %v", calls, err)
+	}
+
+	err = waitUntilVisible(ctx, &Client{ReadinessTimeout: time.Second}, "mapping", "1", func() (bool, error) {
+		return false, nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "mapping 1 was created, but is not returned by the backend after 1s") {
+		t.Errorf("expected a timeout error naming readiness_timeout, got %v", err)
+	}
+
+	err = waitUntilVisible(ctx, &mockClient{}, "mapping", "1", func() (bool, error) {
+		return false, ErrUnauthorized
+	})
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected failed requests to end the wait, got %v", err)
+	}
+}
+
+func TestWaitUntilVisible_ListCache(t *testing.T) {
+	ctx := context.Background()
+	backend := newMockBackend(t)
+	client := backend.client()
+	client.listCache = newListCache(defaultListCacheTTL)
+	client.ReadinessTimeout = 5 * time.Second
+
+	// the list is cached before the provider shows up
+	if _, _, err := client.GetInstalledProviders(ctx); err != nil {
+		t.Fatal(err)
+	}
+	backend.mu.Lock()
+	backend.providers["1"] = KeepProvider{ID: "1", Type: "test"}
+	backend.mu.Unlock()
+
+	start := time.Now()
+	err := waitUntilVisible(ctx, client, "provider", "1", func() (bool, error) {
+		provider, _, err := client.GetInstalledProvider(ctx, "1")
+		return provider != nil, err
+	})
+	if err != nil {
+		t.Fatalf("expected the provider to be visible despite the cached list, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the first poll to find the provider, waited %s", elapsed)
+	}
+}
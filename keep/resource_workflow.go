@@ -2,11 +2,15 @@ package keep
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/spf13/cast"
 	"gopkg.in/yaml.v2"
 )
 
@@ -18,16 +22,26 @@ func resourceWorkflow() *schema.Resource {
 
 	schemaMap := map[string]*schema.Schema{
 		"workflow_file_path": {
-			Type:         schema.TypeString,
-			Optional:     true,
-			ExactlyOneOf: []string{"file", "workflow_file_path"},
-			Description:  "Path of the workflow file (deprecated, use 'file' instead)",
+			Type:             schema.TypeString,
+			Optional:         true,
+			ExactlyOneOf:     []string{"content", "file", "workflow_file_path"},
+			Deprecated:       "use file instead",
+			DiffSuppressFunc: suppressMovedWorkflowFile("file"),
+			Description:      "Path of the workflow file (deprecated, use 'file' instead)",
 		},
 		"file": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			ExactlyOneOf:     []string{"content", "file", "workflow_file_path"},
+			DiffSuppressFunc: suppressMovedWorkflowFile("workflow_file_path"),
+			Description:      "Path of the workflow file",
+		},
+		"content": {
 			Type:         schema.TypeString,
 			Optional:     true,
-			ExactlyOneOf: []string{"file", "workflow_file_path"},
-			Description:  "Path of the workflow file",
+			ExactlyOneOf: []string{"content", "file", "workflow_file_path"},
+			Description: "YAML of the workflow instead of a file. Imports set it to the workflow of the backend, " +
+				"so terraform plan -generate-config-out generates a configuration which keeps the workflow",
 		},
 		"name": {
 			Type:     schema.TypeString,
@@ -41,6 +55,49 @@ func resourceWorkflow() *schema.Resource {
 			Type:     schema.TypeInt,
 			Computed: true,
 		},
+		"disabled": {
+			Type:        schema.TypeBool,
+			Computed:    true,
+			Description: "Whether the workflow is disabled. Changes in the UI are planned as an update restoring the workflow file",
+		},
+		"interval": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "Interval in seconds of the interval trigger of the workflow, 0 without interval trigger. Changes in the UI are planned as an update restoring the workflow file",
+		},
+		"secrets_wo": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			WriteOnly:    true,
+			Sensitive:    true,
+			ValidateFunc: validation.StringIsJSON,
+			RequiredWith: []string{"secrets_wo_version"},
+			Description: "Write-only secrets of the workflow as JSON object, e.g. `jsonencode({ token = var.token })`. " +
+				"They are never stored in state, change secrets_wo_version to apply a new value",
+		},
+		"secrets_wo_version": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ValidateFunc: validation.IntAtLeast(1),
+			RequiredWith: []string{"secrets_wo"},
+			Description:  "Version of secrets_wo, changing it writes the current secrets_wo to the workflow",
+		},
+		"secret_names": {
+			Type:        schema.TypeSet,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Set:         schema.HashString,
+			Description: "Names of the secrets written from secrets_wo, secrets removed from it are deleted",
+		},
+		"enrichment_attributes": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+			Set:      schema.HashString,
+			Description: "Attributes which mappings and extractions add to alerts, e.g. `keep_mapping.example.enriched_attributes`. " +
+				"If set, plans warn about references of the workflow to attributes of the alert which are neither in it nor attributes of every alert",
+		},
+		"deletion_protection": deletionProtectionSchema("workflow"),
 	}
 
 	// Add hash field to schema
@@ -52,17 +109,182 @@ func resourceWorkflow() *schema.Resource {
 		UpdateContext: resourceUpdateWorkflow,
 		DeleteContext: resourceDeleteWorkflow,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceImportWorkflow,
+		},
+		Identity:                       idIdentity(),
+		ValidateRawResourceConfigFuncs: []schema.ValidateRawResourceConfigFunc{validateWorkflowEnrichmentReferences},
+		// the backend returns the id of another workflow if the upload replaced it, e.g. because its name changed
+		ResourceBehavior: schema.ResourceBehavior{MutableIdentity: true},
+		SchemaVersion:    1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Type:    resourceWorkflowV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceWorkflowStateUpgradeV0,
+				Version: 0,
+			},
 		},
 		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
-			workflowFilePath := getWorkflowFilePath(d)
-			hasher.FilePath = workflowFilePath
-			return hasher.CustomizeDiff(ctx, d)
+			hasher.FilePath, hasher.Content = getWorkflowFilePath(d), nil
+			if content, ok := d.GetOk("content"); ok {
+				hasher.Content = []byte(content.(string))
+			}
+			if err := hasher.CustomizeDiff(ctx, d); err != nil {
+				return err
+			}
+			if d.HasChange("secrets_wo_version") {
+				if err := d.SetNewComputed("secret_names"); err != nil {
+					return err
+				}
+			}
+
+			content := hasher.Content
+			if content == nil && hasher.FilePath != "" {
+				content, _ = os.ReadFile(hasher.FilePath)
+			}
+			return customizeDiffWorkflowDrift(d, content)
 		},
 		Schema: schemaMap,
 	}
 }
 
+// resourceWorkflowV0 is the schema of keep_workflow before the path of the deprecated workflow_file_path moved to file
+func resourceWorkflowV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"workflow_file_path": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"file": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"revision": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"workflow_content_hash": {
+				Type:     schema.TypeString,
+				Computed: true,
+				ForceNew: true,
+			},
+		},
+	}
+}
+
+// resourceWorkflowStateUpgradeV0 moves the path of the deprecated workflow_file_path to file. Configurations
+// still using workflow_file_path don't change, since the diff of the moved path is suppressed.
+func resourceWorkflowStateUpgradeV0(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	if rawState == nil {
+		return rawState, nil
+	}
+
+	if path, ok := rawState["workflow_file_path"].(string); ok && path != "" {
+		if file, _ := rawState["file"].(string); file == "" {
+			rawState["file"] = path
+			rawState["workflow_file_path"] = nil
+		}
+	}
+
+	return rawState, nil
+}
+
+// suppressMovedWorkflowFile suppresses the diff of a path moving between file and the other attribute, e.g. when
+// a configuration replaces the deprecated workflow_file_path by file, since the workflow doesn't change
+func suppressMovedWorkflowFile(other string) schema.SchemaDiffSuppressFunc {
+	return func(k, old, new string, d *schema.ResourceData) bool {
+		// without a diff the new value of an attribute removed from the configuration is its old value
+		otherOld, otherNew := d.GetChange(other)
+		switch {
+		case old == "" && new != "":
+			return otherOld.(string) == new && (otherNew.(string) == "" || otherNew.(string) == new)
+		case old != "" && new == "":
+			return otherNew.(string) == old && (otherOld.(string) == "" || otherOld.(string) == old)
+		}
+		return false
+	}
+}
+
+// workflowFields are the attributes of a workflow which are refreshed from the backend
+type workflowFields struct {
+	Name        string
+	Description string
+	Disabled    bool
+	Interval    int
+}
+
+// parseWorkflowFields returns the attributes of a workflow in YAML or JSON, e.g. of a workflow file
+// or the raw workflow of the backend
+func parseWorkflowFields(content []byte) (workflowFields, error) {
+	var workflowWrapper struct {
+		Workflow struct {
+			Name        string      `yaml:"name"`
+			Description string      `yaml:"description"`
+			Disabled    interface{} `yaml:"disabled"`
+			Triggers    []struct {
+				Type  string      `yaml:"type"`
+				Value interface{} `yaml:"value"`
+			} `yaml:"triggers"`
+		} `yaml:"workflow"`
+	}
+	if err := yaml.Unmarshal(content, &workflowWrapper); err != nil {
+		return workflowFields{}, err
+	}
+
+	workflow := workflowWrapper.Workflow
+	fields := workflowFields{
+		Name:        workflow.Name,
+		Description: workflow.Description,
+		Disabled:    cast.ToBool(workflow.Disabled),
+	}
+	for _, trigger := range workflow.Triggers {
+		if trigger.Type == "interval" {
+			fields.Interval = cast.ToInt(trigger.Value)
+		}
+	}
+	return fields, nil
+}
+
+// customizeDiffWorkflowDrift plans an update if the workflow was changed outside of terraform, e.g. disabled
+// in the UI, so the update restores the workflow file or content. Changes of the workflow itself replace it.
+func customizeDiffWorkflowDrift(d *schema.ResourceDiff, content []byte) error {
+	if d.Id() == "" || content == nil || d.HasChange("workflow_content_hash") {
+		return nil
+	}
+
+	fields, err := parseWorkflowFields(content)
+	if err != nil {
+		return nil
+	}
+
+	drifted := false
+	for attribute, value := range map[string]interface{}{
+		"name":        fields.Name,
+		"description": fields.Description,
+		"disabled":    fields.Disabled,
+		"interval":    fields.Interval,
+	} {
+		if d.Get(attribute) != value {
+			if err := d.SetNew(attribute, value); err != nil {
+				return err
+			}
+			drifted = true
+		}
+	}
+	if drifted {
+		return d.SetNewComputed("revision")
+	}
+	return nil
+}
+
 func validateWorkflowFile(filePath string) error {
 	content, err := os.ReadFile(filePath)
 	if err != nil {
@@ -114,46 +336,125 @@ func getWorkflowFilePath(d interface{}) string {
 	return getter.Get("workflow_file_path").(string)
 }
 
-func resourceCreateWorkflow(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Client)
-	workflowFilePath := getWorkflowFilePath(d)
-	if workflowFilePath == "" {
-		return diag.Errorf("either file or workflow_file_path is required for creation")
+// workflowFileAttributePath returns the path of the attribute the workflow is configured with
+func workflowFileAttributePath(d *schema.ResourceData) cty.Path {
+	if _, ok := d.GetOk("content"); ok {
+		return cty.GetAttrPath("content")
 	}
+	if _, ok := d.GetOk("file"); ok {
+		return cty.GetAttrPath("file")
+	}
+	return cty.GetAttrPath("workflow_file_path")
+}
 
+// findWorkflowIDsByName returns the ids of all workflows with the given name
+func findWorkflowIDsByName(ctx context.Context, client KeepClient, name string) ([]string, error) {
+	workflows, errResp, err := client.ListWorkflows(ctx)
+	if err != nil {
+		if errResp != nil {
+			return nil, fmt.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
+		}
+		return nil, fmt.Errorf("error reading workflows: %s", err)
+	}
+
+	ids := make([]string, 0)
+	for _, workflow := range workflows {
+		if workflow.Name == name {
+			ids = append(ids, string(workflow.ID))
+		}
+	}
+
+	return ids, nil
+}
+
+// resourceImportWorkflow supports importing by ID or by name using the "name=<workflow-name>" syntax
+func resourceImportWorkflow(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	importID, err := importIDFromIdentity(d)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := resolveImportID(importID, "workflow", func(name string) ([]string, error) {
+		return findWorkflowIDsByName(ctx, m.(KeepClient), name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	d.SetId(id)
+
+	// the workflow of the backend becomes the content, since the workflow file is not known
+	response, errResp, err := m.(KeepClient).GetWorkflow(ctx, id)
+	if err != nil {
+		if errResp != nil {
+			return nil, fmt.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
+		}
+		return nil, fmt.Errorf("error reading workflow: %s", err)
+	}
+	if response.WorkflowRaw != "" {
+		if err := setAttributes(d, map[string]interface{}{
+			"content":               response.WorkflowRaw,
+			"workflow_content_hash": calculateContentHash([]byte(response.WorkflowRaw)),
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// readWorkflowContent returns the workflow of content or the workflow file and sets its hash
+func readWorkflowContent(d *schema.ResourceData) ([]byte, diag.Diagnostics) {
 	hasher := &FileHasher{
-		FilePath:  workflowFilePath,
+		FilePath:  getWorkflowFilePath(d),
 		HashField: "workflow_content_hash",
 	}
+	if content, ok := d.GetOk("content"); ok {
+		hasher.Content = []byte(content.(string))
+	} else if hasher.FilePath == "" {
+		return nil, diag.Errorf("one of content, file or workflow_file_path is required")
+	}
 	if err := hasher.SetFileHash(d); err != nil {
-		return diag.FromErr(err)
+		return nil, diag.FromErr(err)
+	}
+	if hasher.Content != nil {
+		return hasher.Content, nil
 	}
 
-	content, err := os.ReadFile(workflowFilePath)
+	content, err := os.ReadFile(hasher.FilePath)
 	if err != nil {
-		return diag.FromErr(err)
+		return nil, attributeErrorf(workflowFileAttributePath(d), "%s", err)
+	}
+	return content, nil
+}
+
+func resourceCreateWorkflow(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(KeepClient)
+	content, diags := readWorkflowContent(d)
+	if diags.HasError() {
+		return diags
 	}
 
 	var workflowWrapper map[string]interface{}
 	if err := yaml.Unmarshal(content, &workflowWrapper); err != nil {
-		return diag.Errorf("invalid workflow YAML: %s", err)
+		return attributeErrorf(workflowFileAttributePath(d), "invalid workflow YAML: %s", err)
 	}
 
 	// Validate workflow name
 	if workflow, ok := workflowWrapper["workflow"].(map[interface{}]interface{}); ok {
 		if name, ok := workflow["name"].(string); !ok || name == "" {
-			return diag.Errorf("workflow name is required")
+			return attributeErrorf(workflowFileAttributePath(d), "workflow name is required")
 		}
 	} else {
-		return diag.Errorf("invalid workflow structure")
+		return attributeErrorf(workflowFileAttributePath(d), "invalid workflow structure")
 	}
 
 	workflowData, err := yamlToJSONMap(content)
 	if err != nil {
-		return diag.Errorf("invalid workflow YAML: %s", err)
+		return attributeErrorf(workflowFileAttributePath(d), "invalid workflow YAML: %s", err)
 	}
 
-	response, errResp, err := client.CreateWorkflowJSON(workflowData)
+	response, errResp, err := client.CreateWorkflowJSON(ctx, workflowData)
 	if err != nil {
 		if errResp != nil {
 			return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
@@ -161,18 +462,10 @@ func resourceCreateWorkflow(ctx context.Context, d *schema.ResourceData, m inter
 		return diag.Errorf("error creating workflow: %s", err)
 	}
 
-	if id, ok := response["workflow_id"].(string); ok && id != "" {
+	if id := string(response.WorkflowID); id != "" {
 		d.SetId(id)
-		if workflow, ok := workflowWrapper["workflow"].(map[interface{}]interface{}); ok {
-			if name, ok := workflow["name"].(string); ok {
-				d.Set("name", name)
-			}
-			if desc, ok := workflow["description"].(string); ok {
-				d.Set("description", desc)
-			}
-		}
-		if revision, ok := response["revision"].(float64); ok {
-			d.Set("revision", int(revision))
+		if diags := writeWorkflowSecrets(ctx, d, client); diags.HasError() {
+			return diags
 		}
 		return resourceReadWorkflow(ctx, d, m)
 	}
@@ -180,10 +473,15 @@ func resourceCreateWorkflow(ctx context.Context, d *schema.ResourceData, m inter
 }
 
 func resourceDeleteWorkflow(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Client)
+	if diags := checkDeletionProtection(d, "workflow"); diags.HasError() {
+		return diags
+	}
 
-	errResp, err := client.DeleteWorkflow(d.Id())
-	if err != nil {
+	client := m.(KeepClient)
+
+	errResp, err := client.DeleteWorkflow(ctx, d.Id())
+	// workflows deleted outside of terraform are already gone
+	if err != nil && !isNotFound(err) {
 		if errResp != nil {
 			return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
 		}
@@ -194,42 +492,43 @@ func resourceDeleteWorkflow(ctx context.Context, d *schema.ResourceData, m inter
 }
 
 func resourceUpdateWorkflow(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Client)
-	workflowFilePath := getWorkflowFilePath(d)
+	client := m.(KeepClient)
 
-	hasher := &FileHasher{
-		FilePath:  workflowFilePath,
-		HashField: "workflow_content_hash",
+	if d.HasChange("secrets_wo_version") {
+		if diags := writeWorkflowSecrets(ctx, d, client); diags.HasError() {
+			return diags
+		}
 	}
-	if err := hasher.SetFileHash(d); err != nil {
-		return diag.FromErr(err)
+	// a new revision of the workflow is only uploaded if the workflow changed
+	if !d.HasChangesExcept("secrets_wo_version", "secret_names", "deletion_protection") {
+		return resourceReadWorkflow(ctx, d, m)
 	}
 
-	content, err := os.ReadFile(workflowFilePath)
-	if err != nil {
-		return diag.FromErr(err)
+	content, diags := readWorkflowContent(d)
+	if diags.HasError() {
+		return diags
 	}
 
 	var workflowWrapper map[string]interface{}
 	if err := yaml.Unmarshal(content, &workflowWrapper); err != nil {
-		return diag.Errorf("invalid workflow YAML: %s", err)
+		return attributeErrorf(workflowFileAttributePath(d), "invalid workflow YAML: %s", err)
 	}
 
 	// Validate workflow name
 	if workflow, ok := workflowWrapper["workflow"].(map[interface{}]interface{}); ok {
 		if name, ok := workflow["name"].(string); !ok || name == "" {
-			return diag.Errorf("workflow name is required")
+			return attributeErrorf(workflowFileAttributePath(d), "workflow name is required")
 		}
 	} else {
-		return diag.Errorf("invalid workflow structure")
+		return attributeErrorf(workflowFileAttributePath(d), "invalid workflow structure")
 	}
 
 	workflowData, err := yamlToJSONMap(content)
 	if err != nil {
-		return diag.Errorf("invalid workflow YAML: %s", err)
+		return attributeErrorf(workflowFileAttributePath(d), "invalid workflow YAML: %s", err)
 	}
 
-	response, errResp, err := client.CreateWorkflowJSON(workflowData)
+	response, errResp, err := client.CreateWorkflowJSON(ctx, workflowData)
 	if err != nil {
 		if errResp != nil {
 			return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
@@ -237,60 +536,119 @@ func resourceUpdateWorkflow(ctx context.Context, d *schema.ResourceData, m inter
 		return diag.Errorf("error updating workflow: %s", err)
 	}
 
-	if id, ok := response["workflow_id"].(string); ok && id != "" {
+	if id := string(response.WorkflowID); id != "" {
 		d.SetId(id)
-		if workflow, ok := workflowWrapper["workflow"].(map[interface{}]interface{}); ok {
-			if name, ok := workflow["name"].(string); ok {
-				d.Set("name", name)
-			}
-			if desc, ok := workflow["description"].(string); ok {
-				d.Set("description", desc)
+		return resourceReadWorkflow(ctx, d, m)
+	}
+	return diag.Errorf("workflow ID not found in response")
+}
+
+// decodeWorkflowSecrets decodes the JSON object of secrets_wo, values are converted to strings like in auth_config_wo
+func decodeWorkflowSecrets(value string) (map[string]string, error) {
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		return nil, fmt.Errorf("invalid secrets_wo: must be a JSON object: %s", err)
+	}
+
+	secrets := make(map[string]string, len(decoded))
+	for name, secret := range decoded {
+		if s, ok := secret.(string); ok {
+			secrets[name] = s
+			continue
+		}
+		encoded, _ := json.Marshal(secret)
+		secrets[name] = string(encoded)
+	}
+	return secrets, nil
+}
+
+// writeWorkflowSecrets writes secrets_wo to the workflow and deletes the secrets which were removed from it.
+// The write-only value is only available in the configuration during create and update.
+func writeWorkflowSecrets(ctx context.Context, d *schema.ResourceData, client KeepClient) diag.Diagnostics {
+	rawConfig := d.GetRawConfig()
+	if rawConfig.IsNull() || !rawConfig.IsKnown() {
+		return nil
+	}
+
+	secrets := make(map[string]string)
+	if wo := rawConfig.GetAttr("secrets_wo"); !wo.IsNull() && wo.IsKnown() {
+		var err error
+		if secrets, err = decodeWorkflowSecrets(wo.AsString()); err != nil {
+			return attributeErrorf(cty.GetAttrPath("secrets_wo"), "%s", err)
+		}
+	}
+
+	if len(secrets) > 0 {
+		errResp, err := client.WriteWorkflowSecrets(ctx, d.Id(), secrets)
+		if err != nil {
+			if errResp != nil {
+				return diag.Errorf("API Error writing workflow secrets: %s. Details: %s", errResp.Error, errResp.Details)
 			}
+			return diag.Errorf("error writing workflow secrets: %s", err)
 		}
-		if revision, ok := response["revision"].(float64); ok {
-			d.Set("revision", int(revision))
+	}
+
+	previous, _ := d.GetChange("secret_names")
+	for _, name := range previous.(*schema.Set).List() {
+		if _, ok := secrets[name.(string)]; ok {
+			continue
+		}
+		errResp, err := client.DeleteWorkflowSecret(ctx, d.Id(), name.(string))
+		if err != nil && !isNotFound(err) {
+			if errResp != nil {
+				return diag.Errorf("API Error deleting workflow secret %s: %s. Details: %s", name, errResp.Error, errResp.Details)
+			}
+			return diag.Errorf("error deleting workflow secret %s: %s", name, err)
 		}
-		return resourceReadWorkflow(ctx, d, m)
 	}
-	return diag.Errorf("workflow ID not found in response")
+
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	if err := d.Set("secret_names", names); err != nil {
+		return diag.Errorf("Failed to set secret_names: %s", err.Error())
+	}
+	return nil
 }
 
 func resourceReadWorkflow(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	client := m.(*Client)
+	client := m.(KeepClient)
 
-	response, errResp, err := client.GetWorkflow(d.Id())
+	response, errResp, err := client.GetWorkflow(ctx, d.Id())
 	if err != nil {
+		// only workflows deleted outside of terraform are removed from the state, not those which failed to be read
+		if isNotFound(err) {
+			d.SetId("")
+			return nil
+		}
 		if errResp != nil {
 			return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
 		}
-		d.SetId("")
-		return nil
+		return diag.Errorf("error reading workflow: %s", err)
 	}
 
-	if id, ok := response["id"].(string); ok && id != "" {
+	if id := string(response.ID); id != "" {
 		d.SetId(id)
-		if raw, ok := response["workflow_raw"].(string); ok && raw != "" {
-			var workflowWrapper struct {
-				Workflow struct {
-					Name        string `yaml:"name"`
-					Description string `yaml:"description"`
-					Actions     []struct {
-						Name     string `yaml:"name"`
-						Provider struct {
-							Type string `yaml:"type"`
-						} `yaml:"provider"`
-					} `yaml:"actions"`
-				} `yaml:"workflow"`
-			}
-			if err := yaml.Unmarshal([]byte(raw), &workflowWrapper); err == nil {
-				d.Set("name", workflowWrapper.Workflow.Name)
-				d.Set("description", workflowWrapper.Workflow.Description)
+		fields := workflowFields{Name: response.Name, Description: response.Description, Interval: response.Interval}
+		if raw := response.WorkflowRaw; raw != "" {
+			if parsed, err := parseWorkflowFields([]byte(raw)); err == nil {
+				fields.Name, fields.Description, fields.Disabled = parsed.Name, parsed.Description, parsed.Disabled
 			}
 		}
-		if revision, ok := response["revision"].(float64); ok {
-			d.Set("revision", int(revision))
+		values := map[string]interface{}{
+			"name":        fields.Name,
+			"description": fields.Description,
+			"disabled":    fields.Disabled,
+			"interval":    fields.Interval,
 		}
-		return nil
+		if response.Revision != 0 {
+			values["revision"] = response.Revision
+		}
+		if err := setAttributes(d, values); err != nil {
+			return diag.FromErr(err)
+		}
+		return setIdentity(d, nil)
 	}
 
 	d.SetId("")
@@ -1,12 +1,18 @@
 package keep
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -19,7 +25,7 @@ resource "keep_workflow" "test" {
 
 func TestAccKeepWorkflow_Change(t *testing.T) {
 	workflowContent := `workflow:
-  name: on-field-change
+  name: tf-acc-on-field-change
   description: demonstrates how to trigger a workflow when a field changes
   triggers:
     - type: alert
@@ -53,7 +59,7 @@ func TestAccKeepWorkflow_Change(t *testing.T) {
 					testAccWorkflowConfig(tmpfilePath),
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckWorkflowExists("keep_workflow.test"),
-					resource.TestCheckResourceAttr("keep_workflow.test", "name", "on-field-change"),
+					resource.TestCheckResourceAttr("keep_workflow.test", "name", "tf-acc-on-field-change"),
 					resource.TestCheckResourceAttr("keep_workflow.test", "description", "demonstrates how to trigger a workflow when a field changes"),
 				),
 			},
@@ -61,7 +67,7 @@ func TestAccKeepWorkflow_Change(t *testing.T) {
 				ResourceName:            "keep_workflow.test",
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"workflow_file_path", "workflow_content_hash"},
+				ImportStateVerifyIgnore: []string{"content", "workflow_file_path", "workflow_content_hash"},
 			},
 		},
 	})
@@ -69,7 +75,7 @@ func TestAccKeepWorkflow_Change(t *testing.T) {
 
 func TestAccKeepWorkflow_Discord(t *testing.T) {
 	workflowContent := `workflow:
-  name: discord-example
+  name: tf-acc-discord-example
   description: Discord example
   triggers:
     - type: manual
@@ -109,7 +115,7 @@ func TestAccKeepWorkflow_Discord(t *testing.T) {
 					testAccWorkflowConfig(tmpfilePath),
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckWorkflowExists("keep_workflow.test"),
-					resource.TestCheckResourceAttr("keep_workflow.test", "name", "discord-example"),
+					resource.TestCheckResourceAttr("keep_workflow.test", "name", "tf-acc-discord-example"),
 					resource.TestCheckResourceAttr("keep_workflow.test", "description", "Discord example"),
 				),
 			},
@@ -117,7 +123,7 @@ func TestAccKeepWorkflow_Discord(t *testing.T) {
 				ResourceName:            "keep_workflow.test",
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"workflow_file_path", "workflow_content_hash"},
+				ImportStateVerifyIgnore: []string{"content", "workflow_file_path", "workflow_content_hash"},
 			},
 		},
 	})
@@ -125,7 +131,7 @@ func TestAccKeepWorkflow_Discord(t *testing.T) {
 
 func TestAccKeepWorkflow_Slack(t *testing.T) {
 	workflowContent := `workflow:
-  name: slack-basic-demo
+  name: tf-acc-slack-basic-demo
   description: Send a slack message when a cloudwatch alarm is triggered
   triggers:
     - type: alert
@@ -162,7 +168,7 @@ func TestAccKeepWorkflow_Slack(t *testing.T) {
 					testAccWorkflowConfig(tmpfilePath),
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckWorkflowExists("keep_workflow.test"),
-					resource.TestCheckResourceAttr("keep_workflow.test", "name", "slack-basic-demo"),
+					resource.TestCheckResourceAttr("keep_workflow.test", "name", "tf-acc-slack-basic-demo"),
 					resource.TestCheckResourceAttr("keep_workflow.test", "description", "Send a slack message when a cloudwatch alarm is triggered"),
 				),
 			},
@@ -170,7 +176,7 @@ func TestAccKeepWorkflow_Slack(t *testing.T) {
 				ResourceName:            "keep_workflow.test",
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"workflow_file_path", "workflow_content_hash"},
+				ImportStateVerifyIgnore: []string{"content", "workflow_file_path", "workflow_content_hash"},
 			},
 		},
 	})
@@ -188,7 +194,7 @@ func testAccCheckWorkflowExists(n string) resource.TestCheckFunc {
 		}
 
 		client := testAccProvider.Meta().(*Client)
-		workflow, errResp, err := client.GetWorkflow(rs.Primary.ID)
+		workflow, errResp, err := client.GetWorkflow(context.Background(), rs.Primary.ID)
 		if err != nil {
 			if errResp != nil {
 				return fmt.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
@@ -212,7 +218,7 @@ func testAccCheckWorkflowDestroy(s *terraform.State) error {
 			continue
 		}
 
-		workflow, errResp, err := client.GetWorkflow(rs.Primary.ID)
+		workflow, errResp, err := client.GetWorkflow(context.Background(), rs.Primary.ID)
 		if err == nil && workflow != nil {
 			return fmt.Errorf("workflow still exists")
 		}
@@ -227,7 +233,7 @@ func testAccCheckWorkflowDestroy(s *terraform.State) error {
 
 func TestAccKeepWorkflow_ContentChange(t *testing.T) {
 	workflowContent := `workflow:
-  name: content-change-test
+  name: tf-acc-content-change-test
   description: Initial workflow content
   triggers:
     - type: manual
@@ -239,7 +245,7 @@ func TestAccKeepWorkflow_ContentChange(t *testing.T) {
           message: "Initial message"`
 
 	updatedContent := `workflow:
-  name: content-change-test
+  name: tf-acc-content-change-test
   description: Updated workflow content
   triggers:
     - type: manual
@@ -276,7 +282,7 @@ func TestAccKeepWorkflow_ContentChange(t *testing.T) {
 					testAccWorkflowConfig(tmpfilePath),
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckWorkflowExists("keep_workflow.test"),
-					resource.TestCheckResourceAttr("keep_workflow.test", "name", "content-change-test"),
+					resource.TestCheckResourceAttr("keep_workflow.test", "name", "tf-acc-content-change-test"),
 					resource.TestCheckResourceAttr("keep_workflow.test", "description", "Initial workflow content"),
 				),
 			},
@@ -290,10 +296,391 @@ func TestAccKeepWorkflow_ContentChange(t *testing.T) {
 					testAccWorkflowConfig(tmpfilePath),
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckWorkflowExists("keep_workflow.test"),
-					resource.TestCheckResourceAttr("keep_workflow.test", "name", "content-change-test"),
+					resource.TestCheckResourceAttr("keep_workflow.test", "name", "tf-acc-content-change-test"),
 					resource.TestCheckResourceAttr("keep_workflow.test", "description", "Updated workflow content"),
 				),
 			},
 		},
 	})
 }
+
+func TestResourceWorkflow_MockBackend(t *testing.T) {
+	backend := newMockBackend(t)
+	client := backend.client()
+	r := resourceWorkflow()
+
+	workflowPath := filepath.Join(t.TempDir(), "workflow.yml")
+	writeWorkflow := func(description string) {
+		content := fmt.Sprintf(`workflow:
+  id: on-field-change
+  name: on-field-change
+  description: %s
+  triggers:
+    - type: manual
+  actions:
+    - name: echo
+      provider:
+        type: console
+        with:
+          message: "Hello world"`, description)
+		if err := os.WriteFile(workflowPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeWorkflow("first")
+	config := map[string]interface{}{"file": workflowPath}
+
+	state, diags := applyMockResource(t, r, nil, config, client)
+	if diags.HasError() {
+		t.Fatalf("unexpected error on create: %v", diags)
+	}
+	if state.ID != "on-field-change" || state.Attributes["description"] != "first" || state.Attributes["revision"] != "1" {
+		t.Fatalf("unexpected state after create: %s %v", state.ID, state.Attributes)
+	}
+
+	// a changed file replaces the workflow
+	writeWorkflow("second")
+	if state, diags = applyMockResource(t, r, state, config, client); diags.HasError() {
+		t.Fatalf("unexpected error on update: %v", diags)
+	}
+	if state.Attributes["description"] != "second" || backend.requestCount("DELETE", "/workflows/on-field-change") != 1 || len(backend.workflows) != 1 {
+		t.Errorf("expected the workflow to be replaced, got %v and %+v", state.Attributes, backend.workflows)
+	}
+
+	// a moved file updates the workflow in place
+	movedPath := filepath.Join(t.TempDir(), "moved.yml")
+	if err := os.Rename(workflowPath, movedPath); err != nil {
+		t.Fatal(err)
+	}
+	if state, diags = applyMockResource(t, r, state, map[string]interface{}{"file": movedPath}, client); diags.HasError() {
+		t.Fatalf("unexpected error on update: %v", diags)
+	}
+	if state.Attributes["file"] != movedPath || state.Attributes["revision"] != "2" || backend.requestCount("DELETE", "/workflows/on-field-change") != 1 {
+		t.Errorf("expected a new revision of the workflow, got %v and %+v", state.Attributes, backend.workflows)
+	}
+
+	// the same workflow as content, e.g. of a generated configuration, updates the workflow in place
+	content, err := os.ReadFile(movedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state, diags = applyMockResource(t, r, state, map[string]interface{}{"content": string(content)}, client); diags.HasError() {
+		t.Fatalf("unexpected error on update: %v", diags)
+	}
+	if state.Attributes["revision"] != "3" || backend.requestCount("DELETE", "/workflows/on-field-change") != 1 {
+		t.Errorf("expected a new revision of the workflow, got %v and %+v", state.Attributes, backend.workflows)
+	}
+
+	if state, diags = refreshMockResource(t, r, state, client); diags.HasError() || state.Attributes["name"] != "on-field-change" {
+		t.Errorf("unexpected refresh result: %v, %v", state, diags)
+	}
+
+	if state, diags = applyMockResource(t, r, state, nil, client); diags.HasError() || state != nil {
+		t.Fatalf("unexpected destroy result: %v, %v", state, diags)
+	}
+	if len(backend.workflows) != 0 {
+		t.Errorf("expected the workflow to be deleted, got %+v", backend.workflows)
+	}
+}
+
+func TestResourceWorkflow_ReadErrors(t *testing.T) {
+	backend := newMockBackend(t)
+	client := backend.client()
+	r := resourceWorkflow()
+	backend.workflows["test"] = Workflow{ID: "test", Name: "test"}
+	state := &terraform.InstanceState{ID: "test", Attributes: map[string]string{"id": "test"}}
+
+	// a backend which can't be reached must not remove the workflow from the state
+	unreachable := NewClient("http://127.0.0.1:1", "key", time.Second)
+	unreachable.MaxRetries = 0
+	if newState, diags := refreshMockResource(t, r, state, unreachable); !diags.HasError() || newState != nil && newState.ID != "test" {
+		t.Errorf("expected an error keeping the workflow, got %v, %v", newState, diags)
+	}
+
+	delete(backend.workflows, "test")
+	if newState, diags := refreshMockResource(t, r, state, client); diags.HasError() || newState != nil {
+		t.Errorf("expected a deleted workflow to be removed from the state, got %v, %v", newState, diags)
+	}
+	if _, diags := applyMockResource(t, r, state, nil, client); diags.HasError() {
+		t.Errorf("expected destroying a deleted workflow to succeed, got %v", diags)
+	}
+}
+
+func TestResourceWorkflowStateUpgradeV0(t *testing.T) {
+	rawState := map[string]interface{}{"id": "on-field-change", "workflow_file_path": "workflows/on-field-change.yml"}
+	actual, err := resourceWorkflowStateUpgradeV0(context.Background(), rawState, nil)
+	if err != nil {
+		t.Fatalf("error upgrading state: %s", err)
+	}
+	if actual["file"] != "workflows/on-field-change.yml" || actual["workflow_file_path"] != nil {
+		t.Errorf("expected the path to move to file, got %v", actual)
+	}
+
+	rawState = map[string]interface{}{"id": "on-field-change", "file": "workflows/on-field-change.yml"}
+	if actual, _ := resourceWorkflowStateUpgradeV0(context.Background(), rawState, nil); actual["file"] != "workflows/on-field-change.yml" {
+		t.Errorf("expected file to be kept, got %v", actual)
+	}
+}
+
+func TestResourceWorkflow_MovedFileAttribute(t *testing.T) {
+	workflowPath := filepath.Join(t.TempDir(), "workflow.yml")
+	if err := os.WriteFile(workflowPath, []byte("workflow:\n  id: test\n  name: test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := calculateFileHash(workflowPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the upgraded state of a configuration still using the deprecated attribute, and a configuration
+	// replacing the deprecated attribute by file
+	for stateAttribute, configAttribute := range map[string]string{"file": "workflow_file_path", "workflow_file_path": "file"} {
+		state := &terraform.InstanceState{
+			ID: "test",
+			Attributes: map[string]string{
+				"id":                    "test",
+				"name":                  "test",
+				stateAttribute:          workflowPath,
+				"workflow_content_hash": hash,
+				"secret_names.#":        "0",
+			},
+		}
+		config := terraform.NewResourceConfigRaw(map[string]interface{}{configAttribute: workflowPath})
+
+		diff, err := resourceWorkflow().SimpleDiff(context.Background(), state, config, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff != nil && len(diff.Attributes) > 0 {
+			t.Errorf("expected no changes after moving the path from %s to %s, got %v", stateAttribute, configAttribute, diff.Attributes)
+		}
+	}
+}
+
+func TestResourceImportWorkflow(t *testing.T) {
+	backend := newMockBackend(t)
+	client := backend.client()
+	raw := "workflow:\n  id: on-field-change\n  name: on-field-change\n  triggers:\n    - type: manual\n"
+	backend.workflows["a1b2"] = Workflow{ID: "a1b2", Name: "on-field-change", WorkflowRaw: raw}
+
+	d := resourceWorkflow().Data(nil)
+	d.SetId("name=on-field-change")
+	result, err := resourceImportWorkflow(context.Background(), d, client)
+	if err != nil || result[0].Id() != "a1b2" {
+		t.Fatalf("unexpected import result: %v, %v", result, err)
+	}
+	// generated configurations keep the workflow of the backend
+	if result[0].Get("content") != raw || result[0].Get("workflow_content_hash") != calculateContentHash([]byte(raw)) {
+		t.Errorf("expected the content of the imported workflow, got %q", result[0].Get("content"))
+	}
+
+	d.SetId("name=unknown")
+	if _, err := resourceImportWorkflow(context.Background(), d, client); err == nil || !strings.Contains(err.Error(), "workflow with name 'unknown' not found") {
+		t.Errorf("expected error importing an unknown workflow, got %v", err)
+	}
+}
+
+func TestResourceWorkflow_DeletionProtection(t *testing.T) {
+	backend := newMockBackend(t)
+	client := backend.client()
+	r := resourceWorkflow()
+
+	workflowPath := filepath.Join(t.TempDir(), "workflow.yml")
+	if err := os.WriteFile(workflowPath, []byte("workflow:\n  id: protected\n  name: protected\n  triggers:\n    - type: manual\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := map[string]interface{}{"file": workflowPath, "deletion_protection": true}
+
+	state, diags := applyMockResource(t, r, nil, config, client)
+	if diags.HasError() {
+		t.Fatalf("unexpected error on create: %v", diags)
+	}
+
+	if _, diags := applyMockResource(t, r, state, nil, client); !diags.HasError() || !diags[0].AttributePath.Equals(cty.GetAttrPath("deletion_protection")) {
+		t.Fatalf("expected the destroy to be refused, got %v", diags)
+	}
+	if len(backend.workflows) != 1 {
+		t.Fatalf("expected the workflow to be kept, got %+v", backend.workflows)
+	}
+
+	config["deletion_protection"] = false
+	if state, diags = applyMockResource(t, r, state, config, client); diags.HasError() {
+		t.Fatalf("unexpected error disabling deletion protection: %v", diags)
+	}
+	if _, diags := applyMockResource(t, r, state, nil, client); diags.HasError() || len(backend.workflows) != 0 {
+		t.Errorf("expected the workflow to be deleted, got %v and %+v", diags, backend.workflows)
+	}
+}
+
+func TestResourceWorkflow_WriteOnlySecrets(t *testing.T) {
+	backend := newMockBackend(t)
+	client := backend.client()
+	r := resourceWorkflow()
+
+	workflowPath := filepath.Join(t.TempDir(), "workflow.yml")
+	if err := os.WriteFile(workflowPath, []byte("workflow:\n  id: secrets\n  name: secrets\n  triggers:\n    - type: manual\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := map[string]interface{}{
+		"file":               workflowPath,
+		"secrets_wo":         `{"token": "first", "webhook": "hook"}`,
+		"secrets_wo_version": 1,
+	}
+
+	state, diags := applyMockResource(t, r, nil, config, client)
+	if diags.HasError() {
+		t.Fatalf("unexpected error on create: %v", diags)
+	}
+	if secrets := backend.secrets["secrets"]; secrets["token"] != "first" || secrets["webhook"] != "hook" {
+		t.Fatalf("expected the secrets to be written, got %v", secrets)
+	}
+	for key, value := range state.Attributes {
+		if strings.Contains(value, "first") {
+			t.Errorf("expected the secrets to be kept out of state, got %s = %s", key, value)
+		}
+	}
+
+	// without a new version the secrets are not written again
+	config["secrets_wo"] = `{"token": "second"}`
+	if state, diags = applyMockResource(t, r, state, config, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if backend.secrets["secrets"]["token"] != "first" {
+		t.Fatalf("expected the secrets to be kept, got %v", backend.secrets["secrets"])
+	}
+
+	config["secrets_wo_version"] = 2
+	if state, diags = applyMockResource(t, r, state, config, client); diags.HasError() {
+		t.Fatalf("unexpected error on update: %v", diags)
+	}
+	if secrets := backend.secrets["secrets"]; len(secrets) != 1 || secrets["token"] != "second" {
+		t.Errorf("expected the token to be updated and the webhook to be deleted, got %v", secrets)
+	}
+	if count := backend.requestCount("POST", "/workflows/json"); count != 1 {
+		t.Errorf("expected the workflow to be uploaded once, got %d uploads", count)
+	}
+	if state.Attributes["secret_names.#"] != "1" {
+		t.Errorf("expected the name of the token in state, got %v", state.Attributes)
+	}
+}
+
+func TestResourceWorkflow_Drift(t *testing.T) {
+	backend := newMockBackend(t)
+	client := backend.client()
+	r := resourceWorkflow()
+
+	workflowPath := filepath.Join(t.TempDir(), "workflow.yml")
+	content := "workflow:\n  id: drift\n  name: drift\n  description: from file\n  triggers:\n    - type: interval\n      value: 60\n"
+	if err := os.WriteFile(workflowPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := map[string]interface{}{"file": workflowPath}
+
+	state, diags := applyMockResource(t, r, nil, config, client)
+	if diags.HasError() {
+		t.Fatalf("unexpected error on create: %v", diags)
+	}
+
+	// the workflow is disabled and renamed in the UI
+	workflow := backend.workflows["drift"]
+	workflow.Interval = 60
+	workflow.WorkflowRaw = "workflow:\n  id: drift\n  name: renamed\n  description: from file\n  disabled: true\n"
+	backend.workflows["drift"] = workflow
+
+	if state, diags = refreshMockResource(t, r, state, client); diags.HasError() {
+		t.Fatalf("unexpected refresh result: %v", diags)
+	}
+	if state.Attributes["name"] != "renamed" || state.Attributes["disabled"] != "true" || state.Attributes["interval"] != "60" {
+		t.Fatalf("expected the changes of the UI in the state, got %v", state.Attributes)
+	}
+
+	diff, err := r.SimpleDiff(context.Background(), state, terraform.NewResourceConfigRaw(config), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff == nil || diff.RequiresNew() || diff.Attributes["disabled"] == nil || diff.Attributes["name"] == nil || diff.Attributes["interval"] != nil {
+		t.Fatalf("expected an update restoring the workflow file, got %v", diff)
+	}
+
+	if state, diags = applyMockResource(t, r, state, config, client); diags.HasError() {
+		t.Fatalf("unexpected error on update: %v", diags)
+	}
+	if state.Attributes["name"] != "drift" || state.Attributes["disabled"] != "false" || backend.requestCount("DELETE", "/workflows/drift") != 0 {
+		t.Errorf("expected the workflow file to be restored in place, got %v", state.Attributes)
+	}
+}
+
+func TestValidateWorkflowEnrichmentReferences(t *testing.T) {
+	content := `workflow:
+  id: enriched
+  name: enriched
+  triggers:
+    - type: alert
+      filters:
+        - key: region
+          value: eu
+  actions:
+    - name: notify
+      if: "{{ alert.team }} == 'platform'"
+      provider:
+        type: console
+        with:
+          message: "{{ alert.name }} of {{ alert.labels.service }} is {{ alert.runbook }}"
+`
+	workflowPath := filepath.Join(t.TempDir(), "workflow.yml")
+	if err := os.WriteFile(workflowPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	configType := resourceWorkflow().CoreConfigSchema().ImpliedType()
+	config := func(attributes map[string]cty.Value) cty.Value {
+		values := make(map[string]cty.Value)
+		for name, attributeType := range configType.AttributeTypes() {
+			values[name] = cty.NullVal(attributeType)
+		}
+		for name, value := range attributes {
+			values[name] = value
+		}
+		return cty.ObjectVal(values)
+	}
+	enrichments := cty.SetVal([]cty.Value{cty.StringVal("team"), cty.StringVal("region")})
+
+	cases := []struct {
+		name     string
+		config   cty.Value
+		warnings int
+	}{
+		{name: "without enrichment_attributes", config: config(map[string]cty.Value{"content": cty.StringVal(content)})},
+		{name: "unknown enrichment_attributes", config: config(map[string]cty.Value{
+			"content":               cty.StringVal(content),
+			"enrichment_attributes": cty.UnknownVal(cty.Set(cty.String)),
+		})},
+		{name: "unknown content", config: config(map[string]cty.Value{
+			"content":               cty.UnknownVal(cty.String),
+			"enrichment_attributes": enrichments,
+		})},
+		{name: "content", warnings: 1, config: config(map[string]cty.Value{
+			"content":               cty.StringVal(content),
+			"enrichment_attributes": enrichments,
+		})},
+		{name: "file", warnings: 2, config: config(map[string]cty.Value{
+			"file":                  cty.StringVal(workflowPath),
+			"enrichment_attributes": cty.SetVal([]cty.Value{cty.StringVal("team")}),
+		})},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &schema.ValidateResourceConfigFuncResponse{}
+			validateWorkflowEnrichmentReferences(context.Background(), schema.ValidateResourceConfigFuncRequest{RawConfig: tc.config}, resp)
+			if len(resp.Diagnostics) != tc.warnings {
+				t.Fatalf("expected %d warnings, got %v", tc.warnings, resp.Diagnostics)
+			}
+			for _, d := range resp.Diagnostics {
+				if d.Severity != diag.Warning {
+					t.Errorf("expected a warning, got %v", d)
+				}
+			}
+		})
+	}
+}
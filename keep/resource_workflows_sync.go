@@ -0,0 +1,315 @@
+package keep
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/spf13/cast"
+)
+
+// workflowSyncFile is a workflow file of the synced directory
+type workflowSyncFile struct {
+	Name    string
+	Content []byte
+	Hash    string
+}
+
+func resourceWorkflowsSync() *schema.Resource {
+	return &schema.Resource{
+		Description: "Treats a directory as the source of truth for the workflows of the tenant: new and changed workflow files " +
+			"are uploaded and workflows of the backend which are not in the directory are deleted, unless they are protected.",
+		CreateContext: resourceCreateWorkflowsSync,
+		ReadContext:   resourceReadWorkflowsSync,
+		UpdateContext: resourceUpdateWorkflowsSync,
+		DeleteContext: resourceDeleteWorkflowsSync,
+		CustomizeDiff: customizeDiffWorkflowsSync,
+		Schema: map[string]*schema.Schema{
+			"directory": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Path of the directory with the workflow files (*.yml, *.yaml), subdirectories are ignored",
+			},
+			"protected": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "IDs or names of workflows which are never deleted, e.g. workflows managed by keep_workflow or in the UI",
+			},
+			"content_hashes": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Hashes of the uploaded workflow files by file name for change detection",
+			},
+			"workflow_ids": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the synced workflows by file name",
+			},
+			"pending_deletion": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "IDs of workflows of the backend which are neither in the directory nor protected, they are deleted by the next apply",
+			},
+		},
+	}
+}
+
+// readWorkflowSyncFiles reads and validates the workflow files of the directory, sorted by file name
+func readWorkflowSyncFiles(directory string) ([]workflowSyncFile, error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read workflow directory: %s", err)
+	}
+
+	var files []workflowSyncFile
+	names := make(map[string]string)
+	for _, entry := range entries {
+		extension := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (extension != ".yml" && extension != ".yaml") {
+			continue
+		}
+
+		path := filepath.Join(directory, entry.Name())
+		if err := validateWorkflowFile(path); err != nil {
+			return nil, fmt.Errorf("workflow file %s: %s", path, err)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read workflow file %s: %s", path, err)
+		}
+
+		// the backend adds revisions to the workflow with the same name, so every name has to be unique
+		fields, err := parseWorkflowFields(content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid workflow file %s: %s", path, err)
+		}
+		if other, ok := names[fields.Name]; ok {
+			return nil, fmt.Errorf("workflow files %s and %s have the same name '%s'", other, entry.Name(), fields.Name)
+		}
+		names[fields.Name] = entry.Name()
+
+		files = append(files, workflowSyncFile{Name: entry.Name(), Content: content, Hash: fmt.Sprintf("%x", sha256.Sum256(content))})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files, nil
+}
+
+// customizeDiffWorkflowsSync plans an update if workflow files were added, changed or removed, synced workflows
+// were deleted outside of terraform or workflows are pending deletion
+func customizeDiffWorkflowsSync(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("directory") {
+		return d.SetNewComputed("workflow_ids")
+	}
+
+	files, err := readWorkflowSyncFiles(d.Get("directory").(string))
+	if err != nil {
+		return err
+	}
+
+	hashes := make(map[string]interface{}, len(files))
+	for _, file := range files {
+		hashes[file.Name] = file.Hash
+	}
+
+	oldHashes := d.Get("content_hashes").(map[string]interface{})
+	ids := d.Get("workflow_ids").(map[string]interface{})
+	changed := len(oldHashes) != len(hashes) || len(ids) != len(files)
+	for name, hash := range hashes {
+		if oldHashes[name] != hash {
+			changed = true
+		}
+	}
+	if changed {
+		if err := d.SetNew("content_hashes", hashes); err != nil {
+			return err
+		}
+		if err := d.SetNewComputed("workflow_ids"); err != nil {
+			return err
+		}
+	}
+
+	if d.Get("pending_deletion").(*schema.Set).Len() > 0 {
+		return d.SetNew("pending_deletion", []interface{}{})
+	}
+	return nil
+}
+
+// isProtectedWorkflow reports whether the id or name of the workflow is protected
+func isProtectedWorkflow(workflow Workflow, protected *schema.Set) bool {
+	return protected.Contains(string(workflow.ID)) || protected.Contains(workflow.Name)
+}
+
+// reconcileWorkflows uploads the new and changed workflow files and deletes the workflows of the backend which are
+// neither in the directory nor protected. It returns the ids of the synced workflows and their hashes by file name.
+func reconcileWorkflows(ctx context.Context, d *schema.ResourceData, client KeepClient) (map[string]interface{}, map[string]interface{}, diag.Diagnostics) {
+	ids := make(map[string]interface{})
+	hashes := make(map[string]interface{})
+
+	files, err := readWorkflowSyncFiles(d.Get("directory").(string))
+	if err != nil {
+		return ids, hashes, attributeErrorf(cty.GetAttrPath("directory"), "%s", err)
+	}
+
+	workflows, errResp, err := client.ListWorkflows(ctx)
+	if err != nil {
+		if errResp != nil {
+			return ids, hashes, diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
+		}
+		return ids, hashes, diag.Errorf("error listing workflows: %s", err)
+	}
+	existing := make(map[string]bool, len(workflows))
+	for _, workflow := range workflows {
+		existing[string(workflow.ID)] = true
+	}
+
+	oldIDs, _ := d.GetChange("workflow_ids")
+	oldHashes, _ := d.GetChange("content_hashes")
+	for _, file := range files {
+		id := cast.ToString(oldIDs.(map[string]interface{})[file.Name])
+		if id != "" && existing[id] && oldHashes.(map[string]interface{})[file.Name] == file.Hash {
+			ids[file.Name], hashes[file.Name] = id, file.Hash
+			continue
+		}
+
+		workflowData, err := yamlToJSONMap(file.Content)
+		if err != nil {
+			return ids, hashes, attributeErrorf(cty.GetAttrPath("directory"), "invalid workflow file %s: %s", file.Name, err)
+		}
+		response, errResp, err := client.CreateWorkflowJSON(ctx, workflowData)
+		if err != nil {
+			if errResp != nil {
+				return ids, hashes, diag.Errorf("API Error uploading %s: %s. Details: %s", file.Name, errResp.Error, errResp.Details)
+			}
+			return ids, hashes, diag.Errorf("error uploading workflow %s: %s", file.Name, err)
+		}
+		if response.WorkflowID == "" {
+			return ids, hashes, diag.Errorf("no workflow id found in response for %s", file.Name)
+		}
+		ids[file.Name], hashes[file.Name] = string(response.WorkflowID), file.Hash
+	}
+
+	synced := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		synced[cast.ToString(id)] = true
+	}
+	protected := d.Get("protected").(*schema.Set)
+	for _, workflow := range workflows {
+		if synced[string(workflow.ID)] || isProtectedWorkflow(workflow, protected) {
+			continue
+		}
+
+		errResp, err := client.DeleteWorkflow(ctx, string(workflow.ID))
+		if err != nil && !isNotFound(err) {
+			if errResp != nil {
+				return ids, hashes, diag.Errorf("API Error deleting workflow '%s': %s. Details: %s", workflow.Name, errResp.Error, errResp.Details)
+			}
+			return ids, hashes, diag.Errorf("error deleting workflow '%s': %s", workflow.Name, err)
+		}
+	}
+
+	return ids, hashes, nil
+}
+
+func resourceCreateWorkflowsSync(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(KeepClient)
+
+	ids, hashes, diags := reconcileWorkflows(ctx, d, client)
+	if len(ids) == 0 && diags.HasError() {
+		return diags
+	}
+
+	// Keep the workflows uploaded so far in state, even if a later one failed
+	d.SetId(d.Get("directory").(string))
+	if err := setAttributes(d, map[string]interface{}{"workflow_ids": ids, "content_hashes": hashes}); err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	return resourceReadWorkflowsSync(ctx, d, m)
+}
+
+func resourceReadWorkflowsSync(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(KeepClient)
+
+	workflows, errResp, err := client.ListWorkflows(ctx)
+	if err != nil {
+		if errResp != nil {
+			return diag.Errorf("API Error: %s. Details: %s", errResp.Error, errResp.Details)
+		}
+		return diag.Errorf("error listing workflows: %s", err)
+	}
+
+	existing := make(map[string]bool, len(workflows))
+	for _, workflow := range workflows {
+		existing[string(workflow.ID)] = true
+	}
+
+	// Workflows deleted outside of terraform are uploaded again on the next apply
+	ids := make(map[string]interface{})
+	synced := make(map[string]bool)
+	for name, id := range d.Get("workflow_ids").(map[string]interface{}) {
+		if existing[cast.ToString(id)] {
+			ids[name] = id
+			synced[cast.ToString(id)] = true
+		}
+	}
+
+	protected := d.Get("protected").(*schema.Set)
+	pending := make([]string, 0)
+	for _, workflow := range workflows {
+		if !synced[string(workflow.ID)] && !isProtectedWorkflow(workflow, protected) {
+			pending = append(pending, string(workflow.ID))
+		}
+	}
+
+	return diag.FromErr(setAttributes(d, map[string]interface{}{"workflow_ids": ids, "pending_deletion": pending}))
+}
+
+func resourceUpdateWorkflowsSync(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(KeepClient)
+
+	ids, hashes, diags := reconcileWorkflows(ctx, d, client)
+	if err := setAttributes(d, map[string]interface{}{"workflow_ids": ids, "content_hashes": hashes}); err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	return resourceReadWorkflowsSync(ctx, d, m)
+}
+
+// resourceDeleteWorkflowsSync deletes the synced workflows, other workflows of the backend are kept
+func resourceDeleteWorkflowsSync(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(KeepClient)
+
+	for name, id := range d.Get("workflow_ids").(map[string]interface{}) {
+		errResp, err := client.DeleteWorkflow(ctx, cast.ToString(id))
+		if err != nil && !isNotFound(err) {
+			if errResp != nil {
+				return diag.Errorf("API Error deleting workflow of %s: %s. Details: %s", name, errResp.Error, errResp.Details)
+			}
+			return diag.Errorf("error deleting workflow of %s: %s", name, err)
+		}
+	}
+
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,118 @@
+package keep
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeSyncWorkflow(t *testing.T, directory, file, name, description string) {
+	t.Helper()
+	content := fmt.Sprintf("workflow:\n  id: %s\n  name: %s\n  description: %s\n  triggers:\n    - type: manual\n", name, name, description)
+	if err := os.WriteFile(filepath.Join(directory, file), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadWorkflowSyncFiles(t *testing.T) {
+	directory := t.TempDir()
+	writeSyncWorkflow(t, directory, "b.yaml", "b", "second")
+	writeSyncWorkflow(t, directory, "a.yml", "a", "first")
+	if err := os.WriteFile(filepath.Join(directory, "README.md"), []byte("not a workflow"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(directory, "drafts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeSyncWorkflow(t, filepath.Join(directory, "drafts"), "c.yml", "c", "draft")
+
+	files, err := readWorkflowSyncFiles(directory)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(files) != 2 || files[0].Name != "a.yml" || files[1].Name != "b.yaml" {
+		t.Errorf("expected the workflow files sorted by name, got %+v", files)
+	}
+
+	writeSyncWorkflow(t, directory, "copy.yml", "a", "copy")
+	if _, err := readWorkflowSyncFiles(directory); err == nil {
+		t.Error("expected an error for two workflows with the same name")
+	}
+}
+
+func TestResourceWorkflowsSync_MockBackend(t *testing.T) {
+	backend := newMockBackend(t)
+	client := backend.client()
+	r := resourceWorkflowsSync()
+	ctx := context.Background()
+
+	for _, name := range []string{"manual", "stale"} {
+		if _, _, err := client.CreateWorkflowJSON(ctx, map[string]interface{}{"workflow": map[string]interface{}{"id": name, "name": name}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	directory := t.TempDir()
+	writeSyncWorkflow(t, directory, "a.yml", "a", "first")
+	writeSyncWorkflow(t, directory, "b.yml", "b", "second")
+	config := map[string]interface{}{"directory": directory, "protected": []interface{}{"manual"}}
+
+	state, diags := applyMockResource(t, r, nil, config, client)
+	if diags.HasError() {
+		t.Fatalf("unexpected error on create: %v", diags)
+	}
+	if _, ok := backend.workflows["stale"]; ok || len(backend.workflows) != 3 {
+		t.Fatalf("expected the files to be uploaded and the stale workflow to be deleted, got %v", sortedMockKeys(backend.workflows))
+	}
+	if state.Attributes["workflow_ids.a.yml"] != "a" || state.Attributes["workflow_ids.b.yml"] != "b" {
+		t.Errorf("expected the ids of the synced workflows, got %v", state.Attributes)
+	}
+
+	// unchanged files are not uploaded again
+	if state, diags = applyMockResource(t, r, state, config, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if count := backend.requestCount("POST", "/workflows/json"); count != 4 {
+		t.Errorf("expected no uploads without changes, got %d uploads", count)
+	}
+
+	writeSyncWorkflow(t, directory, "b.yml", "b", "changed")
+	if err := os.Remove(filepath.Join(directory, "a.yml")); err != nil {
+		t.Fatal(err)
+	}
+	if state, diags = applyMockResource(t, r, state, config, client); diags.HasError() {
+		t.Fatalf("unexpected error on update: %v", diags)
+	}
+	if count := backend.requestCount("POST", "/workflows/json"); count != 5 {
+		t.Errorf("expected only the changed file to be uploaded, got %d uploads", count)
+	}
+	if _, ok := backend.workflows["a"]; ok {
+		t.Error("expected the workflow of the removed file to be deleted")
+	}
+
+	// workflows created outside of terraform are pending deletion until the next apply
+	if _, _, err := client.CreateWorkflowJSON(ctx, map[string]interface{}{"workflow": map[string]interface{}{"id": "ui", "name": "ui"}}); err != nil {
+		t.Fatal(err)
+	}
+	if state, diags = refreshMockResource(t, r, state, client); diags.HasError() {
+		t.Fatalf("unexpected error on refresh: %v", diags)
+	}
+	if state.Attributes["pending_deletion.#"] != "1" {
+		t.Fatalf("expected the workflow created in the UI to be pending deletion, got %v", state.Attributes)
+	}
+	if state, diags = applyMockResource(t, r, state, config, client); diags.HasError() {
+		t.Fatalf("unexpected error on update: %v", diags)
+	}
+	if _, ok := backend.workflows["ui"]; ok {
+		t.Error("expected the workflow created in the UI to be deleted")
+	}
+
+	if _, diags := applyMockResource(t, r, state, nil, client); diags.HasError() {
+		t.Fatalf("unexpected error on destroy: %v", diags)
+	}
+	if keys := sortedMockKeys(backend.workflows); len(keys) != 1 || keys[0] != "manual" {
+		t.Errorf("expected only the protected workflow to be kept, got %v", keys)
+	}
+}
@@ -0,0 +1,169 @@
+package keep
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// TestMain runs the sweepers with "go test ./keep -v -sweep=all", which removes the objects aborted
+// acceptance test runs left in the test tenant
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+// sweepNamePrefix is the prefix of the names of all objects acceptance tests create. Only objects with the
+// prefix are swept, so objects of the test tenant which weren't created by the tests are never deleted.
+const sweepNamePrefix = "tf-acc-"
+
+func init() {
+	resource.AddTestSweepers("keep_workflow", &resource.Sweeper{
+		Name: "keep_workflow",
+		F:    withSweepClient(sweepWorkflows),
+	})
+	// workflows may reference the providers
+	resource.AddTestSweepers("keep_provider", &resource.Sweeper{
+		Name:         "keep_provider",
+		Dependencies: []string{"keep_workflow"},
+		F:            withSweepClient(sweepProviders),
+	})
+	resource.AddTestSweepers("keep_mapping", &resource.Sweeper{
+		Name: "keep_mapping",
+		F:    withSweepClient(sweepMappings),
+	})
+	resource.AddTestSweepers("keep_extraction", &resource.Sweeper{
+		Name: "keep_extraction",
+		F:    withSweepClient(sweepExtractions),
+	})
+}
+
+// isSweepable reports whether the object was created by an acceptance test
+func isSweepable(name string) bool {
+	return strings.HasPrefix(name, sweepNamePrefix)
+}
+
+// withSweepClient runs the sweeper with a client of the backend of the acceptance tests
+func withSweepClient(sweep func(ctx context.Context, client *Client) error) func(region string) error {
+	return func(region string) error {
+		if os.Getenv("KEEP_BACKEND_URL") == "" || os.Getenv("KEEP_API_KEY") == "" {
+			return fmt.Errorf("KEEP_BACKEND_URL and KEEP_API_KEY must be set for sweepers")
+		}
+		return sweep(context.Background(), initTestClient())
+	}
+}
+
+func sweepWorkflows(ctx context.Context, client *Client) error {
+	workflows, errResp, err := client.ListWorkflows(ctx)
+	if err != nil {
+		return sweepError("listing workflows", errResp, err)
+	}
+
+	for _, workflow := range workflows {
+		if !isSweepable(workflow.Name) {
+			continue
+		}
+		if errResp, err := client.DeleteWorkflow(ctx, string(workflow.ID)); err != nil && !isNotFound(err) {
+			return sweepError(fmt.Sprintf("deleting workflow %s", workflow.ID), errResp, err)
+		}
+	}
+
+	return nil
+}
+
+func sweepProviders(ctx context.Context, client *Client) error {
+	providers, errResp, err := client.GetInstalledProviders(ctx)
+	if err != nil {
+		return sweepError("listing providers", errResp, err)
+	}
+
+	for _, provider := range providers {
+		if !isSweepable(provider.Details.Name) {
+			continue
+		}
+		if errResp, err := client.DeleteProvider(ctx, provider.Type, string(provider.ID)); err != nil && !isNotFound(err) {
+			return sweepError(fmt.Sprintf("deleting provider %s", provider.ID), errResp, err)
+		}
+	}
+
+	return nil
+}
+
+func sweepMappings(ctx context.Context, client *Client) error {
+	mappings, errResp, err := client.GetMappings(ctx)
+	if err != nil {
+		return sweepError("listing mappings", errResp, err)
+	}
+
+	for _, mapping := range mappings {
+		if !isSweepable(mapping.Name) {
+			continue
+		}
+		if errResp, err := client.DeleteMapping(ctx, string(mapping.ID)); err != nil && !isNotFound(err) {
+			return sweepError(fmt.Sprintf("deleting mapping %s", mapping.ID), errResp, err)
+		}
+	}
+
+	return nil
+}
+
+func sweepExtractions(ctx context.Context, client *Client) error {
+	extractions, errResp, err := client.GetExtractions(ctx)
+	if err != nil {
+		return sweepError("listing extractions", errResp, err)
+	}
+
+	for _, extraction := range extractions {
+		if !isSweepable(extraction.Name) {
+			continue
+		}
+		if errResp, err := client.DeleteExtraction(ctx, string(extraction.ID)); err != nil && !isNotFound(err) {
+			return sweepError(fmt.Sprintf("deleting extraction %s", extraction.ID), errResp, err)
+		}
+	}
+
+	return nil
+}
+
+func sweepError(action string, errResp *ErrorResponse, err error) error {
+	if errResp != nil {
+		return fmt.Errorf("error %s: API Error: %s. Details: %s", action, errResp.Error, errResp.Details)
+	}
+	return fmt.Errorf("error %s: %s", action, err)
+}
+
+func TestSweepers(t *testing.T) {
+	backend := newMockBackend(t)
+	client := backend.client()
+
+	backend.workflows["tf-acc-on-field-change"] = Workflow{ID: "tf-acc-on-field-change", Name: "tf-acc-on-field-change"}
+	backend.workflows["production"] = Workflow{ID: "production", Name: "production"}
+	backend.providers["1"] = KeepProvider{ID: "1", Type: "grafana", Details: ProviderDetails{Name: "tf-acc-grafana-webhook"}}
+	backend.providers["2"] = KeepProvider{ID: "2", Type: "grafana", Details: ProviderDetails{Name: "test-grafana"}}
+	backend.mappings["3"] = Mapping{ID: "3", Name: "tf-acc-mapping"}
+	backend.mappings["4"] = Mapping{ID: "4", Name: "alerts-mapping"}
+	backend.extractions["5"] = Extraction{ID: "5", Name: "tf-acc-error-pattern"}
+	backend.extractions["6"] = Extraction{ID: "6", Name: "error-pattern"}
+
+	for name, sweep := range map[string]func(context.Context, *Client) error{
+		"workflows":   sweepWorkflows,
+		"providers":   sweepProviders,
+		"mappings":    sweepMappings,
+		"extractions": sweepExtractions,
+	} {
+		if err := sweep(context.Background(), client); err != nil {
+			t.Fatalf("unexpected error sweeping %s: %v", name, err)
+		}
+	}
+
+	if len(backend.workflows) != 1 || len(backend.providers) != 1 || len(backend.mappings) != 1 || len(backend.extractions) != 1 {
+		t.Errorf("expected only the objects of acceptance tests to be deleted, got %+v, %+v, %+v, %+v",
+			backend.workflows, backend.providers, backend.mappings, backend.extractions)
+	}
+	if _, ok := backend.providers["2"]; !ok {
+		t.Error("expected objects without the sweep prefix to be kept")
+	}
+}
@@ -1,13 +1,48 @@
 package main
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6/tf6server"
 	"github.com/justtrackio/terraform-provider-keep/keep"
 )
 
+// version is set by goreleaser
+var version = "dev"
+
 //go:generate tfplugindocs
 func main() {
-	plugin.Serve(&plugin.ServeOpts{
-		ProviderFunc: keep.Provider,
-	})
+	var debug bool
+	flag.BoolVar(&debug, "debug", false, "set to true to run the provider with support for debuggers like delve")
+	flag.Parse()
+
+	server, err := keep.NewProviderServer(context.Background(), version)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var serveOpts []tf6server.ServeOpt
+	if debug {
+		serveOpts = append(serveOpts, tf6server.WithManagedDebug())
+	}
+
+	serveErr := tf6server.Serve("registry.terraform.io/justtrackio/keep", server, serveOpts...)
+	if serveErr != nil {
+		log.Print(serveErr)
+	}
+
+	// spans of the last API calls are still buffered when terraform stops the provider
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := keep.ShutdownTracing(ctx); err != nil {
+		log.Printf("[WARN] failed to export OpenTelemetry spans: %s", err)
+	}
+	cancel()
+
+	if serveErr != nil {
+		os.Exit(1)
+	}
 }
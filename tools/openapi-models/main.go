@@ -0,0 +1,305 @@
+// openapi-models generates Go models of the Keep API from the schemas of its OpenAPI document.
+// The provider keeps handwritten models for the objects it manages, the generated ones cover the
+// endpoints which aren't used by resources yet, e.g. incidents, deduplication rules and topology.
+//
+// Usage:
+//
+//	go run ./tools/openapi-models -spec openapi.json -out keep/models_openapi.go -schemas IncidentDto,TopologyServiceDtoOut
+//
+// Schemas referenced by the listed ones are generated as well.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// schema is the subset of an OpenAPI 3.1 schema object used by the Keep API
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Title                string             `json:"title"`
+	Description          string             `json:"description"`
+	Enum                 []interface{}      `json:"enum"`
+	Items                *schema            `json:"items"`
+	Properties           map[string]*schema `json:"properties"`
+	AdditionalProperties *schema            `json:"additionalProperties"`
+	Required             []string           `json:"required"`
+	AllOf                []*schema          `json:"allOf"`
+	AnyOf                []*schema          `json:"anyOf"`
+	OneOf                []*schema          `json:"oneOf"`
+}
+
+type document struct {
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+// initialisms are written in upper case in Go names
+var initialisms = map[string]bool{
+	"ai": true, "api": true, "cel": true, "http": true, "id": true, "ids": true, "ip": true,
+	"json": true, "uri": true, "url": true, "uuid": true, "yaml": true,
+}
+
+func main() {
+	specFile := flag.String("spec", "openapi.json", "OpenAPI document of the Keep API")
+	outFile := flag.String("out", "", "file to write the models to, stdout if empty")
+	pkg := flag.String("package", "keep", "package of the generated file")
+	schemas := flag.String("schemas", "", "comma separated names of the schemas to generate")
+	flag.Parse()
+
+	spec, err := os.ReadFile(*specFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	code, err := generate(spec, *pkg, strings.Split(*schemas, ","))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *outFile == "" {
+		os.Stdout.Write(code)
+		return
+	}
+	if err := os.WriteFile(*outFile, code, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate returns the formatted source of the models of the schemas and the schemas they reference
+func generate(spec []byte, pkg string, names []string) ([]byte, error) {
+	var doc document
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %v", err)
+	}
+
+	g := &generator{schemas: doc.Components.Schemas, pending: map[string]bool{}}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if g.schemas[name] == nil {
+			return nil, fmt.Errorf("schema %s is not defined", name)
+		}
+		g.pending[name] = true
+	}
+	if len(g.pending) == 0 {
+		return nil, fmt.Errorf("no schemas to generate")
+	}
+
+	// referenced schemas are added while generating, so the loop runs until nothing is pending
+	generated := map[string]string{}
+	for len(g.pending) > 0 {
+		for _, name := range sortedKeys(g.pending) {
+			delete(g.pending, name)
+			if _, ok := generated[name]; ok {
+				continue
+			}
+			code, err := g.model(name, g.schemas[name])
+			if err != nil {
+				return nil, fmt.Errorf("schema %s: %v", name, err)
+			}
+			generated[name] = code
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by tools/openapi-models -schemas %s; DO NOT EDIT.\n\n", strings.Join(sortedKeys(toSet(names)), ","))
+	fmt.Fprintf(&buf, "package %s\n", pkg)
+	for _, name := range sortedKeys(generated) {
+		buf.WriteString("\n")
+		buf.WriteString(generated[name])
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+type generator struct {
+	schemas map[string]*schema
+	pending map[string]bool
+}
+
+// model returns the declaration of the named schema
+func (g *generator) model(name string, s *schema) (string, error) {
+	var buf bytes.Buffer
+	writeComment(&buf, "", name, s.Description)
+
+	if len(s.Enum) > 0 && len(s.Properties) == 0 {
+		fmt.Fprintf(&buf, "type %s string\n\nconst (\n", name)
+		for _, value := range s.Enum {
+			v := fmt.Sprint(value)
+			fmt.Fprintf(&buf, "\t%s%s %s = %q\n", name, goName(v), name, v)
+		}
+		buf.WriteString(")\n")
+		return buf.String(), nil
+	}
+
+	if len(s.Properties) == 0 {
+		typ, err := g.goType(s)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&buf, "type %s %s\n", name, typ)
+		return buf.String(), nil
+	}
+
+	required := toSet(s.Required)
+	fmt.Fprintf(&buf, "type %s struct {\n", name)
+	for _, property := range sortedKeys(s.Properties) {
+		ps := s.Properties[property]
+		typ, err := g.goType(ps)
+		if err != nil {
+			return "", fmt.Errorf("property %s: %v", property, err)
+		}
+		writeComment(&buf, "\t", "", ps.Description)
+		tag := property
+		if !required[property] {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&buf, "\t%s %s `json:%q`\n", goName(property), typ, tag)
+	}
+	buf.WriteString("}\n")
+	return buf.String(), nil
+}
+
+// goType returns the Go type of a schema, named schemas it references are queued for generation
+func (g *generator) goType(s *schema) (string, error) {
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		if g.schemas[name] == nil {
+			return "", fmt.Errorf("reference %s is not defined", s.Ref)
+		}
+		g.pending[name] = true
+		return name, nil
+	}
+
+	if len(s.AllOf) == 1 {
+		return g.goType(s.AllOf[0])
+	}
+	if len(s.AllOf) > 0 || len(s.AnyOf) > 0 || len(s.OneOf) > 0 {
+		return "interface{}", nil
+	}
+
+	switch s.Type {
+	case "string":
+		return "string", nil
+	case "integer":
+		return "int", nil
+	case "number":
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		if s.Items == nil {
+			return "[]interface{}", nil
+		}
+		item, err := g.goType(s.Items)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + item, nil
+	case "object":
+		if s.AdditionalProperties == nil {
+			return "map[string]interface{}", nil
+		}
+		value, err := g.goType(s.AdditionalProperties)
+		if err != nil {
+			return "", err
+		}
+		return "map[string]" + value, nil
+	case "":
+		return "interface{}", nil
+	}
+	return "", fmt.Errorf("unsupported type %q", s.Type)
+}
+
+// goName converts snake case and camel case names of the API to exported Go names
+func goName(name string) string {
+	var words []string
+	var word []rune
+	for i, r := range name {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			words, word = appendWord(words, word), nil
+		case unicode.IsUpper(r) && i > 0 && len(word) > 0 && !unicode.IsUpper(word[len(word)-1]):
+			words, word = appendWord(words, word), []rune{r}
+		default:
+			word = append(word, r)
+		}
+	}
+	words = appendWord(words, word)
+
+	var out strings.Builder
+	for _, w := range words {
+		lower := strings.ToLower(w)
+		switch {
+		case lower == "ids":
+			out.WriteString("IDs")
+		case initialisms[lower]:
+			out.WriteString(strings.ToUpper(lower))
+		default:
+			out.WriteString(strings.ToUpper(lower[:1]) + lower[1:])
+		}
+	}
+
+	if out.Len() == 0 || unicode.IsDigit(rune(out.String()[0])) {
+		return "Value" + out.String()
+	}
+	return out.String()
+}
+
+func appendWord(words []string, word []rune) []string {
+	if len(word) == 0 {
+		return words
+	}
+	return append(words, string(word))
+}
+
+// writeComment writes the description as comment, models without description name their schema instead
+func writeComment(buf *bytes.Buffer, indent, name, description string) {
+	description = strings.TrimSpace(description)
+	if description == "An enumeration." {
+		description = ""
+	}
+	switch {
+	case name != "" && description == "":
+		description = fmt.Sprintf("%s is the %s schema of the Keep API", name, name)
+	case name != "":
+		description = name + ": " + description
+	case description == "":
+		return
+	}
+	for _, line := range strings.Split(description, "\n") {
+		fmt.Fprintf(buf, "%s// %s\n", indent, strings.TrimSpace(line))
+	}
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			set[value] = true
+		}
+	}
+	return set
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	spec := `{"components":{"schemas":{
+		"Rule": {
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": {"type": "string", "description": "Name of the rule"},
+				"provider_ids": {"type": "array", "items": {"type": "string"}},
+				"severity": {"$ref": "#/components/schemas/Severity"},
+				"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+				"lastReceived": {"type": "string"},
+				"value": {"anyOf": [{"type": "string"}, {"type": "integer"}]}
+			}
+		},
+		"Severity": {"enum": ["critical", "low"], "description": "An enumeration."},
+		"Unused": {"type": "object", "properties": {"id": {"type": "integer"}}}
+	}}}`
+
+	code, err := generate([]byte(spec), "keep", []string{"Rule"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"// Rule is the Rule schema of the Keep API\ntype Rule struct {",
+		"\t// Name of the rule\n\tName string `json:\"name\"`",
+		"ProviderIDs []string `json:\"provider_ids,omitempty\"`",
+		"Severity Severity `json:\"severity,omitempty\"`",
+		"Labels map[string]string `json:\"labels,omitempty\"`",
+		"LastReceived string `json:\"lastReceived,omitempty\"`",
+		"Value interface{} `json:\"value,omitempty\"`",
+		"type Severity string",
+		"SeverityCritical Severity = \"critical\"",
+	} {
+		if !strings.Contains(strings.Join(strings.Fields(string(code)), " "), strings.Join(strings.Fields(expected), " ")) {
+			t.Errorf("expected the code to contain %q, got:\n%s", expected, code)
+		}
+	}
+	if strings.Contains(string(code), "Unused") {
+		t.Errorf("expected only referenced schemas to be generated, got:\n%s", code)
+	}
+
+	if _, err := generate([]byte(spec), "keep", []string{"Missing"}); err == nil {
+		t.Error("expected an error for an undefined schema")
+	}
+}
+
+func TestGeneratedModelsUpToDate(t *testing.T) {
+	current, err := os.ReadFile("../../keep/models_openapi.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := strings.SplitN(string(current), "\n", 2)[0]
+	schemas := strings.TrimSuffix(strings.TrimPrefix(header, "// Code generated by tools/openapi-models -schemas "), "; DO NOT EDIT.")
+
+	spec, err := os.ReadFile("../../openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := generate(spec, "keep", strings.Split(schemas, ","))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(code) != string(current) {
+		t.Error("keep/models_openapi.go is outdated, run go generate ./keep/...")
+	}
+}